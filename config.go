@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// loadConfigFile applies settings from a JSON config file to the flags in fs.
+// Keys are flag names without the leading dash, e.g.
+//
+//	{"port": 9090, "storage": "sqlite", "cors-origins": ["https://example.com"]}
+//
+// Flags given on the command line take precedence over the file, and the file
+// takes precedence over flag defaults. Unknown keys and invalid values are
+// errors.
+func loadConfigFile(fs *flag.FlagSet, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	// Apply in a stable order so the first error reported is deterministic
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == "config" || fs.Lookup(name) == nil {
+			return fmt.Errorf("unknown setting %q", name)
+		}
+		if explicit[name] {
+			continue
+		}
+
+		value, err := configValue(settings[name])
+		if err != nil {
+			return fmt.Errorf("setting %q: %w", name, err)
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("setting %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// configValue converts a JSON value to its flag string form. Arrays become
+// comma-separated lists.
+func configValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("list items must be strings")
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", v)
+	}
+}
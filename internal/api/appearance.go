@@ -0,0 +1,38 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// List color and icon
+
+// maxListIconLength is the most characters a list icon may have; icons are
+// meant to be an emoji or a short label
+const maxListIconLength = 8
+
+// listColorPattern matches the accepted list colors: #rgb or #rrggbb
+var listColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validateListAppearance checks a list's color and icon, normalizing the
+// color to lower case. Both are optional.
+func validateListAppearance(list *models.TaskList) error {
+	list.Color = strings.ToLower(strings.TrimSpace(list.Color))
+	if list.Color != "" && !listColorPattern.MatchString(list.Color) {
+		return fmt.Errorf("invalid color %q: must be a hex color such as #10b981", list.Color)
+	}
+
+	list.Icon = strings.TrimSpace(list.Icon)
+	if utf8.RuneCountInString(list.Icon) > maxListIconLength {
+		return fmt.Errorf("icon must be at most %d characters", maxListIconLength)
+	}
+	if strings.IndexFunc(list.Icon, unicode.IsControl) >= 0 {
+		return fmt.Errorf("icon must not contain control characters")
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Task attachments
+
+// maxUploadSize is the largest attachment accepted, configurable with
+// SetMaxUploadSize
+var maxUploadSize int64 = 10 << 20
+
+// SetMaxUploadSize sets the largest attachment, in bytes, that may be
+// uploaded. Non-positive values keep the current setting.
+func SetMaxUploadSize(size int64) {
+	if size > 0 {
+		maxUploadSize = size
+	}
+}
+
+// validAttachmentName rejects empty names and names that could escape a
+// directory, such as "../x" or "a/b"
+func validAttachmentName(name string) bool {
+	return name != "" && name != "." && name != ".." &&
+		!strings.ContainsAny(name, `/\`) && !strings.ContainsRune(name, 0) &&
+		filepath.Base(name) == name
+}
+
+// HandleGetAttachments returns the attachment metadata of a task
+func HandleGetAttachments(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		task, err := store.GetTask(chi.URLParam(r, "listID"), chi.URLParam(r, "taskID"))
+		if err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
+
+		attachments := task.Attachments
+		if attachments == nil {
+			attachments = []models.Attachment{}
+		}
+		writeJSON(w, http.StatusOK, attachments)
+	}
+}
+
+// HandleUploadAttachment stores the file in the "file" field of a multipart
+// form as a new attachment on the task
+func HandleUploadAttachment(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		taskID := chi.URLParam(r, "taskID")
+
+		// Allow some room for the multipart framing around the file
+		r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize+1<<20)
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeErrorJSON(w, http.StatusRequestEntityTooLarge, "Attachment is too large")
+				return
+			}
+			writeErrorJSON(w, http.StatusBadRequest, "Expected a multipart form with a file field")
+			return
+		}
+		defer file.Close()
+
+		if header.Size > maxUploadSize {
+			writeErrorJSON(w, http.StatusRequestEntityTooLarge, "Attachment is too large")
+			return
+		}
+		if !validAttachmentName(header.Filename) {
+			writeErrorJSON(w, http.StatusBadRequest, "Invalid attachment filename")
+			return
+		}
+
+		contentType := header.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		attachment := models.Attachment{
+			ID:          uuid.New().String(),
+			Filename:    header.Filename,
+			ContentType: contentType,
+			UploadedAt:  time.Now(),
+		}
+		if err := store.AddAttachment(listID, taskID, &attachment, file); err != nil {
+			writeErrorJSON(w, http.StatusNotFound, "Failed to add attachment: "+err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, attachment)
+	}
+}
+
+// HandleDownloadAttachment serves an attachment's content under its original
+// filename
+func HandleDownloadAttachment(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		attachment, file, err := store.OpenAttachment(chi.URLParam(r, "listID"), chi.URLParam(r, "taskID"), chi.URLParam(r, "attachmentID"))
+		if err != nil {
+			writeErrorJSON(w, http.StatusNotFound, "Attachment not found")
+			return
+		}
+		defer file.Close()
+
+		w.Header().Set("Content-Type", attachment.ContentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeContent(w, r, attachment.Filename, attachment.UploadedAt, file)
+	}
+}
+
+// HandleDeleteAttachment removes an attachment from a task
+func HandleDeleteAttachment(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := store.DeleteAttachment(chi.URLParam(r, "listID"), chi.URLParam(r, "taskID"), chi.URLParam(r, "attachmentID"))
+		if err != nil {
+			if errors.Is(err, storage.ErrAttachmentNotFound) {
+				writeErrorJSON(w, http.StatusNotFound, "Attachment not found")
+				return
+			}
+			writeErrorJSON(w, http.StatusNotFound, "Failed to delete attachment: "+err.Error())
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// maxRestoreSize bounds the archive accepted by HandleRestore
+const maxRestoreSize = 1 << 30
+
+// clearDeadlines lifts the server's read and write timeouts for a request
+// whose body or response is too large to move within them, such as a backup
+// or restore. Writers that can't set deadlines are left as they are.
+func clearDeadlines(w http.ResponseWriter, read, write bool) {
+	rc := http.NewResponseController(w)
+	if read {
+		if err := rc.SetReadDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Printf("Failed to clear read deadline: %v", err)
+		}
+	}
+	if write {
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Printf("Failed to clear write deadline: %v", err)
+		}
+	}
+}
+
+// HandleBackup streams a zip archive of all stored data: the data directory
+// for the file store, or a database snapshot and attachments for SQLite
+func HandleBackup(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filename := "tasks-backup-" + time.Now().Format("20060102-150405") + ".zip"
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		clearDeadlines(w, false, true)
+
+		// The status is already sent once the archive starts streaming, so
+		// later failures can only be logged
+		if err := store.Backup(w); err != nil {
+			log.Printf("Backup failed: %v", err)
+		}
+	}
+}
+
+// HandleRestore replaces all stored data with a backup written by
+// HandleBackup, sent as the request body. The archive is checked before
+// anything is replaced, and malformed archives are rejected with 400.
+func HandleRestore(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Uploading and restoring a large archive can take longer than the
+		// server timeouts; the size is bounded by maxRestoreSize instead
+		clearDeadlines(w, true, true)
+		r.Body = http.MaxBytesReader(w, r.Body, maxRestoreSize)
+
+		// zip needs random access, so the upload is spooled to a temp file
+		tmp, err := os.CreateTemp("", "tasks-restore-*.zip")
+		if err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to store archive")
+			return
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+
+		size, err := io.Copy(tmp, r.Body)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeErrorJSON(w, http.StatusRequestEntityTooLarge, "Archive is too large")
+				return
+			}
+			writeErrorJSON(w, http.StatusBadRequest, "Failed to read archive")
+			return
+		}
+
+		archive, err := zip.NewReader(tmp, size)
+		if err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "Request body is not a zip archive")
+			return
+		}
+
+		if err := store.Restore(archive); err != nil {
+			if errors.Is(err, storage.ErrInvalidBackup) {
+				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to restore backup: "+err.Error())
+			return
+		}
+		InvalidateCaches()
+
+		lists, err := store.GetAllLists()
+		if err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve lists")
+			return
+		}
+		tasks, err := store.GetAllTasks()
+		if err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve tasks")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]int{
+			"lists": len(lists),
+			"tasks": len(tasks),
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// slowBackupStore takes longer than the test server's write timeout to
+// start writing a backup
+type slowBackupStore struct {
+	storage.TaskStore
+	delay time.Duration
+}
+
+func (s slowBackupStore) Backup(w io.Writer) error {
+	time.Sleep(s.delay)
+	return s.TaskStore.Backup(w)
+}
+
+func TestBackupOutlivesWriteTimeout(t *testing.T) {
+	store := slowBackupStore{TaskStore: newTestStore(t), delay: 300 * time.Millisecond}
+	server := httptest.NewUnstartedServer(NewRouter(store, embed.FS{}))
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/backup")
+	if err != nil {
+		t.Fatalf("GET /api/backup: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading backup: %v", err)
+	}
+	if _, err := zip.NewReader(bytes.NewReader(body), int64(len(body))); err != nil {
+		t.Fatalf("backup is not a complete zip archive (%d bytes): %v", len(body), err)
+	}
+}
@@ -0,0 +1,452 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Bulk Operations
+
+// bulkResult reports the outcome of a bulk operation for a single item
+type bulkResult struct {
+	ID      string           `json:"id"`
+	Success bool             `json:"success"`
+	Error   string           `json:"error,omitempty"`
+	Task    *models.Task     `json:"task,omitempty"`
+	List    *models.TaskList `json:"list,omitempty"`
+}
+
+// bulkRequest is the payload accepted by HandleBulkTasks
+type bulkRequest struct {
+	Operation     string   `json:"operation"`
+	IDs           []string `json:"ids"`
+	State         string   `json:"state,omitempty"`          // For set_state
+	BlockedReason string   `json:"blocked_reason,omitempty"` // For set_state to blocked
+	TargetListID  string   `json:"target_list_id,omitempty"` // For move
+	ResetState    bool     `json:"reset_state,omitempty"`    // For move, see storage.MoveOptions
+	Tag           string   `json:"tag,omitempty"`            // For add_tag
+}
+
+// HandleBulkTasks applies one operation (set_state, move, delete or add_tag)
+// to several tasks. Each task is handled independently, so one failure does
+// not abort the rest; the outcome for every ID is reported.
+func HandleBulkTasks(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req bulkRequest
+		if err := decodeBody(r, &req); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "Invalid request data")
+			return
+		}
+
+		if len(req.IDs) == 0 {
+			writeErrorJSON(w, http.StatusBadRequest, "At least one task ID is required")
+			return
+		}
+		if err := validateTaskIDs(req.IDs); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		req.Tag = strings.TrimSpace(req.Tag)
+		switch req.Operation {
+		case "set_state":
+			if req.State == "" {
+				writeErrorJSON(w, http.StatusBadRequest, "State is required for set_state")
+				return
+			}
+		case "move":
+			if req.TargetListID == "" {
+				writeErrorJSON(w, http.StatusBadRequest, "Target list ID is required for move")
+				return
+			}
+			if _, err := store.GetList(req.TargetListID); err != nil {
+				writeStoreError(w, err, "Target list not found")
+				return
+			}
+		case "delete":
+		case "add_tag":
+			if req.Tag == "" {
+				writeErrorJSON(w, http.StatusBadRequest, "Tag is required for add_tag")
+				return
+			}
+		default:
+			writeErrorJSON(w, http.StatusBadRequest, "Invalid operation: "+req.Operation)
+			return
+		}
+
+		results := make([]bulkResult, 0, len(req.IDs))
+		for _, id := range req.IDs {
+			task, err := store.FindTask(id)
+			if err != nil {
+				results = append(results, bulkResult{ID: id, Error: "Task not found"})
+				continue
+			}
+
+			task, err = applyBulkOperation(store, task, &req)
+			if err != nil {
+				results = append(results, bulkResult{ID: id, Error: err.Error()})
+				continue
+			}
+
+			results = append(results, bulkResult{ID: id, Success: true, Task: task})
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"operation": req.Operation,
+			"results":   results,
+		})
+	}
+}
+
+// validateTaskIDs rejects a request naming any task ID the stores couldn't
+// hold, such as one containing a path separator
+func validateTaskIDs(ids []string) error {
+	for _, id := range ids {
+		if err := storage.ValidateID(id); err != nil {
+			return fmt.Errorf("Invalid task ID: %q", id)
+		}
+	}
+	return nil
+}
+
+// applyBulkOperation applies a bulk operation to a single task, enforcing the
+// same rules as an individual update. It returns the resulting task, or nil
+// for deletions.
+func applyBulkOperation(store storage.TaskStore, task *models.Task, req *bulkRequest) (*models.Task, error) {
+	switch req.Operation {
+	case "set_state":
+		previous := task.State
+		task.SetState(models.TaskState(req.State))
+		if req.BlockedReason != "" {
+			task.BlockedReason = req.BlockedReason
+		}
+		if err := normalizeBlockedReason(task); err != nil {
+			return nil, err
+		}
+		if err := validateTaskState(store, task); err != nil {
+			return nil, err
+		}
+		if err := checkCanStart(store, task, previous); err != nil {
+			return nil, err
+		}
+		if task.Recurrence != "" && task.State == models.TaskStateDone && previous != models.TaskStateDone {
+			task.CompleteOccurrence(time.Now())
+		}
+
+	case "move":
+		moved, err := store.MoveTask(task.ListID, task.ID, req.TargetListID, storage.MoveOptions{ResetState: req.ResetState})
+		if err != nil {
+			return nil, fmt.Errorf("failed to move task: %w", err)
+		}
+		return moved, nil
+
+	case "delete":
+		if err := store.DeleteTask(task.ListID, task.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete task: %w", err)
+		}
+		return nil, nil
+
+	case "add_tag":
+		if hasTag(req.Tag)(task) {
+			return task, nil
+		}
+		task.Tags = append(task.Tags, req.Tag)
+	}
+
+	if err := store.UpdateTask(task); err != nil {
+		return nil, fmt.Errorf("failed to update task: %w", err)
+	}
+	return task, nil
+}
+
+// bulkDueRequest is the payload accepted by HandleBulkSetDue
+type bulkDueRequest struct {
+	IDs []string `json:"ids"`
+	Due string   `json:"due"`
+}
+
+// HandleBulkSetDue sets the due date of several tasks at once. The due value
+// may be an absolute date or a relative expression such as "+5d" or "friday",
+// or "clear" to remove the due date.
+func HandleBulkSetDue(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req bulkDueRequest
+		if err := decodeBody(r, &req); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "Invalid request data")
+			return
+		}
+
+		if len(req.IDs) == 0 {
+			writeErrorJSON(w, http.StatusBadRequest, "At least one task ID is required")
+			return
+		}
+		if err := validateTaskIDs(req.IDs); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var dueDate *time.Time
+		if !strings.EqualFold(strings.TrimSpace(req.Due), "clear") {
+			parsed, err := parseRelativeDate(req.Due, time.Now())
+			if err != nil {
+				writeErrorJSON(w, http.StatusBadRequest, "Invalid due date: "+err.Error())
+				return
+			}
+			dueDate = &parsed
+		}
+
+		results := make([]bulkResult, 0, len(req.IDs))
+		for _, id := range req.IDs {
+			task, err := store.FindTask(id)
+			if err != nil {
+				results = append(results, bulkResult{ID: id, Error: "Task not found"})
+				continue
+			}
+
+			task.DueDate = dueDate
+			if err := validateStartDate(task); err != nil {
+				results = append(results, bulkResult{ID: id, Error: err.Error()})
+				continue
+			}
+			if err := store.UpdateTask(task); err != nil {
+				results = append(results, bulkResult{ID: id, Error: "Failed to update task: " + err.Error()})
+				continue
+			}
+
+			results = append(results, bulkResult{ID: id, Success: true, Task: task})
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"due_date": dueDate,
+			"results":  results,
+		})
+	}
+}
+
+// bulkArchiveRequest is the payload accepted by HandleBulkArchiveLists
+type bulkArchiveRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// HandleBulkArchiveLists archives several lists at once, reporting the
+// outcome for each list
+func HandleBulkArchiveLists(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req bulkArchiveRequest
+		if err := decodeBody(r, &req); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "Invalid request data")
+			return
+		}
+
+		if len(req.IDs) == 0 {
+			writeErrorJSON(w, http.StatusBadRequest, "At least one list ID is required")
+			return
+		}
+
+		results := make([]bulkResult, 0, len(req.IDs))
+		for _, id := range req.IDs {
+			if _, err := store.GetList(id); err != nil {
+				results = append(results, bulkResult{ID: id, Error: "List not found"})
+				continue
+			}
+
+			list, err := store.ArchiveList(id)
+			if err != nil {
+				results = append(results, bulkResult{ID: id, Error: "Failed to archive list: " + err.Error()})
+				continue
+			}
+
+			results = append(results, bulkResult{ID: id, Success: true, List: list})
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"results": results,
+		})
+	}
+}
+
+// HandleArchiveDoneTasks archives every done task of a list. Archived tasks
+// stay in their list but are left out of task listings and the web UI unless
+// include_archived=true; reports and stats still count them. The tasks are
+// archived together and undone as one change. Setting archived back to false
+// with PATCH restores a task.
+func HandleArchiveDoneTasks(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		if _, err := store.GetList(listID); err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		archived, err := store.ArchiveDoneTasks(listID)
+		if err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"archived": len(archived),
+			"tasks":    archived,
+		})
+	}
+}
+
+// activeTasks returns the tasks that are not archived
+func activeTasks(tasks []models.Task) []models.Task {
+	active := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if !task.Archived {
+			active = append(active, task)
+		}
+	}
+	return active
+}
+
+// withoutArchived drops archived tasks unless the request asks for them with
+// include_archived=true
+func withoutArchived(r *http.Request, tasks []models.Task) []models.Task {
+	if r.URL.Query().Get("include_archived") == "true" {
+		return tasks
+	}
+	return activeTasks(tasks)
+}
+
+// moveByFilterRequest is the payload accepted by HandleMoveTasksByFilter
+type moveByFilterRequest struct {
+	Filter       models.TaskFilter `json:"filter"`
+	TargetListID string            `json:"target_list_id"`
+	DryRun       bool              `json:"dry_run"`
+	ResetState   bool              `json:"reset_state"` // See storage.MoveOptions
+}
+
+// HandleMoveTasksByFilter moves every task matching a filter into the target
+// list. With dry_run set the matching tasks are reported without moving them.
+// Tasks already in the target list are left alone, and tasks whose ID already
+// exists in the target list are reported as failures rather than overwritten.
+func HandleMoveTasksByFilter(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req moveByFilterRequest
+		if err := decodeBody(r, &req); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "Invalid request data")
+			return
+		}
+
+		if req.TargetListID == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Target list ID is required")
+			return
+		}
+
+		// An empty filter would match every task
+		if req.Filter == (models.TaskFilter{}) {
+			writeErrorJSON(w, http.StatusBadRequest, "At least one filter criterion is required")
+			return
+		}
+
+		predicate, err := filterPredicate(store, req.Filter)
+		if err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if _, err := store.GetList(req.TargetListID); err != nil {
+			writeStoreError(w, err, "Target list not found")
+			return
+		}
+
+		tasks, err := store.GetAllTasks()
+		if err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve tasks")
+			return
+		}
+
+		matched := filterTasks(tasks, predicate)
+		results := make([]bulkResult, 0, len(matched))
+		moved := 0
+		for i := range matched {
+			task := &matched[i]
+
+			if task.ListID == req.TargetListID {
+				results = append(results, bulkResult{ID: task.ID, Error: "Task is already in the target list"})
+				continue
+			}
+
+			if req.DryRun {
+				results = append(results, bulkResult{ID: task.ID, Success: true, Task: task})
+				continue
+			}
+
+			movedTask, err := store.MoveTask(task.ListID, task.ID, req.TargetListID, storage.MoveOptions{ResetState: req.ResetState})
+			if err != nil {
+				results = append(results, bulkResult{ID: task.ID, Error: "Failed to move task: " + err.Error()})
+				continue
+			}
+
+			moved++
+			results = append(results, bulkResult{ID: task.ID, Success: true, Task: movedTask})
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"target_list_id": req.TargetListID,
+			"dry_run":        req.DryRun,
+			"matched":        len(matched),
+			"moved":          moved,
+			"results":        results,
+		})
+	}
+}
+
+// batchCreateError reports why one task of a batch was not created
+type batchCreateError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// HandleBatchCreateTasks creates every task in a JSON array in one list.
+// Each task is validated like a single create; a task that fails is reported
+// by its index in the array without aborting the others.
+func HandleBatchCreateTasks(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+
+		var tasks []models.Task
+		if err := decodeStrict(r, &tasks); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		if len(tasks) == 0 {
+			writeErrorJSON(w, http.StatusBadRequest, "At least one task is required")
+			return
+		}
+
+		if _, err := store.GetList(listID); err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		created := make([]models.Task, 0, len(tasks))
+		errs := []batchCreateError{}
+		for i := range tasks {
+			task := &tasks[i]
+			if _, err := prepareNewTask(store, listID, task); err != nil {
+				errs = append(errs, batchCreateError{Index: i, Error: err.Error()})
+				continue
+			}
+			if err := store.CreateTask(task); err != nil {
+				errs = append(errs, batchCreateError{Index: i, Error: "Failed to create task: " + err.Error()})
+				continue
+			}
+			created = append(created, *task)
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"created": created,
+			"errors":  errs,
+		})
+	}
+}
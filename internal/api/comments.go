@@ -0,0 +1,107 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Task comments
+
+// HandleGetComments returns the comments on a task, oldest first
+func HandleGetComments(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		task, err := store.GetTask(chi.URLParam(r, "listID"), chi.URLParam(r, "taskID"))
+		if err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
+
+		comments := task.Comments
+		if comments == nil {
+			comments = []models.Comment{}
+		}
+		writeJSON(w, http.StatusOK, comments)
+	}
+}
+
+// HandleCreateComment adds a comment to a task. Without an author in the
+// body, the basic auth user making the request is used.
+func HandleCreateComment(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		taskID := chi.URLParam(r, "taskID")
+
+		var comment models.Comment
+		if err := decodeBody(r, &comment); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "Invalid comment data")
+			return
+		}
+
+		comment.Author = strings.TrimSpace(comment.Author)
+		if comment.Author == "" {
+			comment.Author, _, _ = r.BasicAuth()
+		}
+		if comment.Author == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Comment author is required")
+			return
+		}
+		if strings.TrimSpace(comment.Body) == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Comment body is required")
+			return
+		}
+
+		task, err := store.GetTask(listID, taskID)
+		if err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
+
+		// IDs and timestamps are always assigned by the server
+		comment.ID = uuid.New().String()
+		comment.CreatedAt = time.Now()
+
+		task.Comments = append(task.Comments, comment)
+		if err := store.UpdateTask(task); err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to save comment")
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, comment)
+	}
+}
+
+// HandleDeleteComment removes a comment from a task
+func HandleDeleteComment(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		commentID := chi.URLParam(r, "commentID")
+
+		task, err := store.GetTask(chi.URLParam(r, "listID"), chi.URLParam(r, "taskID"))
+		if err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
+
+		for i := range task.Comments {
+			if task.Comments[i].ID != commentID {
+				continue
+			}
+
+			task.Comments = append(task.Comments[:i], task.Comments[i+1:]...)
+			if err := store.UpdateTask(task); err != nil {
+				writeErrorJSON(w, http.StatusInternalServerError, "Failed to delete comment")
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		writeErrorJSON(w, http.StatusNotFound, "Comment not found")
+	}
+}
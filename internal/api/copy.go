@@ -0,0 +1,176 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// List and task duplication
+
+// duplicateListRequest is the optional payload accepted by HandleDuplicateList
+type duplicateListRequest struct {
+	Name string `json:"name"`
+}
+
+// copyTask returns a fresh copy of a task for the list listID: new IDs for
+// the task, its subtasks and notes, and none of the original's activity
+// (history, comments, attachments, completions). ids maps each original task
+// ID to its copy's ID and gains an entry for every subtask copied.
+func copyTask(task models.Task, listID string, ids map[string]string, now time.Time) models.Task {
+	task.ID = ids[task.ID]
+	task.ListID = listID
+	task.StateTime = time.Time{}
+	task.Version = 0
+	task.History = nil
+	task.StateSeconds = nil
+	task.CompletionHistory = nil
+	task.CompletedAt = nil
+	task.Archived = false
+	task.ArchivedAt = nil
+	task.ReminderSent = nil
+	task.Comments = nil
+	task.Attachments = nil
+	task.DeletedAt = nil
+	if parentID, ok := ids[task.ParentID]; ok {
+		task.ParentID = parentID
+	}
+
+	if task.DependsOn != nil {
+		deps := make([]string, len(task.DependsOn))
+		for i, id := range task.DependsOn {
+			if copied, ok := ids[id]; ok {
+				id = copied
+			}
+			deps[i] = id
+		}
+		task.DependsOn = deps
+	}
+
+	if task.Notes != nil {
+		notes := make([]models.Note, len(task.Notes))
+		for i, note := range task.Notes {
+			note.ID = uuid.New().String()
+			note.CreatedAt = now
+			note.UpdatedAt = now
+			notes[i] = note
+		}
+		task.Notes = notes
+	}
+
+	if task.SubTasks != nil {
+		subTasks := make([]models.Task, len(task.SubTasks))
+		for i, subTask := range task.SubTasks {
+			ids[subTask.ID] = uuid.New().String()
+			subTask = copyTask(subTask, listID, ids, now)
+			subTask.CreatedAt = now
+			subTask.UpdatedAt = now
+			subTask.StateTime = now
+			subTasks[i] = subTask
+		}
+		task.SubTasks = subTasks
+	}
+
+	return task
+}
+
+// HandleDuplicateList copies a list and all of its tasks into a new list.
+// The copy is named "<name> (copy)" unless a name is given in the body.
+func HandleDuplicateList(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req duplicateListRequest
+		if err := decodeBody(r, &req); err != nil && !errors.Is(err, io.EOF) {
+			writeErrorJSON(w, http.StatusBadRequest, "Invalid request data")
+			return
+		}
+
+		source, err := store.GetList(chi.URLParam(r, "listID"))
+		if err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		tasks, err := store.GetTasksForList(source.ID)
+		if err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		list := *source
+		list.ID = uuid.New().String()
+		list.Name = strings.TrimSpace(req.Name)
+		if list.Name == "" {
+			list.Name = source.Name + " (copy)"
+		}
+		list.Order = 0
+		list.Archived = false
+		list.ArchivedAt = nil
+
+		// Assign every top-level ID first so dependencies between tasks
+		// point at the copies
+		ids := make(map[string]string, len(tasks))
+		for _, task := range tasks {
+			ids[task.ID] = uuid.New().String()
+		}
+		now := time.Now()
+		copies := make([]models.Task, len(tasks))
+		for i, task := range tasks {
+			copies[i] = copyTask(task, list.ID, ids, now)
+		}
+
+		if err := store.CreateListWithTasks(&list, copies); err != nil {
+			writeCreateError(w, err, "Failed to duplicate list: "+err.Error())
+			return
+		}
+
+		w.Header().Set("Location", listLocation(list.ID))
+		writeJSON(w, http.StatusCreated, list)
+	}
+}
+
+// HandleDuplicateTask copies a task, with its notes and subtasks, into the
+// same list. The copy's title gets a "(copy)" suffix and it starts over in
+// the list's first state.
+func HandleDuplicateTask(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		task, err := store.GetTask(chi.URLParam(r, "listID"), chi.URLParam(r, "taskID"))
+		if err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
+
+		ids := map[string]string{task.ID: uuid.New().String()}
+		duplicate := copyTask(*task, task.ListID, ids, time.Now())
+		duplicate.Title = task.Title + " (copy)"
+		duplicate.State = defaultTaskState(store, task.ListID)
+		if err := normalizeBlockedReason(&duplicate); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := store.CreateTask(&duplicate); err != nil {
+			writeCreateError(w, err, "Failed to duplicate task")
+			return
+		}
+
+		if r.Header.Get("HX-Request") == "true" {
+			tasks, err := store.GetTasksForList(duplicate.ListID)
+			if err != nil {
+				http.Error(w, "Failed to retrieve tasks", http.StatusInternalServerError)
+				return
+			}
+			renderTasksContainer(w, tasks)
+			return
+		}
+
+		w.Header().Set("Location", taskLocation(duplicate.ListID, duplicate.ID))
+		writeJSON(w, http.StatusCreated, duplicate)
+	}
+}
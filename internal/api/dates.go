@@ -0,0 +1,90 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout is the format used for due dates in forms and exports
+const dateLayout = "2006-01-02"
+
+var relativeOffsetPattern = regexp.MustCompile(`^([+-]?)(\d+)\s*(d|days?|w|weeks?|m|months?|y|years?)$`)
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// parseRelativeDate converts an absolute (2006-01-02) or relative date
+// expression into a concrete date. Supported relative forms are "today",
+// "tomorrow", "yesterday", offsets such as "+5d", "-1w" or "3 months",
+// weekday names ("friday", "next friday") and "next week/month/year".
+// Dates are returned as midnight UTC, matching how form dates are parsed.
+func parseRelativeDate(value string, now time.Time) (time.Time, error) {
+	expr := strings.ToLower(strings.TrimSpace(value))
+	if expr == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+
+	if date, err := time.Parse(dateLayout, expr); err == nil {
+		return date, nil
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch expr {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "next week":
+		return today.AddDate(0, 0, 7), nil
+	case "next month":
+		return today.AddDate(0, 1, 0), nil
+	case "next year":
+		return today.AddDate(1, 0, 0), nil
+	}
+
+	if m := relativeOffsetPattern.FindStringSubmatch(expr); m != nil {
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid offset: %s", value)
+		}
+		if m[1] == "-" {
+			n = -n
+		}
+		switch m[3][0] {
+		case 'd':
+			return today.AddDate(0, 0, n), nil
+		case 'w':
+			return today.AddDate(0, 0, 7*n), nil
+		case 'm':
+			return today.AddDate(0, n, 0), nil
+		case 'y':
+			return today.AddDate(n, 0, 0), nil
+		}
+	}
+
+	// Weekday names resolve to the next occurrence, today included.
+	// "next <weekday>" always skips today.
+	name := strings.TrimPrefix(expr, "next ")
+	if day, ok := weekdays[name]; ok {
+		offset := (int(day) - int(today.Weekday()) + 7) % 7
+		if offset == 0 && name != expr {
+			offset = 7
+		}
+		return today.AddDate(0, 0, offset), nil
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date: %s", value)
+}
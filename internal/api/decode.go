@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Strict request decoding
+
+// decodeError describes why a request body was rejected, naming the
+// offending field and the type it expects when they are known. It is written
+// to the client as the body of a 400 response.
+type decodeError struct {
+	Message  string `json:"error"`
+	Field    string `json:"field,omitempty"`
+	Expected string `json:"expected,omitempty"`
+}
+
+func (e *decodeError) Error() string {
+	return e.Message
+}
+
+// decodeStrict decodes a JSON body like decodeBody, but rejects unknown
+// fields and trailing data. Failures are returned as a *decodeError.
+func decodeStrict(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return describeDecodeError(err)
+	}
+	if dec.More() {
+		return &decodeError{Message: "Request body must contain a single JSON object"}
+	}
+	return nil
+}
+
+// describeDecodeError turns an encoding/json error into a decodeError
+func describeDecodeError(err error) *decodeError {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var timeErr *time.ParseError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return &decodeError{Message: "Request body is empty"}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &decodeError{Message: "Malformed JSON: unexpected end of body"}
+	case errors.As(err, &syntaxErr):
+		return &decodeError{Message: fmt.Sprintf("Malformed JSON at byte %d: %v", syntaxErr.Offset, syntaxErr)}
+	case errors.As(err, &typeErr):
+		expected := jsonTypeName(typeErr.Type)
+		if typeErr.Field == "" {
+			return &decodeError{Message: fmt.Sprintf("Request body must be %s, got %s", expected, typeErr.Value), Expected: expected}
+		}
+		return &decodeError{
+			Message:  fmt.Sprintf("Field %q must be %s, got %s", typeErr.Field, expected, typeErr.Value),
+			Field:    typeErr.Field,
+			Expected: expected,
+		}
+	case errors.As(err, &timeErr):
+		return &decodeError{
+			Message:  fmt.Sprintf("Invalid time %q: expected an RFC 3339 timestamp such as 2006-01-02T15:04:05Z", timeErr.Value),
+			Expected: "date-time",
+		}
+	}
+
+	// encoding/json has no error type for unknown fields
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		field = strings.Trim(field, `"`)
+		return &decodeError{Message: fmt.Sprintf("Unknown field %q", field), Field: field}
+	}
+	return &decodeError{Message: "Invalid JSON: " + err.Error()}
+}
+
+// jsonTypeName names the JSON type that decodes into t
+func jsonTypeName(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Time{}) {
+		return "date-time"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Ptr:
+		return jsonTypeName(t.Elem())
+	}
+	return "object"
+}
+
+// writeDecodeError writes a 400 response for a request that could not be
+// decoded, including the field details of a decodeError
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var decodeErr *decodeError
+	if errors.As(err, &decodeErr) {
+		writeJSON(w, http.StatusBadRequest, decodeErr)
+		return
+	}
+	writeErrorJSON(w, http.StatusBadRequest, err.Error())
+}
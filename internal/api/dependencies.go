@@ -0,0 +1,140 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Task dependencies
+
+// normalizeDependsOn trims dependency IDs, dropping empty and repeated ones
+func normalizeDependsOn(task *models.Task) {
+	seen := make(map[string]bool, len(task.DependsOn))
+	deps := task.DependsOn[:0]
+	for _, id := range task.DependsOn {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			seen[id] = true
+			deps = append(deps, id)
+		}
+	}
+	if len(deps) == 0 {
+		deps = nil
+	}
+	task.DependsOn = deps
+}
+
+// validateDependencies checks that every dependency exists and that adding
+// them would not create a cycle. It returns the HTTP status to report along
+// with the error.
+func validateDependencies(store storage.TaskStore, task *models.Task) (int, error) {
+	if len(task.DependsOn) == 0 {
+		return http.StatusOK, nil
+	}
+
+	tasks, err := store.GetAllTasks()
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to retrieve tasks")
+	}
+
+	graph := make(map[string][]string, len(tasks)+1)
+	for _, t := range tasks {
+		graph[t.ID] = t.DependsOn
+	}
+	graph[task.ID] = task.DependsOn
+	return checkDependencies(graph, task)
+}
+
+// checkDependencies checks a task's dependencies against graph, which maps
+// the ID of every task, including this one, to its dependencies
+func checkDependencies(graph map[string][]string, task *models.Task) (int, error) {
+	for _, id := range task.DependsOn {
+		if id == task.ID {
+			return http.StatusBadRequest, fmt.Errorf("a task cannot depend on itself")
+		}
+		if err := storage.ValidateID(id); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("invalid dependency ID: %q", id)
+		}
+		if _, ok := graph[id]; !ok {
+			return http.StatusBadRequest, fmt.Errorf("dependency not found: %s", id)
+		}
+	}
+
+	// Walk the dependencies depth-first; reaching the task again is a cycle
+	visited := make(map[string]bool)
+	var reaches func(id string) bool
+	reaches = func(id string) bool {
+		if id == task.ID {
+			return true
+		}
+		if visited[id] {
+			return false
+		}
+		visited[id] = true
+		for _, dep := range graph[id] {
+			if reaches(dep) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, id := range task.DependsOn {
+		if reaches(id) {
+			return http.StatusConflict, fmt.Errorf("dependency cycle through task: %s", id)
+		}
+	}
+
+	return http.StatusOK, nil
+}
+
+// unfinishedDependencies returns the dependencies of a task that are not done.
+// Dependencies that no longer exist do not block.
+func unfinishedDependencies(store storage.TaskStore, task *models.Task) []models.Task {
+	blockers := []models.Task{}
+	for _, id := range task.DependsOn {
+		dep, err := store.FindTask(id)
+		if err != nil {
+			continue
+		}
+		if dep.State != models.TaskStateDone {
+			blockers = append(blockers, *dep)
+		}
+	}
+	return blockers
+}
+
+// checkCanStart rejects moving a task into progress while any of its
+// dependencies are unfinished
+func checkCanStart(store storage.TaskStore, task *models.Task, previous models.TaskState) error {
+	if task.State != models.TaskStateInProgress || previous == models.TaskStateInProgress {
+		return nil
+	}
+	if blockers := unfinishedDependencies(store, task); len(blockers) > 0 {
+		return fmt.Errorf("task cannot start until its dependencies are done: %s", blockers[0].Title)
+	}
+	return nil
+}
+
+// HandleGetTaskBlockers returns the unfinished dependencies of a task
+func HandleGetTaskBlockers(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		taskID := chi.URLParam(r, "taskID")
+		if listID == "" || taskID == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Missing list ID or task ID")
+			return
+		}
+
+		task, err := store.GetTask(listID, taskID)
+		if err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, unfinishedDependencies(store, task))
+	}
+}
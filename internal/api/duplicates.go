@@ -0,0 +1,140 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// maxDuplicateDistance caps the edit distance accepted by the duplicates
+// endpoint; larger values would group unrelated tasks
+const maxDuplicateDistance = 10
+
+// duplicateGroup is a cluster of tasks with identical or similar titles
+type duplicateGroup struct {
+	Title string        `json:"title"`
+	Tasks []models.Task `json:"tasks"`
+}
+
+// normalizeTitle lowercases a title and collapses whitespace for comparison
+func normalizeTitle(title string) string {
+	return strings.Join(strings.Fields(strings.ToLower(title)), " ")
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// findDuplicates clusters tasks whose normalized titles are within distance
+// edits of each other. Similarity is transitive, so chains of near matches
+// end up in the same group. Only groups with more than one task are returned.
+func findDuplicates(tasks []models.Task, distance int) []duplicateGroup {
+	titles := make([]string, len(tasks))
+	parent := make([]int, len(tasks))
+	for i := range tasks {
+		titles[i] = normalizeTitle(tasks[i].Title)
+		parent[i] = i
+	}
+
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range tasks {
+		for j := i + 1; j < len(tasks); j++ {
+			if find(i) == find(j) {
+				continue
+			}
+			if titles[i] == titles[j] || (distance > 0 && levenshtein(titles[i], titles[j]) <= distance) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	clusters := make(map[int][]models.Task)
+	for i := range tasks {
+		root := find(i)
+		clusters[root] = append(clusters[root], tasks[i])
+	}
+
+	groups := make([]duplicateGroup, 0)
+	for root, members := range clusters {
+		if len(members) < 2 {
+			continue
+		}
+		sort.Slice(members, func(i, j int) bool {
+			return members[i].CreatedAt.Before(members[j].CreatedAt)
+		})
+		groups = append(groups, duplicateGroup{Title: titles[root], Tasks: members})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Title < groups[j].Title
+	})
+
+	return groups
+}
+
+// HandleGetDuplicateTasks returns clusters of tasks in a list with identical
+// titles (ignoring case and whitespace), or titles within ?distance= edits
+func HandleGetDuplicateTasks(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		if listID == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Missing list ID")
+			return
+		}
+
+		distance := 0
+		if value := r.URL.Query().Get("distance"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 || parsed > maxDuplicateDistance {
+				writeErrorJSON(w, http.StatusBadRequest, "distance must be an integer between 0 and "+strconv.Itoa(maxDuplicateDistance))
+				return
+			}
+			distance = parsed
+		}
+
+		if _, err := store.GetList(listID); err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		tasks, err := store.GetTasksForList(listID)
+		if err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, findDuplicates(tasks, distance))
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Conditional requests
+
+// etagFor returns a strong ETag derived from the serialized response body
+func etagFor(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header matches the ETag.
+// Weak comparison is used, as RFC 9110 requires for If-None-Match.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// etagMatchesStrong reports whether an If-Match header matches the ETag.
+// Strong comparison is used, as RFC 9110 requires for If-Match, so weak
+// validators never match.
+func etagMatchesStrong(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || (!strings.HasPrefix(candidate, "W/") && candidate == etag) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonETag serializes v as writeJSON would and returns the body with its ETag
+func jsonETag(v interface{}) ([]byte, string, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, "", err
+	}
+	body = append(body, '\n')
+	return body, etagFor(body), nil
+}
+
+// writeJSONWithETag writes a 200 JSON response tagged with an ETag of its
+// body, or a bodiless 304 when the request's If-None-Match already matches
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, v interface{}) {
+	body, etag, err := jsonETag(v)
+	if err != nil {
+		writeErrorJSON(w, http.StatusInternalServerError, "Failed to encode response")
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	if match := r.Header.Get("If-None-Match"); match != "" && etagMatches(match, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestETagComparison(t *testing.T) {
+	const etag = `"abc"`
+	tests := []struct {
+		header string
+		weak   bool
+		strong bool
+	}{
+		{`"abc"`, true, true},
+		{`W/"abc"`, true, false},
+		{`"xyz"`, false, false},
+		{`"xyz", "abc"`, true, true},
+		{`"xyz", W/"abc"`, true, false},
+		{`*`, true, true},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, etag); got != tt.weak {
+			t.Errorf("etagMatches(%s) = %v, want %v", tt.header, got, tt.weak)
+		}
+		if got := etagMatchesStrong(tt.header, etag); got != tt.strong {
+			t.Errorf("etagMatchesStrong(%s) = %v, want %v", tt.header, got, tt.strong)
+		}
+	}
+}
+
+func TestIfMatchRejectsWeakETags(t *testing.T) {
+	router := newTestRouter(t)
+	if rec := doJSON(t, router, http.MethodPost, "/api/lists", `{"id":"list","name":"List"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("creating list: status %d: %s", rec.Code, rec.Body)
+	}
+	if rec := doJSON(t, router, http.MethodPost, "/api/lists/list/tasks", `{"id":"task","title":"Task"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("creating task: status %d: %s", rec.Code, rec.Body)
+	}
+	etag := doJSON(t, router, http.MethodGet, "/api/tasks/list/task", "").Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("GET returned no ETag")
+	}
+
+	// If-None-Match compares weakly, so a weak copy of the tag still matches
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks/list/task", nil)
+	req.Header.Set("If-None-Match", "W/"+etag)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("GET with weak If-None-Match: status %d, want %d", rec.Code, http.StatusNotModified)
+	}
+
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		for _, tt := range []struct {
+			ifMatch string
+			want    int
+		}{
+			{"W/" + etag, http.StatusPreconditionFailed},
+			{etag, http.StatusOK},
+		} {
+			current := doJSON(t, router, http.MethodGet, "/api/tasks/list/task", "").Header().Get("ETag")
+			ifMatch := strings.Replace(tt.ifMatch, etag, current, 1)
+			body, _ := json.Marshal(map[string]string{"title": "Renamed by " + method})
+			req := httptest.NewRequest(method, "/api/tasks/list/task", strings.NewReader(string(body)))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", ifMatch)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s with If-Match %s: status %d, want %d: %s", method, ifMatch, rec.Code, tt.want, rec.Body)
+			}
+		}
+	}
+}
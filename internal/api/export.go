@@ -0,0 +1,262 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Export formats and content negotiation
+
+// exportFormat is one format GET /api/export can produce
+type exportFormat struct {
+	Name        string   // Value of ?format=
+	Aliases     []string // Other accepted ?format= values
+	ContentType string   // Media type matched against Accept and sent back
+	Filename    string
+	Write       func(buf *bytes.Buffer, lists []models.TaskList, store storage.TaskStore) error
+}
+
+// exportFormats are the export formats, the first being the default for
+// requests without a preference
+var exportFormats = []exportFormat{
+	{Name: "markdown", Aliases: []string{"md"}, ContentType: "text/markdown", Filename: "tasks.md", Write: writeMarkdownExport},
+	{Name: "csv", ContentType: "text/csv", Filename: "tasks.csv", Write: writeCSVExport},
+	{Name: "ics", Aliases: []string{"ical"}, ContentType: "text/calendar", Filename: "tasks.ics", Write: writeICSExport},
+	{Name: "json", ContentType: "application/json", Filename: "tasks.json", Write: writeJSONExport},
+}
+
+// writeJSONExport writes all lists and their tasks as the fullExport
+// document, the same one HandleExportFullJSON streams
+func writeJSONExport(buf *bytes.Buffer, lists []models.TaskList, store storage.TaskStore) error {
+	export := fullExport{ExportedAt: time.Now(), Lists: make([]fullExportList, 0, len(lists))}
+	for _, list := range lists {
+		tasks, err := store.GetTasksForList(list.ID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve tasks for list %s: %w", list.ID, err)
+		}
+		if tasks == nil {
+			tasks = []models.Task{}
+		}
+		export.Lists = append(export.Lists, fullExportList{TaskList: list, Tasks: tasks})
+	}
+
+	encoder := json.NewEncoder(buf)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
+
+// fullExport is the document written by HandleExportFullJSON, which streams
+// it a list at a time, and read back by HandleImportJSON
+type fullExport struct {
+	ExportedAt time.Time        `json:"exported_at"`
+	Lists      []fullExportList `json:"lists"`
+}
+
+// fullExportList is one list of the full JSON export, with its tasks nested
+type fullExportList struct {
+	models.TaskList
+	Tasks []models.Task `json:"tasks"`
+}
+
+// HandleExportFullJSON streams every list, with its tasks and their notes and
+// subtasks nested inside, as one JSON document. Tasks are written as stored,
+// without computed fields, so the document can be read back by
+// HandleImportJSON. Lists are encoded one at a time rather than buffered, so
+// the write timeout is lifted, and a failure partway ends the response early
+// with invalid JSON instead of an error status. A list deleted while the
+// export runs is left out.
+func HandleExportFullJSON(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lists, err := store.GetAllLists()
+		if err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename=tasks-export.json")
+		clearDeadlines(w, false, true)
+		w.WriteHeader(http.StatusOK)
+
+		exportedAt, _ := json.Marshal(time.Now())
+		fmt.Fprintf(w, "{\"exported_at\":%s,\"lists\":[\n", exportedAt)
+		encoder := json.NewEncoder(w)
+		written := 0
+		for _, list := range lists {
+			tasks, err := store.GetTasksForList(list.ID)
+			if errors.Is(err, storage.ErrListNotFound) {
+				continue
+			}
+			if err != nil {
+				log.Printf("JSON export: failed to retrieve tasks for list %s: %v", list.ID, err)
+				return
+			}
+			if tasks == nil {
+				tasks = []models.Task{}
+			}
+			if written > 0 {
+				io.WriteString(w, ",")
+			}
+			written++
+			if err := encoder.Encode(fullExportList{TaskList: list, Tasks: tasks}); err != nil {
+				log.Printf("JSON export: failed to write list %s: %v", list.ID, err)
+				return
+			}
+		}
+		io.WriteString(w, "]}\n")
+	}
+}
+
+// exportFormatByName returns the format named by a ?format= value
+func exportFormatByName(name string) (*exportFormat, bool) {
+	name = strings.ToLower(name)
+	for i := range exportFormats {
+		format := &exportFormats[i]
+		if format.Name == name {
+			return format, true
+		}
+		for _, alias := range format.Aliases {
+			if alias == name {
+				return format, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// acceptedRange is one media range of an Accept header
+type acceptedRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept returns the media ranges of an Accept header, most preferred
+// first. Ranges with equal quality keep their order, and ranges that can't
+// be parsed are ignored.
+func parseAccept(header string) []acceptedRange {
+	var ranges []acceptedRange
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptedRange{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+	return ranges
+}
+
+// negotiateExportFormat picks the export format for a request: the one named
+// by ?format=, else the most preferred format in the Accept header, else the
+// default. It returns false when ?format= is unknown or Accept rules out
+// every format.
+func negotiateExportFormat(r *http.Request) (*exportFormat, bool) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		return exportFormatByName(name)
+	}
+
+	header := r.Header.Get("Accept")
+	if header == "" {
+		return &exportFormats[0], true
+	}
+	for _, accepted := range parseAccept(header) {
+		if accepted.quality <= 0 {
+			continue
+		}
+		for i := range exportFormats {
+			if mediaTypeMatches(accepted.mediaType, exportFormats[i].ContentType) {
+				return &exportFormats[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// mediaTypeMatches reports whether a media range such as text/* covers
+// mediaType
+func mediaTypeMatches(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(mediaRange, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+// writeExport writes all lists and tasks in format as a download
+func writeExport(w http.ResponseWriter, store storage.TaskStore, format *exportFormat) {
+	lists, err := store.GetAllLists()
+	if err != nil {
+		writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve lists")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := format.Write(&buf, lists, store); err != nil {
+		log.Printf("Export: %v", err)
+		writeErrorJSON(w, http.StatusInternalServerError, "Failed to write "+format.Name+" export")
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename="+format.Filename)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// HandleExport exports all lists and tasks in the format chosen by ?format=
+// or the Accept header, defaulting to markdown
+func HandleExport(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+
+		format, ok := negotiateExportFormat(r)
+		if !ok {
+			names := make([]string, len(exportFormats))
+			for i, format := range exportFormats {
+				names[i] = format.Name
+			}
+			status := http.StatusNotAcceptable
+			if r.URL.Query().Has("format") {
+				status = http.StatusBadRequest
+			}
+			writeErrorJSON(w, status, "Unsupported export format; supported formats are "+strings.Join(names, ", "))
+			return
+		}
+
+		writeExport(w, store, format)
+	}
+}
+
+// HandleExportAs exports all lists and tasks in the named format, regardless
+// of the Accept header. It serves the older per-format export routes.
+func HandleExportAs(store storage.TaskStore, name string) http.HandlerFunc {
+	format, ok := exportFormatByName(name)
+	if !ok {
+		log.Fatalf("Unknown export format %q", name)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeExport(w, store, format)
+	}
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// seedExportStore fills a store with a list whose tasks have notes,
+// comments, subtasks and a dependency
+func seedExportStore(t *testing.T, store storage.TaskStore) {
+	t.Helper()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.CreateList(&models.TaskList{ID: "work", Name: "Work", States: []string{"open", "review", "closed"}}); err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	tasks := []models.Task{
+		{ID: "design", ListID: "work", Title: "Design", State: "closed", Priority: models.TaskPriorityHigh},
+		{
+			ID: "build", ListID: "work", Title: "Build", State: "open", DependsOn: []string{"design"},
+			Notes:    []models.Note{{ID: "n1", Content: "Use the new API", CreatedAt: now, UpdatedAt: now}},
+			Comments: []models.Comment{{ID: "c1", Author: "sam", Body: "Looks good", CreatedAt: now}},
+			SubTasks: []models.Task{{ID: "build-ui", ListID: "work", Title: "Build the UI", State: "open"}},
+		},
+	}
+	for i := range tasks {
+		if err := store.CreateTask(&tasks[i]); err != nil {
+			t.Fatalf("CreateTask(%s): %v", tasks[i].ID, err)
+		}
+	}
+}
+
+// exportFullJSON returns the body of GET /api/export/json
+func exportFullJSON(t *testing.T, router http.Handler) []byte {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/export/json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/export/json: status %d: %s", rec.Code, rec.Body)
+	}
+	return rec.Body.Bytes()
+}
+
+func TestJSONExportImportRoundTrip(t *testing.T) {
+	source := newTestStore(t)
+	seedExportStore(t, source)
+	exported := exportFullJSON(t, NewRouter(source, embed.FS{}))
+
+	target := newTestStore(t)
+	router := NewRouter(target, embed.FS{})
+	if rec := doJSON(t, router, http.MethodPost, "/api/import/json", string(exported)); rec.Code != http.StatusCreated {
+		t.Fatalf("POST /api/import/json: status %d: %s", rec.Code, rec.Body)
+	}
+
+	list, err := target.GetList("work")
+	if err != nil {
+		t.Fatalf("GetList: %v", err)
+	}
+	if strings.Join(list.States, ",") != "open,review,closed" {
+		t.Errorf("imported states %v", list.States)
+	}
+	build, err := target.GetTask("work", "build")
+	if err != nil {
+		t.Fatalf("GetTask(build): %v", err)
+	}
+	if len(build.Notes) != 1 || build.Notes[0].Content != "Use the new API" {
+		t.Errorf("imported notes %+v", build.Notes)
+	}
+	if len(build.Comments) != 1 || build.Comments[0].Body != "Looks good" || build.Comments[0].Author != "sam" {
+		t.Errorf("imported comments %+v", build.Comments)
+	}
+	if len(build.SubTasks) != 1 || build.SubTasks[0].ID != "build-ui" {
+		t.Errorf("imported subtasks %+v", build.SubTasks)
+	}
+	if len(build.DependsOn) != 1 || build.DependsOn[0] != "design" {
+		t.Errorf("imported dependencies %v", build.DependsOn)
+	}
+	design, err := target.GetTask("work", "design")
+	if err != nil {
+		t.Fatalf("GetTask(design): %v", err)
+	}
+	if design.State != "closed" || design.Priority != models.TaskPriorityHigh {
+		t.Errorf("imported design task %+v", design)
+	}
+
+	// Exporting again gives the same lists and tasks
+	var first, second fullExport
+	if err := json.Unmarshal(exported, &first); err != nil {
+		t.Fatalf("parsing first export: %v", err)
+	}
+	if err := json.Unmarshal(exportFullJSON(t, router), &second); err != nil {
+		t.Fatalf("parsing second export: %v", err)
+	}
+	if len(second.Lists) != 1 || len(second.Lists[0].Tasks) != len(first.Lists[0].Tasks) {
+		t.Errorf("re-export has %+v, want the lists and tasks of the first", second.Lists)
+	}
+}
+
+func TestNegotiatedJSONExportIsImportable(t *testing.T) {
+	source := newTestStore(t)
+	seedExportStore(t, source)
+	rec := httptest.NewRecorder()
+	NewRouter(source, embed.FS{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/export?format=json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/export?format=json: status %d: %s", rec.Code, rec.Body)
+	}
+
+	router := newTestRouter(t)
+	if rec := doJSON(t, router, http.MethodPost, "/api/import/json", rec.Body.String()); rec.Code != http.StatusCreated {
+		t.Fatalf("importing the negotiated JSON export: status %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestJSONImportRejections(t *testing.T) {
+	doc := func(tasks string) string {
+		return `{"lists":[{"id":"imported","name":"Imported","tasks":[` + tasks + `]}]}`
+	}
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"duplicate task ID", doc(`{"id":"a","title":"A","state":"todo"},{"id":"a","title":"Again","state":"todo"}`), http.StatusBadRequest},
+		{"taken task ID", doc(`{"id":"existing","title":"A","state":"todo"}`), http.StatusConflict},
+		{"taken list ID", `{"lists":[{"id":"existing-list","name":"Taken","tasks":[]}]}`, http.StatusConflict},
+		{"invalid state", doc(`{"id":"a","title":"A","state":"bogus"}`), http.StatusBadRequest},
+		{"invalid priority", doc(`{"id":"a","title":"A","state":"todo","priority":"urgent!!"}`), http.StatusBadRequest},
+		{"negative estimate", doc(`{"id":"a","title":"A","state":"todo","estimate_minutes":-5}`), http.StatusBadRequest},
+		{"missing dependency", doc(`{"id":"a","title":"A","state":"todo","depends_on":["nowhere"]}`), http.StatusBadRequest},
+		{"dependency cycle", doc(`{"id":"a","title":"A","state":"todo","depends_on":["b"]},{"id":"b","title":"B","state":"todo","depends_on":["a"]}`), http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t)
+			if err := store.CreateList(&models.TaskList{ID: "existing-list", Name: "Existing"}); err != nil {
+				t.Fatalf("CreateList: %v", err)
+			}
+			if err := store.CreateTask(&models.Task{ID: "existing", ListID: "existing-list", Title: "Existing", State: models.TaskStateTodo}); err != nil {
+				t.Fatalf("CreateTask: %v", err)
+			}
+			router := NewRouter(store, embed.FS{})
+
+			if rec := doJSON(t, router, http.MethodPost, "/api/import/json", tt.body); rec.Code != tt.want {
+				t.Errorf("status %d: %s, want %d", rec.Code, rec.Body, tt.want)
+			}
+			if _, err := store.GetList("imported"); err == nil {
+				t.Error("rejected import created its list")
+			}
+		})
+	}
+}
+
+func TestJSONImportIsAllOrNothing(t *testing.T) {
+	store := newTestStore(t)
+	store.SetTaskLimits(storage.TaskLimits{Total: 2})
+	router := NewRouter(store, embed.FS{})
+
+	body := `{"lists":[` +
+		`{"id":"first","name":"First","tasks":[{"id":"a","title":"A","state":"todo"}]},` +
+		`{"id":"second","name":"Second","tasks":[{"id":"b","title":"B","state":"todo"},{"id":"c","title":"C","state":"todo"}]}]}`
+	if rec := doJSON(t, router, http.MethodPost, "/api/import/json", body); rec.Code != http.StatusConflict {
+		t.Fatalf("import over the task limit: status %d: %s, want %d", rec.Code, rec.Body, http.StatusConflict)
+	}
+	if _, err := store.GetList("first"); err == nil {
+		t.Error("first list was created although the import failed")
+	}
+}
+
+func TestExportFailsWhenTasksCannotBeRead(t *testing.T) {
+	store := failingStore{TaskStore: newTestStore(t), err: storage.ErrCorruptData}
+	if err := store.CreateList(&models.TaskList{ID: "list", Name: "List"}); err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+
+	for _, format := range exportFormats {
+		rec := httptest.NewRecorder()
+		writeExport(rec, store, &format)
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("%s export with unreadable tasks: status %d, want %d", format.Name, rec.Code, http.StatusInternalServerError)
+		}
+	}
+}
+
+// vanishingListStore reports one list as deleted when its tasks are read,
+// as if it were deleted while an export ran, and takes delay to read tasks
+type vanishingListStore struct {
+	storage.TaskStore
+	gone  string
+	delay time.Duration
+}
+
+func (s vanishingListStore) GetTasksForList(listID string) ([]models.Task, error) {
+	time.Sleep(s.delay)
+	if listID == s.gone {
+		return nil, storage.ErrListNotFound
+	}
+	return s.TaskStore.GetTasksForList(listID)
+}
+
+func TestFullJSONExportSkipsDeletedListsAndOutlivesWriteTimeout(t *testing.T) {
+	inner := newTestStore(t)
+	seedExportStore(t, inner)
+	if err := inner.CreateList(&models.TaskList{ID: "gone", Name: "Gone"}); err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	store := vanishingListStore{TaskStore: inner, gone: "gone", delay: 150 * time.Millisecond}
+
+	server := httptest.NewUnstartedServer(NewRouter(store, embed.FS{}))
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/export/json")
+	if err != nil {
+		t.Fatalf("GET /api/export/json: %v", err)
+	}
+	defer resp.Body.Close()
+	var doc fullExport
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("export is not a complete JSON document: %v", err)
+	}
+	if len(doc.Lists) != 1 || doc.Lists[0].ID != "work" {
+		t.Errorf("exported lists %+v, want only work", doc.Lists)
+	}
+}
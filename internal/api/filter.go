@@ -0,0 +1,440 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Task filtering
+
+// taskPredicate reports whether a task matches a single filter criterion
+type taskPredicate func(task *models.Task) bool
+
+// matchAll combines predicates with AND semantics
+func matchAll(predicates ...taskPredicate) taskPredicate {
+	return func(task *models.Task) bool {
+		for _, predicate := range predicates {
+			if !predicate(task) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// stateIn matches tasks in any of the given states
+func stateIn(states ...models.TaskState) taskPredicate {
+	return func(task *models.Task) bool {
+		for _, state := range states {
+			if task.State == state {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// parseStates parses repeatable, comma-separated ?state= values. Each must be
+// a default state or a custom state of some list.
+func parseStates(store storage.TaskStore, values []string) ([]models.TaskState, error) {
+	known := append([]models.TaskState{}, models.DefaultTaskStates...)
+	if lists, err := store.GetAllLists(); err == nil {
+		for i := range lists {
+			for _, state := range lists[i].TaskStates() {
+				if !slices.Contains(known, state) {
+					known = append(known, state)
+				}
+			}
+		}
+	}
+
+	var states []models.TaskState
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			state := models.TaskState(strings.TrimSpace(name))
+			if state == "" {
+				continue
+			}
+			if !slices.Contains(known, state) {
+				return nil, fmt.Errorf("invalid state: %s (expected one of %s)", state, joinStates(known))
+			}
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
+
+// parseListIDs parses ?lists= values, each a comma-separated list of list
+// IDs, dropping blanks and duplicates
+func parseListIDs(values []string) ([]string, error) {
+	var ids []string
+	for _, value := range values {
+		for _, id := range strings.Split(value, ",") {
+			id = strings.TrimSpace(id)
+			if id == "" || slices.Contains(ids, id) {
+				continue
+			}
+			if err := storage.ValidateID(id); err != nil {
+				return nil, fmt.Errorf("invalid list ID: %q", id)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// tasksForLists loads the tasks of the given lists, reading only those
+// lists. It returns the HTTP status to report on failure.
+func tasksForLists(store storage.TaskStore, listIDs []string) ([]models.Task, int, error) {
+	var tasks []models.Task
+	for _, listID := range listIDs {
+		if _, err := store.GetList(listID); err != nil {
+			status, message := storeError(err, "list not found: "+listID)
+			return nil, status, errors.New(message)
+		}
+		listTasks, err := store.GetTasksForList(listID)
+		if err != nil {
+			status, message := storeError(err, "list not found: "+listID)
+			return nil, status, errors.New(message)
+		}
+		tasks = append(tasks, listTasks...)
+	}
+	return tasks, http.StatusOK, nil
+}
+
+// hasTag matches tasks carrying the given tag (case-insensitive)
+func hasTag(tag string) taskPredicate {
+	return func(task *models.Task) bool {
+		for _, t := range task.Tags {
+			if strings.EqualFold(t, tag) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// assignedTo matches tasks owned by the given assignee. The special value
+// "unassigned" matches tasks without an owner.
+func assignedTo(assignee string) taskPredicate {
+	if strings.EqualFold(assignee, "unassigned") {
+		assignee = ""
+	}
+	return func(task *models.Task) bool {
+		return strings.EqualFold(strings.TrimSpace(task.Assignee), assignee)
+	}
+}
+
+// priorityIs matches tasks with the given priority
+func priorityIs(priority models.TaskPriority) taskPredicate {
+	return func(task *models.Task) bool {
+		return task.Priority == priority
+	}
+}
+
+// dueBefore matches tasks due strictly before the given time
+func dueBefore(t time.Time) taskPredicate {
+	return func(task *models.Task) bool {
+		return task.DueDate != nil && task.DueDate.Before(t)
+	}
+}
+
+// dueBetween matches tasks due in [start, end)
+func dueBetween(start, end time.Time) taskPredicate {
+	return func(task *models.Task) bool {
+		return task.DueDate != nil && !task.DueDate.Before(start) && task.DueDate.Before(end)
+	}
+}
+
+// completedBetween matches tasks completed at or after after and strictly
+// before before. A nil bound is open; tasks that aren't done never match.
+func completedBetween(after, before *time.Time) taskPredicate {
+	return func(task *models.Task) bool {
+		if task.CompletedAt == nil {
+			return false
+		}
+		if after != nil && task.CompletedAt.Before(*after) {
+			return false
+		}
+		return before == nil || task.CompletedAt.Before(*before)
+	}
+}
+
+// flaggedIs matches tasks whose flag is set to flagged
+func flaggedIs(flagged bool) taskPredicate {
+	return func(task *models.Task) bool {
+		return task.Flagged == flagged
+	}
+}
+
+// readyAt matches tasks that are actionable at now, see models.Task.Ready
+func readyAt(now time.Time) taskPredicate {
+	return func(task *models.Task) bool {
+		return task.Ready(now)
+	}
+}
+
+// updatedAfter matches tasks updated strictly after since
+func updatedAfter(since time.Time) taskPredicate {
+	return func(task *models.Task) bool {
+		return task.UpdatedAt.After(since)
+	}
+}
+
+// parseTimeParam reads a query parameter holding an RFC 3339 timestamp, a
+// date or a relative date expression, returning nil when it is not set
+func parseTimeParam(values url.Values, name string, now time.Time) (*time.Time, error) {
+	value := strings.TrimSpace(values.Get(name))
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := parseRelativeDate(value, now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s (expected a timestamp, a date such as 2006-01-02 or a relative date)", name, value)
+	}
+	return &t, nil
+}
+
+// dueWindow matches tasks by the ?due= window: "overdue" (past due and not
+// done), "today", or "week" (due within seven days starting today). Tasks
+// without a due date never match.
+func dueWindow(window string, now time.Time) (taskPredicate, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch strings.ToLower(strings.TrimSpace(window)) {
+	case "overdue":
+		return func(task *models.Task) bool {
+			return isOverdue(task, now)
+		}, nil
+	case "today":
+		return dueBetween(today, today.AddDate(0, 0, 1)), nil
+	case "week":
+		return dueBetween(today, today.AddDate(0, 0, 7)), nil
+	default:
+		return nil, fmt.Errorf("invalid due: %s (expected overdue, today or week)", window)
+	}
+}
+
+// hasDueDate matches tasks with (or without) a due date
+func hasDueDate(want bool) taskPredicate {
+	return func(task *models.Task) bool {
+		return (task.DueDate != nil) == want
+	}
+}
+
+// containsText matches tasks whose title, description or notes contain the
+// query (case-insensitive)
+func containsText(query string) taskPredicate {
+	query = strings.ToLower(query)
+	return func(task *models.Task) bool {
+		if strings.Contains(strings.ToLower(task.Title), query) ||
+			strings.Contains(strings.ToLower(task.Description), query) {
+			return true
+		}
+		for _, note := range task.Notes {
+			if strings.Contains(strings.ToLower(note.Content), query) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// filterTasks returns the tasks matching the predicate
+func filterTasks(tasks []models.Task, predicate taskPredicate) []models.Task {
+	matched := make([]models.Task, 0, len(tasks))
+	for i := range tasks {
+		if predicate(&tasks[i]) {
+			matched = append(matched, tasks[i])
+		}
+	}
+	return matched
+}
+
+// parseTaskFilter reads filter criteria from query parameters
+func parseTaskFilter(values url.Values) models.TaskFilter {
+	return models.TaskFilter{
+		State:     values.Get("state"),
+		Tag:       values.Get("tag"),
+		Assignee:  values.Get("assignee"),
+		Priority:  values.Get("priority"),
+		DueBefore: values.Get("due_before"),
+		HasDue:    values.Get("has_due"),
+		Query:     values.Get("q"),
+	}
+}
+
+// filterPredicate builds a predicate from the filter criteria, returning an
+// error describing the first invalid value. States must be known to the
+// store, as for parseStates.
+func filterPredicate(store storage.TaskStore, f models.TaskFilter) (taskPredicate, error) {
+	var predicates []taskPredicate
+
+	if f.State != "" {
+		states, err := parseStates(store, []string{f.State})
+		if err != nil {
+			return nil, err
+		}
+		if len(states) > 0 {
+			predicates = append(predicates, stateIn(states...))
+		}
+	}
+
+	if f.Tag != "" {
+		predicates = append(predicates, hasTag(strings.TrimSpace(f.Tag)))
+	}
+
+	if f.Assignee != "" {
+		predicates = append(predicates, assignedTo(strings.TrimSpace(f.Assignee)))
+	}
+
+	if f.Priority != "" {
+		priority := models.TaskPriority(strings.ToLower(strings.TrimSpace(f.Priority)))
+		if !priority.Valid() {
+			return nil, fmt.Errorf("invalid priority: %s (expected low, medium, high or urgent)", f.Priority)
+		}
+		predicates = append(predicates, priorityIs(priority))
+	}
+
+	if f.DueBefore != "" {
+		before, err := parseRelativeDate(f.DueBefore, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("invalid due_before: %w", err)
+		}
+		predicates = append(predicates, dueBefore(before))
+	}
+
+	if f.HasDue != "" {
+		want, err := strconv.ParseBool(f.HasDue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid has_due: %s", f.HasDue)
+		}
+		predicates = append(predicates, hasDueDate(want))
+	}
+
+	if q := strings.TrimSpace(f.Query); q != "" {
+		predicates = append(predicates, containsText(q))
+	}
+
+	return matchAll(predicates...), nil
+}
+
+// HandleFilterTasks returns all tasks matching the combined filter criteria
+func HandleFilterTasks(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		predicate, err := filterPredicate(store, parseTaskFilter(r.URL.Query()))
+		if err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		tasks, err := store.GetAllTasks()
+		if err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve tasks")
+			return
+		}
+
+		tasks, ok := paginate(w, r, filterTasks(withoutArchived(r, tasks), predicate))
+		if !ok {
+			return
+		}
+
+		writeJSON(w, http.StatusOK, taskViews(tasks))
+	}
+}
+
+// Saved Filter Handlers
+
+// HandleGetSavedFilters returns all saved filters
+func HandleGetSavedFilters(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filters, err := store.GetSavedFilters()
+		if err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve filters")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, filters)
+	}
+}
+
+// HandleSaveFilter creates or replaces a named filter
+func HandleSaveFilter(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var filter models.SavedFilter
+		if err := decodeBody(r, &filter); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "Invalid filter data")
+			return
+		}
+
+		filter.Name = strings.TrimSpace(filter.Name)
+		if filter.Name == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Filter name is required")
+			return
+		}
+
+		// Reject criteria that could never be applied
+		if _, err := filterPredicate(store, filter.Criteria); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := store.SaveFilter(&filter); err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to save filter")
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, filter)
+	}
+}
+
+// HandleDeleteSavedFilter deletes a named filter
+func HandleDeleteSavedFilter(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := chi.URLParam(r, "name")
+		if err := store.DeleteSavedFilter(name); err != nil {
+			writeErrorJSON(w, http.StatusNotFound, "Filter not found")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// HandleGetSavedFilterTasks returns the tasks matching a saved filter
+func HandleGetSavedFilterTasks(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := store.GetSavedFilter(chi.URLParam(r, "name"))
+		if err != nil {
+			writeErrorJSON(w, http.StatusNotFound, "Filter not found")
+			return
+		}
+
+		predicate, err := filterPredicate(store, filter.Criteria)
+		if err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		tasks, err := store.GetAllTasks()
+		if err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve tasks")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, filterTasks(withoutArchived(r, tasks), predicate))
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// filterTestTasks returns tasks that differ in every criterion the filter
+// endpoint supports
+func filterTestTasks() []models.Task {
+	soon := time.Now().Add(24 * time.Hour)
+	later := time.Now().AddDate(0, 1, 0)
+	return []models.Task{
+		{ID: "a", Title: "Write report", State: models.TaskStateTodo, Priority: models.TaskPriorityHigh, Tags: []string{"Work"}, Assignee: "sam", DueDate: &soon},
+		{ID: "b", Title: "Review", Description: "the quarterly REPORT", State: models.TaskStateInProgress, Priority: models.TaskPriorityLow, Tags: []string{"work", "review"}, DueDate: &later},
+		{ID: "c", Title: "Groceries", State: models.TaskStateDone, Priority: models.TaskPriorityHigh, Tags: []string{"home"}, Assignee: "alex",
+			Notes: []models.Note{{Content: "Remember the report paper"}}},
+		{ID: "d", Title: "Call plumber", State: models.TaskStateTodo, Priority: models.TaskPriorityMedium},
+	}
+}
+
+func TestFilterPredicate(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter models.TaskFilter
+		want   string
+	}{
+		{"no criteria", models.TaskFilter{}, "a,b,c,d"},
+		{"state", models.TaskFilter{State: "todo"}, "a,d"},
+		{"several states", models.TaskFilter{State: "todo, done"}, "a,c,d"},
+		{"tag is case-insensitive", models.TaskFilter{Tag: "WORK"}, "a,b"},
+		{"assignee", models.TaskFilter{Assignee: "Sam"}, "a"},
+		{"unassigned", models.TaskFilter{Assignee: "unassigned"}, "b,d"},
+		{"priority", models.TaskFilter{Priority: "High"}, "a,c"},
+		{"due before", models.TaskFilter{DueBefore: "+7d"}, "a"},
+		{"has due", models.TaskFilter{HasDue: "true"}, "a,b"},
+		{"has no due", models.TaskFilter{HasDue: "false"}, "c,d"},
+		{"text in title, description or notes", models.TaskFilter{Query: "report"}, "a,b,c"},
+		{"state and priority", models.TaskFilter{State: "todo", Priority: "high"}, "a"},
+		{"tag and text", models.TaskFilter{Tag: "work", Query: "quarterly"}, "b"},
+		{"text and has due", models.TaskFilter{Query: "report", HasDue: "false"}, "c"},
+		{"every criterion", models.TaskFilter{State: "todo", Tag: "work", Assignee: "sam", Priority: "high", DueBefore: "+7d", HasDue: "true", Query: "write"}, "a"},
+		{"contradictory criteria", models.TaskFilter{State: "done", HasDue: "true"}, ""},
+	}
+
+	store := newTestStore(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			predicate, err := filterPredicate(store, tt.filter)
+			if err != nil {
+				t.Fatalf("filterPredicate: %v", err)
+			}
+			var ids []string
+			for _, task := range filterTasks(filterTestTasks(), predicate) {
+				ids = append(ids, task.ID)
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("matched %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterPredicateRejectsInvalidCriteria(t *testing.T) {
+	store := newTestStore(t)
+	if err := store.CreateList(&models.TaskList{ID: "work", Name: "Work", States: []string{"open", "closed"}}); err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+
+	for _, filter := range []models.TaskFilter{
+		{State: "someday"},
+		{State: "todo,someday"},
+		{Priority: "whenever"},
+		{DueBefore: "not a date"},
+		{HasDue: "maybe"},
+	} {
+		if _, err := filterPredicate(store, filter); err == nil {
+			t.Errorf("filterPredicate(%+v) succeeded, want an error", filter)
+		}
+	}
+
+	// A custom state of any list is accepted
+	if _, err := filterPredicate(store, models.TaskFilter{State: "open"}); err != nil {
+		t.Errorf("filterPredicate with a custom state: %v", err)
+	}
+}
+
+func TestFilterEndpointRejectsUnknownState(t *testing.T) {
+	router := newTestRouter(t)
+	if rec := doJSON(t, router, http.MethodGet, "/api/tasks/filter?state=someday", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("filter by unknown state: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := doJSON(t, router, http.MethodGet, "/api/tasks/filter?state=todo", ""); rec.Code != http.StatusOK {
+		t.Errorf("filter by known state: status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+}
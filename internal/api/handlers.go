@@ -2,9 +2,16 @@ package api
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"mime"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,12 +23,28 @@ import (
 
 // API Handlers for Task Lists
 
-// HandleGetAllLists returns all task lists
-func HandleGetAllLists(store *storage.FileStore) http.HandlerFunc {
+// HandleGetAllLists returns all task lists in display order. Archived lists
+// are left out unless include_archived=true.
+func HandleGetAllLists(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		lists, err := store.GetAllLists()
+		var lists []models.TaskList
+		var err error
+		if r.URL.Query().Get("include_errors") == "true" {
+			lists, err = store.GetAllListsWithErrors()
+		} else {
+			lists, err = store.GetAllLists()
+		}
 		if err != nil {
-			writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve lists")
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		if r.URL.Query().Get("include_archived") != "true" {
+			lists = activeLists(lists)
+		}
+
+		lists, ok := paginate(w, r, lists)
+		if !ok {
 			return
 		}
 
@@ -30,13 +53,13 @@ func HandleGetAllLists(store *storage.FileStore) http.HandlerFunc {
 }
 
 // HandleCreateList creates a new task list
-func HandleCreateList(store *storage.FileStore) http.HandlerFunc {
+func HandleCreateList(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var list models.TaskList
 
-		err := decodeBody(r, &list)
+		err := decodeStrict(r, &list)
 		if err != nil {
-			writeErrorJSON(w, http.StatusBadRequest, "Invalid list data")
+			writeDecodeError(w, err)
 			return
 		}
 
@@ -46,10 +69,29 @@ func HandleCreateList(store *storage.FileStore) http.HandlerFunc {
 			return
 		}
 
+		if err := validateDescriptionTemplate(&list); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := validateListStates(&list); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := validateListAppearance(&list); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		// Generate ID if not provided
 		if list.ID == "" {
 			list.ID = uuid.New().String()
 		}
+		if err := storage.ValidateID(list.ID); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "Invalid list ID: IDs may only contain letters, digits, dashes and underscores")
+			return
+		}
 
 		// Set timestamps
 		now := time.Now()
@@ -59,16 +101,17 @@ func HandleCreateList(store *storage.FileStore) http.HandlerFunc {
 		// Save the list
 		err = store.CreateList(&list)
 		if err != nil {
-			writeErrorJSON(w, http.StatusInternalServerError, "Failed to create list")
+			writeCreateError(w, err, "Failed to create list")
 			return
 		}
 
+		w.Header().Set("Location", listLocation(list.ID))
 		writeJSON(w, http.StatusCreated, list)
 	}
 }
 
 // HandleGetList returns a specific task list
-func HandleGetList(store *storage.FileStore) http.HandlerFunc {
+func HandleGetList(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		listID := chi.URLParam(r, "listID")
 		if listID == "" {
@@ -78,16 +121,16 @@ func HandleGetList(store *storage.FileStore) http.HandlerFunc {
 
 		list, err := store.GetList(listID)
 		if err != nil {
-			writeErrorJSON(w, http.StatusNotFound, "List not found")
+			writeStoreError(w, err, "List not found")
 			return
 		}
 
-		writeJSON(w, http.StatusOK, list)
+		writeJSONWithETag(w, r, list)
 	}
 }
 
 // HandleUpdateList updates a task list
-func HandleUpdateList(store *storage.FileStore) http.HandlerFunc {
+func HandleUpdateList(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		listID := chi.URLParam(r, "listID")
 		if listID == "" {
@@ -96,9 +139,9 @@ func HandleUpdateList(store *storage.FileStore) http.HandlerFunc {
 		}
 
 		var list models.TaskList
-		err := decodeBody(r, &list)
+		err := decodeStrict(r, &list)
 		if err != nil {
-			writeErrorJSON(w, http.StatusBadRequest, "Invalid list data")
+			writeDecodeError(w, err)
 			return
 		}
 
@@ -108,12 +151,27 @@ func HandleUpdateList(store *storage.FileStore) http.HandlerFunc {
 			return
 		}
 
+		if err := validateDescriptionTemplate(&list); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := validateListStates(&list); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := validateListAppearance(&list); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		// Update timestamps
 		list.UpdatedAt = time.Now()
 
 		err = store.UpdateList(&list)
 		if err != nil {
-			writeErrorJSON(w, http.StatusNotFound, "List not found")
+			writeStoreError(w, err, "List not found")
 			return
 		}
 
@@ -122,7 +180,7 @@ func HandleUpdateList(store *storage.FileStore) http.HandlerFunc {
 }
 
 // HandleDeleteList deletes a task list
-func HandleDeleteList(store *storage.FileStore) http.HandlerFunc {
+func HandleDeleteList(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		listID := chi.URLParam(r, "listID")
 		if listID == "" {
@@ -132,7 +190,7 @@ func HandleDeleteList(store *storage.FileStore) http.HandlerFunc {
 
 		err := store.DeleteList(listID)
 		if err != nil {
-			writeErrorJSON(w, http.StatusNotFound, "List not found")
+			writeStoreError(w, err, "List not found")
 			return
 		}
 
@@ -140,23 +198,262 @@ func HandleDeleteList(store *storage.FileStore) http.HandlerFunc {
 	}
 }
 
+// activeLists returns the lists that are not archived
+func activeLists(lists []models.TaskList) []models.TaskList {
+	active := make([]models.TaskList, 0, len(lists))
+	for _, list := range lists {
+		if !list.Archived {
+			active = append(active, list)
+		}
+	}
+	return active
+}
+
+// reorderListsRequest is the payload accepted by HandleReorderLists
+type reorderListsRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// HandleReorderLists sets the display order of the lists. Lists left out of
+// the request keep their relative order after the listed ones.
+func HandleReorderLists(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req reorderListsRequest
+		if err := decodeBody(r, &req); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "Invalid request data")
+			return
+		}
+
+		if len(req.IDs) == 0 {
+			writeErrorJSON(w, http.StatusBadRequest, "At least one list ID is required")
+			return
+		}
+
+		if err := store.ReorderLists(req.IDs); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "Failed to reorder lists: "+err.Error())
+			return
+		}
+
+		lists, err := store.GetAllLists()
+		if err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, lists)
+	}
+}
+
+// renameListIDRequest is the body of POST /api/lists/{listID}/rename-id
+type renameListIDRequest struct {
+	ID string `json:"id"`
+}
+
+// HandleRenameListID changes a list's ID, moving its tasks along with it, so
+// imported data can keep stable IDs
+func HandleRenameListID(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+
+		var req renameListIDRequest
+		if err := decodeStrict(r, &req); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		if req.ID == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "New list ID is required")
+			return
+		}
+		if err := storage.ValidateID(req.ID); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if req.ID == listID {
+			writeErrorJSON(w, http.StatusBadRequest, "List already has ID "+listID)
+			return
+		}
+
+		list, err := store.RenameListID(listID, req.ID)
+		if errors.Is(err, storage.ErrListExists) {
+			writeErrorJSON(w, http.StatusConflict, "A list with ID "+req.ID+" already exists")
+			return
+		}
+		if err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		w.Header().Set("Location", listLocation(list.ID))
+		writeJSON(w, http.StatusOK, list)
+	}
+}
+
 // API Handlers for Tasks
 
 // HandleGetAllTasks returns all tasks across all lists
-func HandleGetAllTasks(store *storage.FileStore) http.HandlerFunc {
+func HandleGetAllTasks(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		tasks, err := store.GetAllTasks()
+		query := r.URL.Query()
+
+		// Without per-task filtering only the requested page needs loading
+		if query.Get("include_archived") == "true" && !query.Has("flatten_subtasks") && !query.Has("tag") && !query.Has("assignee") &&
+			!query.Has("due") && !query.Has("state") && !query.Has("completed_after") && !query.Has("completed_before") && !query.Has("lists") &&
+			!query.Has("updated_since") && !query.Has("ready") && !query.Has("flagged") {
+			limit, offset, paged, ok := pageParams(w, r)
+			if !ok {
+				return
+			}
+			if paged {
+				tasks, total, err := store.GetTasksPage(offset, limit)
+				if err != nil {
+					writeStoreError(w, err, "List not found")
+					return
+				}
+
+				setPageHeaders(w, total, limit, offset)
+				writeJSON(w, http.StatusOK, taskViews(tasks))
+				return
+			}
+		}
+
+		listIDs, err := parseListIDs(query["lists"])
+		if err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// ?lists= reads only the requested lists instead of every task
+		var tasks []models.Task
+		if len(listIDs) > 0 {
+			var status int
+			if tasks, status, err = tasksForLists(store, listIDs); err != nil {
+				writeErrorJSON(w, status, err.Error())
+				return
+			}
+		} else if tasks, err = store.GetAllTasks(); err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+		tasks = withoutArchived(r, tasks)
+
+		// Optionally hoist subtasks into the top-level array
+		if r.URL.Query().Get("flatten_subtasks") == "true" {
+			tasks = flattenSubTasks(tasks)
+		}
+
+		if tag := strings.TrimSpace(r.URL.Query().Get("tag")); tag != "" {
+			tasks = filterTasks(tasks, hasTag(tag))
+		}
+
+		if assignee := strings.TrimSpace(r.URL.Query().Get("assignee")); assignee != "" {
+			tasks = filterTasks(tasks, assignedTo(assignee))
+		}
+
+		if due := r.URL.Query().Get("due"); due != "" {
+			predicate, err := dueWindow(due, time.Now())
+			if err != nil {
+				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			tasks = filterTasks(tasks, predicate)
+		}
+
+		if query.Has("state") {
+			states, err := parseStates(store, query["state"])
+			if err != nil {
+				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if len(states) > 0 {
+				tasks = filterTasks(tasks, stateIn(states...))
+			}
+		}
+
+		if query.Has("completed_after") || query.Has("completed_before") {
+			now := time.Now()
+			after, err := parseTimeParam(query, "completed_after", now)
+			if err != nil {
+				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			before, err := parseTimeParam(query, "completed_before", now)
+			if err != nil {
+				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			tasks = filterTasks(tasks, completedBetween(after, before))
+		}
+
+		since, err := parseTimeParam(query, "updated_since", time.Now())
 		if err != nil {
-			writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve tasks")
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		if since != nil {
+			tasks = filterTasks(tasks, updatedAfter(*since))
+		}
+
+		if query.Get("ready") == "true" {
+			tasks = filterTasks(tasks, readyAt(time.Now()))
+		}
+
+		if value := query.Get("flagged"); value != "" {
+			flagged, err := parseFlagged(value)
+			if err != nil {
+				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			tasks = filterTasks(tasks, flaggedIs(flagged))
+		}
+
+		tasks, ok := paginate(w, r, tasks)
+		if !ok {
+			return
+		}
+
+		writeJSON(w, http.StatusOK, taskViews(tasks))
+	}
+}
+
+// flattenSubTasks recursively hoists subtasks into a single slice with their
+// ParentID set. Task IDs that were already visited are skipped so that
+// malformed data referencing the same task twice cannot loop forever.
+func flattenSubTasks(tasks []models.Task) []models.Task {
+	flat := make([]models.Task, 0, len(tasks))
+	seen := make(map[string]bool)
 
-		writeJSON(w, http.StatusOK, tasks)
+	var walk func(task models.Task, parent *models.Task)
+	walk = func(task models.Task, parent *models.Task) {
+		if task.ID != "" {
+			if seen[task.ID] {
+				return
+			}
+			seen[task.ID] = true
+		}
+
+		subTasks := task.SubTasks
+		task.SubTasks = nil
+		if parent != nil {
+			task.ParentID = parent.ID
+			if task.ListID == "" {
+				task.ListID = parent.ListID
+			}
+		}
+		flat = append(flat, task)
+
+		for _, subTask := range subTasks {
+			walk(subTask, &task)
+		}
 	}
+
+	for _, task := range tasks {
+		walk(task, nil)
+	}
+	return flat
 }
 
 // HandleGetTasksForList returns all tasks in a list
-func HandleGetTasksForList(store *storage.FileStore) http.HandlerFunc {
+func HandleGetTasksForList(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		listID := chi.URLParam(r, "listID")
 		if listID == "" {
@@ -166,16 +463,62 @@ func HandleGetTasksForList(store *storage.FileStore) http.HandlerFunc {
 
 		tasks, err := store.GetTasksForList(listID)
 		if err != nil {
-			writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve tasks")
+			writeStoreError(w, err, "List not found")
+			return
+		}
+		tasks = withoutArchived(r, tasks)
+
+		if due := r.URL.Query().Get("due"); due != "" {
+			predicate, err := dueWindow(due, time.Now())
+			if err != nil {
+				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			tasks = filterTasks(tasks, predicate)
+		}
+
+		since, err := parseTimeParam(r.URL.Query(), "updated_since", time.Now())
+		if err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if since != nil {
+			tasks = filterTasks(tasks, updatedAfter(*since))
+		}
+
+		if r.URL.Query().Get("ready") == "true" {
+			tasks = filterTasks(tasks, readyAt(time.Now()))
+		}
+
+		if value := r.URL.Query().Get("flagged"); value != "" {
+			flagged, err := parseFlagged(value)
+			if err != nil {
+				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			tasks = filterTasks(tasks, flaggedIs(flagged))
+		}
+
+		switch sortBy := r.URL.Query().Get("sort"); sortBy {
+		case "":
+		case "priority":
+			models.SortTasksByPriority(tasks)
+		default:
+			writeErrorJSON(w, http.StatusBadRequest, "Unsupported sort: "+sortBy)
+			return
+		}
+
+		tasks, ok := paginate(w, r, tasks)
+		if !ok {
 			return
 		}
 
-		writeJSON(w, http.StatusOK, tasks)
+		writeJSONWithETag(w, r, taskViews(tasks))
 	}
 }
 
 // HandleCreateTask creates a new task in a list
-func HandleCreateTask(store *storage.FileStore) http.HandlerFunc {
+func HandleCreateTask(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		listID := chi.URLParam(r, "listID")
 		if listID == "" {
@@ -198,53 +541,39 @@ func HandleCreateTask(store *storage.FileStore) http.HandlerFunc {
 			task.Title = r.FormValue("title")
 			task.Description = r.FormValue("description")
 			task.State = models.TaskState(r.FormValue("state"))
-
-			// Parse due date if provided
-			dueDateStr := r.FormValue("due_date")
-			if dueDateStr != "" {
-				dueDate, err := time.Parse("2006-01-02", dueDateStr)
-				if err == nil {
-					task.DueDate = &dueDate
-				}
+			task.BlockedReason = r.FormValue("blocked_reason")
+			task.Tags = parseTags(r.FormValue("tags"))
+			task.Assignee = r.FormValue("assignee")
+			if err := parseEffortForm(r, &task); err != nil {
+				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if err := parseFlaggedForm(r, &task); err != nil {
+				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if err := parseDateForm(r, &task, time.Now()); err != nil {
+				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				return
 			}
 		} else {
 			// Decode JSON body
-			err := decodeBody(r, &task)
+			err := decodeTask(r, &task)
 			if err != nil {
-				writeErrorJSON(w, http.StatusBadRequest, "Invalid task data")
+				writeDecodeError(w, err)
 				return
 			}
 		}
 
-		// Validate task data
-		if task.Title == "" {
-			writeErrorJSON(w, http.StatusBadRequest, "Task title is required")
+		if status, err := prepareNewTask(store, listID, &task); err != nil {
+			writeErrorJSON(w, status, err.Error())
 			return
 		}
 
-		// Set list ID
-		task.ListID = listID
-
-		// Generate ID if not provided
-		if task.ID == "" {
-			task.ID = uuid.New().String()
-		}
-
-		// Set timestamps and state
-		now := time.Now()
-		task.CreatedAt = now
-		task.UpdatedAt = now
-
-		// Set default state if not provided
-		if task.State == "" {
-			task.State = models.TaskStateTodo
-		}
-		task.StateTime = now
-
 		// Save the task
 		err := store.CreateTask(&task)
 		if err != nil {
-			writeErrorJSON(w, http.StatusInternalServerError, "Failed to create task")
+			writeCreateError(w, err, "Failed to create task")
 			return
 		}
 
@@ -256,17 +585,85 @@ func HandleCreateTask(store *storage.FileStore) http.HandlerFunc {
 				http.Error(w, "Failed to retrieve tasks", http.StatusInternalServerError)
 				return
 			}
-			html := renderTasksContainer(tasks)
-			writeHTMX(w, http.StatusOK, html)
+			renderTasksContainer(w, tasks)
 			return
 		}
 
-		writeJSON(w, http.StatusCreated, task)
+		w.Header().Set("Location", taskLocation(task.ListID, task.ID))
+		writeJSON(w, http.StatusCreated, newTaskView(&task))
+	}
+}
+
+// prepareNewTask fills in the server-assigned fields of a task about to be
+// created in a list and validates it, returning the HTTP status to report
+// along with any error
+func prepareNewTask(store storage.TaskStore, listID string, task *models.Task) (int, error) {
+	if task.Title == "" {
+		return http.StatusBadRequest, fmt.Errorf("Task title is required")
+	}
+
+	task.ListID = listID
+
+	// Generate ID if not provided
+	if task.ID == "" {
+		task.ID = uuid.New().String()
+	}
+	if err := storage.ValidateID(task.ID); err != nil {
+		return http.StatusBadRequest, fmt.Errorf("Invalid task ID: IDs may only contain letters, digits, dashes and underscores")
+	}
+
+	// Set timestamps and state
+	now := time.Now()
+	task.CreatedAt = now
+	task.UpdatedAt = now
+
+	// Set default state and priority if not provided
+	if task.State == "" {
+		task.State = defaultTaskState(store, listID)
+	}
+	if task.Priority == "" {
+		task.Priority = models.TaskPriorityMedium
+	}
+	task.StateTime = now
+	task.RecordReminder(nil)
+
+	normalizeTags(task)
+	task.Assignee = strings.TrimSpace(task.Assignee)
+	if err := normalizeBlockedReason(task); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if err := validateStartDate(task); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if err := validateEffort(task); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if err := validateTaskState(store, task); err != nil {
+		return http.StatusBadRequest, err
 	}
+
+	normalizeDependsOn(task)
+	if status, err := validateDependencies(store, task); err != nil {
+		return status, err
+	}
+	if err := checkCanStart(store, task, ""); err != nil {
+		return http.StatusConflict, err
+	}
+
+	if !task.Recurrence.Valid() {
+		return http.StatusBadRequest, fmt.Errorf("Invalid recurrence: %s", task.Recurrence)
+	}
+	if !task.Priority.Valid() {
+		return http.StatusBadRequest, fmt.Errorf("Invalid priority: %s (expected low, medium, high or urgent)", task.Priority)
+	}
+
+	applyDescriptionTemplate(store, task)
+	return http.StatusOK, nil
 }
 
 // HandleGetTask returns a specific task
-func HandleGetTask(store *storage.FileStore) http.HandlerFunc {
+func HandleGetTask(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		listID := chi.URLParam(r, "listID")
 		taskID := chi.URLParam(r, "taskID")
@@ -277,16 +674,16 @@ func HandleGetTask(store *storage.FileStore) http.HandlerFunc {
 
 		task, err := store.GetTask(listID, taskID)
 		if err != nil {
-			writeErrorJSON(w, http.StatusNotFound, "Task not found")
+			writeStoreError(w, err, "Task not found")
 			return
 		}
 
-		writeJSON(w, http.StatusOK, task)
+		writeJSONWithETag(w, r, newTaskView(task))
 	}
 }
 
 // HandleUpdateTask updates a task
-func HandleUpdateTask(store *storage.FileStore) http.HandlerFunc {
+func HandleUpdateTask(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		listID := chi.URLParam(r, "listID")
 		taskID := chi.URLParam(r, "taskID")
@@ -298,78 +695,219 @@ func HandleUpdateTask(store *storage.FileStore) http.HandlerFunc {
 		// Try to load existing task, but continue even if not found for new tasks
 		existingTask, err := store.GetTask(listID, taskID)
 		if err == nil {
+			// If-Match guards against overwriting changes made since the
+			// client last read the task
+			if match := r.Header.Get("If-Match"); match != "" {
+				if _, etag, err := jsonETag(newTaskView(existingTask)); err == nil && !etagMatchesStrong(match, etag) {
+					writeErrorJSON(w, http.StatusPreconditionFailed, "Task has been modified since it was read")
+					return
+				}
+			}
+
 			// We found the task, update it
 			// Parse updates from request body
 			updatedTask := *existingTask // Start with existing data
 			
 			if err = parseTaskFormOrJSON(r, &updatedTask); err != nil {
-				writeErrorJSON(w, http.StatusBadRequest, err.Error())
-				return
-			}
-			
-			// Update timestamp and handle state changes
-			updatedTask.UpdatedAt = time.Now()
-			if updatedTask.State != existingTask.State {
-				updatedTask.StateTime = time.Now()
-			}
-			
-			// Handle list changes (move task if needed)
-			if updatedTask.ListID != listID {
-				_, err = store.MoveTask(listID, taskID, updatedTask.ListID)
-			} else {
-				err = store.UpdateTask(&updatedTask)
-			}
-			
-			if err != nil {
-				writeErrorJSON(w, http.StatusInternalServerError, "Failed to update task: "+err.Error())
+				writeDecodeError(w, err)
 				return
 			}
-			
-			// Return response based on request type
-			handleTaskResponse(w, r, store, &updatedTask)
+
+			saveTaskUpdate(w, r, store, listID, existingTask, updatedTask)
 			
 		} else {
-			// Task doesn't exist, create new one
+			// Task doesn't exist, create new one under the ID in the URL
 			var newTask models.Task
-			newTask.ID = taskID
-			newTask.ListID = listID
-			
 			if err = parseTaskFormOrJSON(r, &newTask); err != nil {
-				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				writeDecodeError(w, err)
 				return
 			}
-			
-			// Set timestamps for new task
-			now := time.Now()
-			newTask.CreatedAt = now
-			newTask.UpdatedAt = now
-			newTask.StateTime = now
-			
-			// Ensure state is set
-			if newTask.State == "" {
-				newTask.State = models.TaskStateTodo
+			newTask.ID = taskID
+
+			if status, err := prepareNewTask(store, listID, &newTask); err != nil {
+				writeErrorJSON(w, status, err.Error())
+				return
 			}
 			
 			// Save the new task
 			err = store.CreateTask(&newTask)
 			if err != nil {
-				writeErrorJSON(w, http.StatusInternalServerError, "Failed to create task: "+err.Error())
+				writeCreateError(w, err, "Failed to create task: "+err.Error())
 				return
 			}
 			
-			// Return response based on request type
-			handleTaskResponse(w, r, store, &newTask)
+			if r.Header.Get("HX-Request") == "true" {
+				handleTaskResponse(w, r, store, &newTask)
+				return
+			}
+			w.Header().Set("Location", taskLocation(newTask.ListID, newTask.ID))
+			writeJSON(w, http.StatusCreated, newTaskView(&newTask))
 		}
 	}
 }
 
-// Helper function to parse task data from either form or JSON
-func parseTaskFormOrJSON(r *http.Request, task *models.Task) error {
-	contentType := r.Header.Get("Content-Type")
+// saveTaskUpdate validates an updated copy of an existing task and saves it,
+// moving it when its list differs from listID, then writes the response.
+// Shared by PUT and PATCH once the request has been applied to the task.
+func saveTaskUpdate(w http.ResponseWriter, r *http.Request, store storage.TaskStore, listID string, existingTask *models.Task, updatedTask models.Task) {
+	taskID := existingTask.ID
+	var err error
+
+	// A version in the request must match the stored one; the store
+	// repeats the check atomically when saving
+	if updatedTask.Version != 0 && updatedTask.Version != existingTask.Version {
+		writeErrorJSON(w, http.StatusConflict, fmt.Sprintf("Task has been modified: version %d is stale, current is %d", updatedTask.Version, existingTask.Version))
+		return
+	}
 	
-	if strings.Contains(contentType, "application/x-www-form-urlencoded") {
-		err := r.ParseForm()
-		if err != nil {
+	normalizeTags(&updatedTask)
+	if err = normalizeBlockedReason(&updatedTask); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err = validateStartDate(&updatedTask); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err = validateEffort(&updatedTask); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Moves are checked against the destination workflow by MoveTask
+	if updatedTask.ListID == listID {
+		if err = validateTaskState(store, &updatedTask); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	normalizeDependsOn(&updatedTask)
+	if !slices.Equal(updatedTask.DependsOn, existingTask.DependsOn) {
+		if status, err := validateDependencies(store, &updatedTask); err != nil {
+			writeErrorJSON(w, status, err.Error())
+			return
+		}
+	}
+	if err = checkCanStart(store, &updatedTask, existingTask.State); err != nil {
+		writeErrorJSON(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	if !updatedTask.Recurrence.Valid() {
+		writeErrorJSON(w, http.StatusBadRequest, "Invalid recurrence: "+string(updatedTask.Recurrence))
+		return
+	}
+	if !updatedTask.Priority.Valid() {
+		writeErrorJSON(w, http.StatusBadRequest, "Invalid priority: "+string(updatedTask.Priority)+" (expected low, medium, high or urgent)")
+		return
+	}
+	
+	autoCompleteParent(store, &updatedTask)
+
+	// Update timestamp and handle state changes
+	updatedTask.UpdatedAt = time.Now()
+	updatedTask.RecordArchive(existingTask, updatedTask.UpdatedAt)
+	updatedTask.RecordReminder(existingTask)
+	if updatedTask.State != existingTask.State {
+		updatedTask.StateTime = time.Now()
+	}
+
+	// Completing a recurring task regenerates it for the next interval
+	if updatedTask.Recurrence != "" && updatedTask.State == models.TaskStateDone && existingTask.State != models.TaskStateDone {
+		updatedTask.CompleteOccurrence(time.Now())
+	}
+	
+	// Handle list changes (move task if needed). ?reset_state=true
+	// moves tasks whose state the destination list does not allow
+	// into its first state.
+	if updatedTask.ListID != listID {
+		opts := storage.MoveOptions{ResetState: r.URL.Query().Get("reset_state") == "true"}
+		if updatedTask.Position != existingTask.Position {
+			opts.Position = &updatedTask.Position
+		}
+
+		var movedTask *models.Task
+		if movedTask, err = store.MoveTask(listID, taskID, updatedTask.ListID, opts); err == nil {
+			updatedTask = *movedTask
+		}
+	} else {
+		err = store.UpdateTask(&updatedTask)
+	}
+	
+	if errors.Is(err, storage.ErrStateNotAllowed) {
+		writeErrorJSON(w, http.StatusBadRequest, "Failed to move task: "+err.Error())
+		return
+	}
+	if errors.Is(err, storage.ErrVersionConflict) {
+		writeErrorJSON(w, http.StatusConflict, "Task has been modified: "+err.Error())
+		return
+	}
+	if errors.Is(err, storage.ErrTaskLimit) {
+		writeErrorJSON(w, http.StatusConflict, "Failed to move task: "+err.Error())
+		return
+	}
+	if errors.Is(err, storage.ErrInvalidID) {
+		writeErrorJSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, storage.ErrListNotFound) {
+		writeErrorJSON(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErrorJSON(w, http.StatusInternalServerError, "Failed to update task: "+err.Error())
+		return
+	}
+	
+	// Return response based on request type
+	handleTaskResponse(w, r, store, &updatedTask)
+}
+
+// writeCreateError reports a failure to create tasks: 409 when a task limit
+// has been reached or the list or task ID is taken, otherwise a server error
+// with message
+func writeCreateError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, storage.ErrTaskLimit) || errors.Is(err, storage.ErrTaskExists) || errors.Is(err, storage.ErrListExists) {
+		writeErrorJSON(w, http.StatusConflict, err.Error())
+		return
+	}
+	writeErrorJSON(w, http.StatusInternalServerError, message)
+}
+
+// storeError returns the HTTP status and message for an error from the
+// store: notFound with 404 for a missing list or task, 400 for a malformed
+// ID, and a server error for anything else, including stored data that can't
+// be parsed. Server errors are logged rather than returned, since they may
+// contain file paths.
+func storeError(err error, notFound string) (int, string) {
+	switch {
+	case errors.Is(err, storage.ErrListNotFound), errors.Is(err, storage.ErrTaskNotFound):
+		return http.StatusNotFound, notFound
+	case errors.Is(err, storage.ErrInvalidID):
+		return http.StatusBadRequest, err.Error()
+	}
+
+	log.Printf("Storage error: %v", err)
+	if errors.Is(err, storage.ErrCorruptData) {
+		return http.StatusInternalServerError, "Stored data is corrupt"
+	}
+	return http.StatusInternalServerError, "Failed to access storage"
+}
+
+// writeStoreError reports a failed store lookup as JSON, see storeError
+func writeStoreError(w http.ResponseWriter, err error, notFound string) {
+	status, message := storeError(err, notFound)
+	writeErrorJSON(w, status, message)
+}
+
+// Helper function to parse task data from either form or JSON
+func parseTaskFormOrJSON(r *http.Request, task *models.Task) error {
+	contentType := r.Header.Get("Content-Type")
+	
+	if strings.Contains(contentType, "application/x-www-form-urlencoded") {
+		err := r.ParseForm()
+		if err != nil {
 			return fmt.Errorf("failed to parse form data")
 		}
 		
@@ -390,32 +928,200 @@ func parseTaskFormOrJSON(r *http.Request, task *models.Task) error {
 			task.ListID = listID
 		}
 		
-		// Handle due date
-		if r.Form.Has("due_date") {
-			dueDateStr := r.FormValue("due_date")
-			if dueDateStr == "clear" || dueDateStr == "" {
-				task.DueDate = nil
-			} else {
-				dueDate, err := time.Parse("2006-01-02", dueDateStr)
-				if err == nil {
-					task.DueDate = &dueDate
-				}
+		if r.Form.Has("blocked_reason") {
+			task.BlockedReason = r.FormValue("blocked_reason")
+		}
+
+		if r.Form.Has("tags") {
+			task.Tags = parseTags(r.FormValue("tags"))
+		}
+
+		if r.Form.Has("assignee") {
+			task.Assignee = strings.TrimSpace(r.FormValue("assignee"))
+		}
+
+		if priority := r.FormValue("priority"); priority != "" {
+			task.Priority = models.TaskPriority(priority)
+		}
+
+		if err := parseEffortForm(r, task); err != nil {
+			return err
+		}
+		if err := parseFlaggedForm(r, task); err != nil {
+			return err
+		}
+
+		if version := r.FormValue("version"); version != "" {
+			v, err := strconv.Atoi(version)
+			if err != nil {
+				return fmt.Errorf("invalid version: %s", version)
 			}
+			task.Version = v
+		}
+		
+		if err := parseDateForm(r, task, time.Now()); err != nil {
+			return err
 		}
 		
 	} else {
-		// For JSON, we completely override with the new data
-		err := decodeBody(r, task)
-		if err != nil {
-			return fmt.Errorf("invalid JSON data: %w", err)
+		// For JSON, fields in the body replace the task's values and fields
+		// left out keep them. An explicit null clears start_date or
+		// due_date, like start_date=clear or due_date=clear in forms.
+		if err := decodeTask(r, task); err != nil {
+			return err
 		}
+		task.Assignee = strings.TrimSpace(task.Assignee)
 	}
 	
 	return nil
 }
 
+// normalizeTags trims tags and drops any that are empty
+func normalizeTags(task *models.Task) {
+	tags := task.Tags[:0]
+	for _, tag := range task.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		tags = nil
+	}
+	task.Tags = tags
+}
+
+// parseTags splits a comma-separated tag list
+func parseTags(value string) []string {
+	return strings.Split(value, ",")
+}
+
+// parseEffortForm reads the estimate_minutes and spent_minutes form fields
+// that are present; an empty value clears the field
+func parseEffortForm(r *http.Request, task *models.Task) error {
+	fields := []struct {
+		name    string
+		minutes *int
+	}{
+		{"estimate_minutes", &task.EstimateMinutes},
+		{"spent_minutes", &task.SpentMinutes},
+	}
+	for _, field := range fields {
+		name, minutes := field.name, field.minutes
+		if !r.Form.Has(name) {
+			continue
+		}
+		value := strings.TrimSpace(r.FormValue(name))
+		if value == "" {
+			*minutes = 0
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("Invalid %s: %s", name, value)
+		}
+		*minutes = n
+	}
+	return nil
+}
+
+// parseDateForm reads the start_date and due_date form fields that are
+// present. Besides 2006-01-02 they accept relative dates such as "tomorrow",
+// "+3d" or "next week", resolved against now; an empty value or "clear"
+// clears the date, and anything else is an error.
+func parseDateForm(r *http.Request, task *models.Task, now time.Time) error {
+	fields := []struct {
+		name string
+		date **time.Time
+	}{
+		{"start_date", &task.StartDate},
+		{"due_date", &task.DueDate},
+	}
+	for _, field := range fields {
+		name, date := field.name, field.date
+		if !r.Form.Has(name) {
+			continue
+		}
+		value := strings.TrimSpace(r.FormValue(name))
+		if value == "" || value == "clear" {
+			*date = nil
+			continue
+		}
+		parsed, err := parseRelativeDate(value, now)
+		if err != nil {
+			return fmt.Errorf("Invalid %s: %s (expected a date such as 2006-01-02 or a relative date such as tomorrow or +3d)", name, value)
+		}
+		*date = &parsed
+	}
+	return nil
+}
+
+// parseFlaggedForm reads the flagged form field if present. An unchecked
+// checkbox sends nothing, so forms pair it with a hidden "false" input and
+// the last value wins.
+func parseFlaggedForm(r *http.Request, task *models.Task) error {
+	values := r.Form["flagged"]
+	if len(values) == 0 {
+		return nil
+	}
+	flagged, err := parseFlagged(values[len(values)-1])
+	if err != nil {
+		return err
+	}
+	task.Flagged = flagged
+	return nil
+}
+
+// parseFlagged parses a flagged form or query value: a boolean, or "on" as
+// sent by checkboxes. An empty value is false.
+func parseFlagged(value string) (bool, error) {
+	switch value {
+	case "":
+		return false, nil
+	case "on":
+		return true, nil
+	}
+	flagged, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("Invalid flagged: %s", value)
+	}
+	return flagged, nil
+}
+
+// validateEffort rejects negative effort estimates and spent time
+func validateEffort(task *models.Task) error {
+	if task.EstimateMinutes < 0 {
+		return fmt.Errorf("Invalid estimate_minutes: %d (must not be negative)", task.EstimateMinutes)
+	}
+	if task.SpentMinutes < 0 {
+		return fmt.Errorf("Invalid spent_minutes: %d (must not be negative)", task.SpentMinutes)
+	}
+	return nil
+}
+
+// validateStartDate rejects a start date later than the due date
+func validateStartDate(task *models.Task) error {
+	if task.StartDate != nil && task.DueDate != nil && task.StartDate.After(*task.DueDate) {
+		return fmt.Errorf("Start date %s is after due date %s", task.StartDate.Format("2006-01-02"), task.DueDate.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// normalizeBlockedReason requires a reason for blocked tasks and clears the
+// reason once a task leaves the blocked state
+func normalizeBlockedReason(task *models.Task) error {
+	task.BlockedReason = strings.TrimSpace(task.BlockedReason)
+	if task.State != models.TaskStateBlocked {
+		task.BlockedReason = ""
+		return nil
+	}
+	if task.BlockedReason == "" {
+		return fmt.Errorf("a blocked reason is required when a task is blocked")
+	}
+	return nil
+}
+
 // Helper function to handle task responses
-func handleTaskResponse(w http.ResponseWriter, r *http.Request, store *storage.FileStore, task *models.Task) {
+func handleTaskResponse(w http.ResponseWriter, r *http.Request, store storage.TaskStore, task *models.Task) {
 	// Handle HTMX requests differently
 	if r.Header.Get("HX-Request") == "true" {
 		tasks, err := store.GetTasksForList(task.ListID)
@@ -423,16 +1129,15 @@ func handleTaskResponse(w http.ResponseWriter, r *http.Request, store *storage.F
 			http.Error(w, "Failed to retrieve tasks", http.StatusInternalServerError)
 			return
 		}
-		html := renderTasksContainer(tasks)
-		writeHTMX(w, http.StatusOK, html)
+		renderTasksContainer(w, tasks)
 	} else {
 		// Regular JSON response
-		writeJSON(w, http.StatusOK, task)
+		writeJSON(w, http.StatusOK, newTaskView(task))
 	}
 }
 
 // HandleDeleteTask deletes a task
-func HandleDeleteTask(store *storage.FileStore) http.HandlerFunc {
+func HandleDeleteTask(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		listID := chi.URLParam(r, "listID")
 		taskID := chi.URLParam(r, "taskID")
@@ -443,7 +1148,7 @@ func HandleDeleteTask(store *storage.FileStore) http.HandlerFunc {
 
 		err := store.DeleteTask(listID, taskID)
 		if err != nil {
-			writeErrorJSON(w, http.StatusNotFound, "Task not found")
+			writeStoreError(w, err, "Task not found")
 			return
 		}
 
@@ -451,624 +1156,582 @@ func HandleDeleteTask(store *storage.FileStore) http.HandlerFunc {
 	}
 }
 
-// Export Handler
+// Note Handlers
 
-// HandleExportMarkdown exports all tasks to markdown
-func HandleExportMarkdown(store *storage.FileStore) http.HandlerFunc {
+// HandleCreateNote adds a note to a task
+func HandleCreateNote(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		lists, err := store.GetAllLists()
-		if err != nil {
-			writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve lists")
+		listID := chi.URLParam(r, "listID")
+		taskID := chi.URLParam(r, "taskID")
+		if listID == "" || taskID == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Missing list ID or task ID")
 			return
 		}
 
-		var buf bytes.Buffer
-		buf.WriteString("# Task Lists\n\n")
-
-		for _, list := range lists {
-			buf.WriteString(fmt.Sprintf("## %s\n\n", list.Name))
-			if list.Description != "" {
-				buf.WriteString(fmt.Sprintf("%s\n\n", list.Description))
-			}
-
-			tasks, err := store.GetTasksForList(list.ID)
-			if err != nil {
-				continue
-			}
+		var note models.Note
+		if err := decodeBody(r, &note); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "Invalid note data")
+			return
+		}
 
-			// Group tasks by state
-			tasksByState := make(map[models.TaskState][]models.Task)
-			for _, task := range tasks {
-				tasksByState[task.State] = append(tasksByState[task.State], task)
-			}
+		if strings.TrimSpace(note.Content) == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Note content is required")
+			return
+		}
 
-			// Write tasks by state
-			for _, state := range []models.TaskState{models.TaskStateTodo, models.TaskStateInProgress, models.TaskStateBlocked, models.TaskStateDone} {
-				stateTasks := tasksByState[state]
-				if len(stateTasks) > 0 {
-					buf.WriteString(fmt.Sprintf("### %s\n\n", stateToTitle(state)))
-					for _, task := range stateTasks {
-						buf.WriteString(fmt.Sprintf("- **%s**", task.Title))
-						if task.Description != "" {
-							buf.WriteString(fmt.Sprintf(": %s", task.Description))
-						}
-						if task.DueDate != nil {
-							buf.WriteString(fmt.Sprintf(" (Due: %s)", task.DueDate.Format("2006-01-02")))
-						}
-						buf.WriteString("\n")
+		task, err := store.GetTask(listID, taskID)
+		if err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
 
-						// Add notes if any
-						if len(task.Notes) > 0 {
-							buf.WriteString("  - Notes:\n")
-							for _, note := range task.Notes {
-								buf.WriteString(fmt.Sprintf("    - %s\n", note.Content))
-							}
-						}
+		// IDs and timestamps are always assigned by the server
+		now := time.Now()
+		note.ID = uuid.New().String()
+		note.CreatedAt = now
+		note.UpdatedAt = now
 
-						// Add subtasks if any
-						if len(task.SubTasks) > 0 {
-							buf.WriteString("  - Subtasks:\n")
-							for _, subtask := range task.SubTasks {
-								buf.WriteString(fmt.Sprintf("    - **%s**", subtask.Title))
-								if subtask.Description != "" {
-									buf.WriteString(fmt.Sprintf(": %s", subtask.Description))
-								}
-								buf.WriteString("\n")
-							}
-						}
-					}
-					buf.WriteString("\n")
-				}
-			}
+		task.Notes = append(task.Notes, note)
+		if err := store.UpdateTask(task); err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to save note")
+			return
 		}
 
-		w.Header().Set("Content-Type", "text/markdown")
-		w.Header().Set("Content-Disposition", "attachment; filename=tasks.md")
-		w.WriteHeader(http.StatusOK)
-		w.Write(buf.Bytes())
+		writeJSON(w, http.StatusCreated, note)
 	}
 }
 
-// Helper to convert state to a title
-func stateToTitle(state models.TaskState) string {
-	switch state {
-	case models.TaskStateTodo:
-		return "To Do"
-	case models.TaskStateInProgress:
-		return "In Progress"
-	case models.TaskStateBlocked:
-		return "Blocked"
-	case models.TaskStateDone:
-		return "Done"
-	default:
-		return string(state)
-	}
-}
+// HandleUpdateNote replaces the content of a task note
+func HandleUpdateNote(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		taskID := chi.URLParam(r, "taskID")
+		noteID := chi.URLParam(r, "noteID")
+		if listID == "" || taskID == "" || noteID == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Missing list ID, task ID or note ID")
+			return
+		}
 
-// UI handlers
+		var update models.Note
+		if err := decodeBody(r, &update); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "Invalid note data")
+			return
+		}
 
-// HandleHomeUI renders the home page with all tasks
-func HandleHomeUI(store *storage.FileStore) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		tasks, err := store.GetAllTasks()
-		if err != nil {
-			http.Error(w, "Error loading tasks", http.StatusInternalServerError)
+		if strings.TrimSpace(update.Content) == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Note content is required")
 			return
 		}
 
-		lists, err := store.GetAllLists()
+		task, err := store.GetTask(listID, taskID)
 		if err != nil {
-			http.Error(w, "Error loading lists", http.StatusInternalServerError)
+			writeStoreError(w, err, "Task not found")
 			return
 		}
 
-		// Create list filter HTML with checkboxes
-		var listSelectorHTML bytes.Buffer
-		listSelectorHTML.WriteString("<div class=\"list-filter\">")
-		listSelectorHTML.WriteString("<h3>Filter by List:</h3>")
-		listSelectorHTML.WriteString("<form id=\"list-filter-form\">")
-		listSelectorHTML.WriteString("<div class=\"checkbox-group\">")
-		listSelectorHTML.WriteString("<label class=\"filter-label\"><input type=\"checkbox\" value=\"all\" checked data-filter-all><span>All Lists</span></label>")
-		for _, list := range lists {
-			listSelectorHTML.WriteString(fmt.Sprintf("<label class=\"filter-label\"><input type=\"checkbox\" name=\"list\" value=\"%s\" data-list-id=\"%s\"><span>%s</span></label>", 
-				list.ID, list.ID, list.Name))
-		}
-		listSelectorHTML.WriteString("</div>")
-		listSelectorHTML.WriteString("</form>")
-		listSelectorHTML.WriteString("</div>")
-
-		// In a real app, this would use a template engine
-		html := fmt.Sprintf(`
-			<!DOCTYPE html>
-			<html>
-				<head>
-					<title>Task Manager</title>
-					<meta charset="UTF-8">
-					<meta name="viewport" content="width=device-width, initial-scale=1.0">
-					<link rel="icon" href="/static/img/favicon.ico" type="image/x-icon">
-					<script src="https://unpkg.com/htmx.org@1.9.2"></script>
-					<link rel="stylesheet" href="/static/style.css">
-					<script src="/static/app.js" defer></script>
-				</head>
-				<body>
-					<header>
-						<h1>Task Manager</h1>
-						<nav>
-							<a href="/">All Tasks</a>
-							<a href="/lists">Task Lists</a>
-							<a href="/all-kanban">Kanban View</a>
-							<a href="/api/openapi" target="_blank">API Docs</a>
-						</nav>
-					</header>
-					<main>
-						<h2>All Tasks</h2>
-						<div class="filter-container">
-							%s
-						</div>
-						<div class="tasks-container">
-							%s
-						</div>
-					</main>
-				</body>
-			</html>
-		`, listSelectorHTML.String(), renderAllTasksHTML(tasks, lists))
-
-		writeHTMX(w, http.StatusOK, html)
-	}
-}
+		for i := range task.Notes {
+			if task.Notes[i].ID != noteID {
+				continue
+			}
 
-// HandleListsUI renders the lists page
-func HandleListsUI(store *storage.FileStore) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		lists, err := store.GetAllLists()
-		if err != nil {
-			http.Error(w, "Error loading lists", http.StatusInternalServerError)
+			task.Notes[i].Content = update.Content
+			task.Notes[i].UpdatedAt = time.Now()
+			if err := store.UpdateTask(task); err != nil {
+				writeErrorJSON(w, http.StatusInternalServerError, "Failed to save note")
+				return
+			}
+
+			writeJSON(w, http.StatusOK, task.Notes[i])
 			return
 		}
 
-		// In a real app, this would use a template engine
-		html := fmt.Sprintf(`
-			<!DOCTYPE html>
-			<html>
-				<head>
-					<title>Task Lists</title>
-					<meta charset="UTF-8">
-					<meta name="viewport" content="width=device-width, initial-scale=1.0">
-					<link rel="icon" href="/static/img/favicon.ico" type="image/x-icon">
-					<script src="https://unpkg.com/htmx.org@1.9.2"></script>
-					<link rel="stylesheet" href="/static/style.css">
-					<script src="/static/app.js" defer></script>
-				</head>
-				<body>
-					<header>
-						<h1>Task Manager</h1>
-						<nav>
-							<a href="/">All Tasks</a>
-							<a href="/lists">Task Lists</a>
-							<a href="/all-kanban">Kanban View</a>
-							<a href="/api/openapi" target="_blank">API Docs</a>
-						</nav>
-					</header>
-					<main>
-						<h2>Task Lists</h2>
-						<div class="lists-container">
-							%s
-						</div>
-						<div class="new-list-form">
-							<h3>Create New List</h3>
-							<form hx-post="/api/lists" hx-target=".lists-container" hx-swap="innerHTML" enctype="application/x-www-form-urlencoded">
-								<div>
-									<label for="name">Name:</label>
-									<input type="text" id="name" name="name" required>
-								</div>
-								<div>
-									<label for="description">Description:</label>
-									<textarea id="description" name="description"></textarea>
-								</div>
-								<button type="submit">Create List</button>
-							</form>
-						</div>
-					</main>
-				</body>
-			</html>
-		`, renderListsHTML(lists))
-
-		writeHTMX(w, http.StatusOK, html)
+		writeErrorJSON(w, http.StatusNotFound, "Note not found")
 	}
 }
 
-// HandleListUI renders a single list with its tasks
-func HandleListUI(store *storage.FileStore) http.HandlerFunc {
+// HandleDeleteNote removes a note from a task
+func HandleDeleteNote(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		listID := chi.URLParam(r, "listID")
-		if listID == "" {
-			http.Error(w, "Missing list ID", http.StatusBadRequest)
+		taskID := chi.URLParam(r, "taskID")
+		noteID := chi.URLParam(r, "noteID")
+		if listID == "" || taskID == "" || noteID == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Missing list ID, task ID or note ID")
 			return
 		}
 
-		list, err := store.GetList(listID)
+		task, err := store.GetTask(listID, taskID)
 		if err != nil {
-			http.Error(w, "List not found", http.StatusNotFound)
+			writeStoreError(w, err, "Task not found")
 			return
 		}
 
-		tasks, err := store.GetTasksForList(listID)
-		if err != nil {
-			http.Error(w, "Error loading tasks", http.StatusInternalServerError)
+		for i := range task.Notes {
+			if task.Notes[i].ID != noteID {
+				continue
+			}
+
+			task.Notes = append(task.Notes[:i], task.Notes[i+1:]...)
+			if err := store.UpdateTask(task); err != nil {
+				writeErrorJSON(w, http.StatusInternalServerError, "Failed to delete note")
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
-		// In a real app, this would use a template engine
-		html := fmt.Sprintf(`
-			<!DOCTYPE html>
-			<html>
-				<head>
-					<title>%s</title>
-					<meta charset="UTF-8">
-					<meta name="viewport" content="width=device-width, initial-scale=1.0">
-					<link rel="icon" href="/static/img/favicon.ico" type="image/x-icon">
-					<script src="https://unpkg.com/htmx.org@1.9.2"></script>
-					<link rel="stylesheet" href="/static/style.css">
-					<script src="/static/app.js" defer></script>
-				</head>
-				<body>
-					<header>
-						<h1>Task Manager</h1>
-						<nav>
-							<a href="/">All Tasks</a>
-							<a href="/lists">Task Lists</a>
-							<a href="/kanban/%s">Kanban View</a>
-							<a href="/api/openapi" target="_blank">API Docs</a>
-						</nav>
-					</header>
-					<main>
-						<h2>%s</h2>
-						<p>%s</p>
-						<div class="tasks-container">
-							%s
-						</div>
-						<div class="new-task-form">
-							<h3>Create New Task</h3>
-							<form hx-post="/api/lists/%s/tasks" hx-target=".tasks-container" hx-swap="outerHTML" enctype="application/x-www-form-urlencoded">
-								<div>
-									<label for="title">Title:</label>
-									<input type="text" id="title" name="title" required>
-								</div>
-								<div>
-									<label for="description">Description:</label>
-									<textarea id="description" name="description"></textarea>
-								</div>
-								<div>
-									<label for="state">State:</label>
-									<select id="state" name="state">
-										<option value="todo">Todo</option>
-										<option value="in_progress">In Progress</option>
-										<option value="blocked">Blocked</option>
-										<option value="done">Done</option>
-									</select>
-								</div>
-								<div>
-									<label for="due_date">Due Date:</label>
-									<input type="date" id="due_date" name="due_date">
-								</div>
-								<button type="submit">Create Task</button>
-							</form>
-						</div>
-					</main>
-
-					<!-- Task edit modal -->
-					<div id="task-edit-modal" class="modal">
-						<div class="modal-content">
-							<span class="close">&times;</span>
-							<h2>Edit Task</h2>
-							<form id="edit-task-form" enctype="application/x-www-form-urlencoded">
-								<input type="hidden" id="edit-task-id" name="id">
-								<div>
-									<label for="edit-title">Title:</label>
-									<input type="text" id="edit-title" name="title" required>
-								</div>
-								<div>
-									<label for="edit-description">Description:</label>
-									<textarea id="edit-description" name="description"></textarea>
-								</div>
-								<div>
-									<label for="edit-state">State:</label>
-									<select id="edit-state" name="state">
-										<option value="todo">Todo</option>
-										<option value="in_progress">In Progress</option>
-										<option value="blocked">Blocked</option>
-										<option value="done">Done</option>
-									</select>
-								</div>
-								<div>
-									<label for="edit-due-date">Due Date:</label>
-									<input type="date" id="edit-due-date" name="due_date">
-									<button type="button" id="clear-due-date">Clear</button>
-								</div>
-								<div>
-									<label for="edit-list-id">List:</label>
-									<select id="edit-list-id" name="list_id">
-										<!-- Will be populated by JavaScript -->
-									</select>
-								</div>
-								<button type="submit">Update Task</button>
-							</form>
-						</div>
-					</div>
-				</body>
-			</html>
-		`, list.Name, listID, list.Name, list.Description, renderTasksHTML(tasks), listID)
-
-		writeHTMX(w, http.StatusOK, html)
+		writeErrorJSON(w, http.StatusNotFound, "Note not found")
 	}
 }
 
-// HandleKanbanUI renders a kanban view of a list's tasks
-func HandleKanbanUI(store *storage.FileStore) http.HandlerFunc {
+// Subtask Handlers
+
+// HandleAddSubTask adds a subtask to a task
+func HandleAddSubTask(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		listID := chi.URLParam(r, "listID")
-		if listID == "" {
-			http.Error(w, "Missing list ID", http.StatusBadRequest)
+		taskID := chi.URLParam(r, "taskID")
+		if listID == "" || taskID == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Missing list ID or task ID")
 			return
 		}
 
-		list, err := store.GetList(listID)
-		if err != nil {
-			http.Error(w, "List not found", http.StatusNotFound)
+		var subTask models.Task
+		if err := decodeBody(r, &subTask); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, "Invalid subtask data")
 			return
 		}
 
-		tasks, err := store.GetTasksForList(listID)
-		if err != nil {
-			http.Error(w, "Error loading tasks", http.StatusInternalServerError)
+		if strings.TrimSpace(subTask.Title) == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Subtask title is required")
 			return
 		}
 
-		// Group tasks by state
-		tasksByState := make(map[models.TaskState][]models.Task)
-		for _, task := range tasks {
-			tasksByState[task.State] = append(tasksByState[task.State], task)
-		}
-
-		// In a real app, this would use a template engine
-		html := fmt.Sprintf(`
-			<!DOCTYPE html>
-			<html>
-				<head>
-					<title>Kanban - %s</title>
-					<meta charset="UTF-8">
-					<meta name="viewport" content="width=device-width, initial-scale=1.0">
-					<link rel="icon" href="/static/img/favicon.ico" type="image/x-icon">
-					<script src="https://unpkg.com/htmx.org@1.9.2"></script>
-					<link rel="stylesheet" href="/static/style.css">
-					<script src="/static/app.js" defer></script>
-				</head>
-				<body>
-					<header>
-						<h1>Task Manager</h1>
-						<nav>
-							<a href="/">All Tasks</a>
-							<a href="/lists">Task Lists</a>
-							<a href="/all-kanban">All Kanban</a>
-							<a href="/lists/%s">List View</a>
-							<a href="/api/openapi" target="_blank">API Docs</a>
-						</nav>
-					</header>
-					<main>
-						<h2>Kanban Board - %s</h2>
-						<div class="kanban-board">
-							<div class="kanban-column">
-								<h3>Todo</h3>
-								<div class="kanban-tasks">
-									%s
-								</div>
-							</div>
-							<div class="kanban-column">
-								<h3>In Progress</h3>
-								<div class="kanban-tasks">
-									%s
-								</div>
-							</div>
-							<div class="kanban-column">
-								<h3>Blocked</h3>
-								<div class="kanban-tasks">
-									%s
-								</div>
-							</div>
-							<div class="kanban-column">
-								<h3>Done</h3>
-								<div class="kanban-tasks">
-									%s
-								</div>
-							</div>
-						</div>
-					</main>
-
-					<!-- Task edit modal -->
-					<div id="task-edit-modal" class="modal">
-						<div class="modal-content">
-							<span class="close">&times;</span>
-							<h2>Edit Task</h2>
-							<form id="edit-task-form" enctype="application/x-www-form-urlencoded">
-								<input type="hidden" id="edit-task-id" name="id">
-								<div>
-									<label for="edit-title">Title:</label>
-									<input type="text" id="edit-title" name="title" required>
-								</div>
-								<div>
-									<label for="edit-description">Description:</label>
-									<textarea id="edit-description" name="description"></textarea>
-								</div>
-								<div>
-									<label for="edit-state">State:</label>
-									<select id="edit-state" name="state">
-										<option value="todo">Todo</option>
-										<option value="in_progress">In Progress</option>
-										<option value="blocked">Blocked</option>
-										<option value="done">Done</option>
-									</select>
-								</div>
-								<div>
-									<label for="edit-due-date">Due Date:</label>
-									<input type="date" id="edit-due-date" name="due_date">
-									<button type="button" id="clear-due-date">Clear</button>
-								</div>
-								<div>
-									<label for="edit-list-id">List:</label>
-									<select id="edit-list-id" name="list_id">
-										<!-- Will be populated by JavaScript -->
-									</select>
-								</div>
-								<button type="submit">Update Task</button>
-							</form>
-						</div>
-					</div>
-				</body>
-			</html>
-		`, list.Name, listID, list.Name, 
-			renderKanbanTasksHTML(tasksByState[models.TaskStateTodo]), 
-			renderKanbanTasksHTML(tasksByState[models.TaskStateInProgress]),
-			renderKanbanTasksHTML(tasksByState[models.TaskStateBlocked]),
-			renderKanbanTasksHTML(tasksByState[models.TaskStateDone]))
-
-		writeHTMX(w, http.StatusOK, html)
-	}
-}
-
-// HTML rendering helpers
+		if subTask.State == "" {
+			subTask.State = models.TaskStateTodo
+		}
+		normalizeTags(&subTask)
+		if err := normalizeBlockedReason(&subTask); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		subTask.ListID = listID
+		if err := validateTaskState(store, &subTask); err != nil {
+			writeErrorJSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
 
-// renderAllTasksHTML renders all tasks with list names
-func renderAllTasksHTML(tasks []models.Task, lists []models.TaskList) string {
-	if len(tasks) == 0 {
-		return "<p>No tasks found</p>"
-	}
+		task, err := store.GetTask(listID, taskID)
+		if err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
 
-	// Create a map of list IDs to names
-	listNames := make(map[string]string)
-	for _, list := range lists {
-		listNames[list.ID] = list.Name
-	}
+		now := time.Now()
+		subTask.ID = uuid.New().String()
+		subTask.ParentID = ""
+		subTask.CreatedAt = now
+		subTask.UpdatedAt = now
+		subTask.StateTime = now
 
-	var buf bytes.Buffer
-	buf.WriteString("<div class=\"tasks\">")
-	for _, task := range tasks {
-		listName := listNames[task.ListID]
-		buf.WriteString(fmt.Sprintf(`
-			<div class="task task-state-%s" data-task-id="%s" data-list-id="%s">
-				<div class="task-header">
-					<h3>%s</h3>
-					<span class="task-list">%s</span>
-				</div>
-				<div class="task-body">
-					<p>%s</p>
-					<div class="task-meta">
-						<span class="task-state">%s</span>
-						%s
-					</div>
-				</div>
-			</div>
-		`, task.State, task.ID, task.ListID, task.Title, listName, task.Description, stateToTitle(task.State), renderDueDate(task.DueDate)))
-	}
-	buf.WriteString("</div>")
-	return buf.String()
-}
+		task.SubTasks = append(task.SubTasks, subTask)
+		task.UpdatedAt = now
+		if err := store.UpdateTask(task); err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to save subtask")
+			return
+		}
 
-// renderTasksHTML renders tasks for a specific list
-func renderTasksHTML(tasks []models.Task) string {
-	if len(tasks) == 0 {
-		return "<p>No tasks found</p>"
+		writeJSON(w, http.StatusCreated, subTask)
 	}
-
-	var buf bytes.Buffer
-	buf.WriteString("<div class=\"tasks\">")
-	for _, task := range tasks {
-		buf.WriteString(fmt.Sprintf(`
-			<div class="task task-state-%s" data-task-id="%s" data-list-id="%s">
-				<div class="task-header">
-					<h3>%s</h3>
-				</div>
-				<div class="task-body">
-					<p>%s</p>
-					<div class="task-meta">
-						<span class="task-state">%s</span>
-						%s
-					</div>
-				</div>
-			</div>
-		`, task.State, task.ID, task.ListID, task.Title, task.Description, stateToTitle(task.State), renderDueDate(task.DueDate)))
-	}
-	buf.WriteString("</div>")
-	return buf.String()
-}
-
-// renderTasksContainer wraps the tasks HTML in a container
-func renderTasksContainer(tasks []models.Task) string {
-	return fmt.Sprintf(`
-		<div class="tasks-container">
-			%s
-		</div>
-	`, renderTasksHTML(tasks))
 }
 
-// renderKanbanTasksHTML renders tasks for a kanban column
-func renderKanbanTasksHTML(tasks []models.Task) string {
-	if len(tasks) == 0 {
-		return "<p class=\"empty-column\">No tasks</p>"
-	}
+// HandleUpdateSubTask updates a subtask of a task
+func HandleUpdateSubTask(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		taskID := chi.URLParam(r, "taskID")
+		subTaskID := chi.URLParam(r, "subTaskID")
+		if listID == "" || taskID == "" || subTaskID == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Missing list ID, task ID or subtask ID")
+			return
+		}
 
-	var buf bytes.Buffer
-	for _, task := range tasks {
-		buf.WriteString(fmt.Sprintf(`
-			<div class="kanban-task" data-task-id="%s" data-list-id="%s">
-				<h4>%s</h4>
-				<p>%s</p>
-				<div class="task-meta">
-					%s
-				</div>
-			</div>
-		`, task.ID, task.ListID, task.Title, task.Description, renderDueDate(task.DueDate)))
-	}
-	return buf.String()
+		task, err := store.GetTask(listID, taskID)
+		if err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
+
+		for i := range task.SubTasks {
+			existing := task.SubTasks[i]
+			if existing.ID != subTaskID {
+				continue
+			}
+
+			// Start from the existing subtask so omitted fields are kept
+			updated := existing
+			if err := decodeBody(r, &updated); err != nil {
+				writeErrorJSON(w, http.StatusBadRequest, "Invalid subtask data")
+				return
+			}
+
+			if strings.TrimSpace(updated.Title) == "" {
+				writeErrorJSON(w, http.StatusBadRequest, "Subtask title is required")
+				return
+			}
+			normalizeTags(&updated)
+			if err := normalizeBlockedReason(&updated); err != nil {
+				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			updated.ListID = listID
+			if err := validateTaskState(store, &updated); err != nil {
+				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			now := time.Now()
+			updated.ID = existing.ID
+			updated.CreatedAt = existing.CreatedAt
+			updated.UpdatedAt = now
+			if updated.State != existing.State {
+				updated.StateTime = now
+			}
+
+			task.SubTasks[i] = updated
+			task.UpdatedAt = now
+			autoCompleteParent(store, task)
+			if err := store.UpdateTask(task); err != nil {
+				writeErrorJSON(w, http.StatusInternalServerError, "Failed to save subtask")
+				return
+			}
+
+			writeJSON(w, http.StatusOK, updated)
+			return
+		}
+
+		writeErrorJSON(w, http.StatusNotFound, "Subtask not found")
+	}
+}
+
+// HandleDeleteSubTask removes a subtask from a task
+func HandleDeleteSubTask(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		taskID := chi.URLParam(r, "taskID")
+		subTaskID := chi.URLParam(r, "subTaskID")
+		if listID == "" || taskID == "" || subTaskID == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Missing list ID, task ID or subtask ID")
+			return
+		}
+
+		task, err := store.GetTask(listID, taskID)
+		if err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
+
+		for i := range task.SubTasks {
+			if task.SubTasks[i].ID != subTaskID {
+				continue
+			}
+
+			task.SubTasks = append(task.SubTasks[:i], task.SubTasks[i+1:]...)
+			task.UpdatedAt = time.Now()
+			autoCompleteParent(store, task)
+			if err := store.UpdateTask(task); err != nil {
+				writeErrorJSON(w, http.StatusInternalServerError, "Failed to delete subtask")
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		writeErrorJSON(w, http.StatusNotFound, "Subtask not found")
+	}
+}
+
+// Export Handler
+
+// writeMarkdownExport writes all tasks as markdown, one section per list
+func writeMarkdownExport(buf *bytes.Buffer, lists []models.TaskList, store storage.TaskStore) error {
+	buf.WriteString("# Task Lists\n\n")
+
+	for _, list := range lists {
+		tasks, err := store.GetTasksForList(list.ID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve tasks for list %s: %w", list.ID, err)
+		}
+		writeListMarkdown(buf, list, tasks)
+	}
+	return nil
 }
 
-// renderListsHTML renders all lists
-func renderListsHTML(lists []models.TaskList) string {
-	if len(lists) == 0 {
-		return "<p>No lists found</p>"
+// HandleExportListMarkdown exports a single list's tasks to markdown, in the
+// same format as the markdown export of all lists
+func HandleExportListMarkdown(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list, err := store.GetList(chi.URLParam(r, "listID"))
+		if err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+		tasks, err := store.GetTasksForList(list.ID)
+		if err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		var buf bytes.Buffer
+		writeListMarkdown(&buf, *list, tasks)
+
+		w.Header().Set("Content-Type", "text/markdown")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": list.Name + ".md"}))
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+	}
+}
+
+// writeListMarkdown writes a list and its tasks, grouped by state, as a
+// section of the markdown export
+func writeListMarkdown(buf *bytes.Buffer, list models.TaskList, tasks []models.Task) {
+	buf.WriteString(fmt.Sprintf("## %s\n\n", list.Name))
+	if list.Description != "" {
+		buf.WriteString(fmt.Sprintf("%s\n\n", list.Description))
+	}
+
+	// Group tasks by state
+	tasksByState := make(map[models.TaskState][]models.Task)
+	for _, task := range tasks {
+		tasksByState[task.State] = append(tasksByState[task.State], task)
+	}
+
+	// Write tasks by state
+	for _, state := range list.TaskStates() {
+		stateTasks := tasksByState[state]
+		if len(stateTasks) > 0 {
+			buf.WriteString(fmt.Sprintf("### %s\n\n", stateToTitle(state)))
+			for _, task := range stateTasks {
+				buf.WriteString(fmt.Sprintf("- **%s**", task.Title))
+				if task.Description != "" {
+					buf.WriteString(fmt.Sprintf(": %s", task.Description))
+				}
+				if task.DueDate != nil {
+					buf.WriteString(fmt.Sprintf(" (Due: %s)", task.DueDate.Format("2006-01-02")))
+				}
+				buf.WriteString("\n")
+
+				if task.BlockedReason != "" {
+					buf.WriteString(fmt.Sprintf("  - Blocked: %s\n", task.BlockedReason))
+				}
+
+				// Add notes if any
+				if len(task.Notes) > 0 {
+					buf.WriteString("  - Notes:\n")
+					for _, note := range task.Notes {
+						buf.WriteString(fmt.Sprintf("    - %s\n", note.Content))
+					}
+				}
+
+				// Add comments if any
+				if len(task.Comments) > 0 {
+					buf.WriteString("  - Comments:\n")
+					for _, comment := range task.Comments {
+						buf.WriteString(fmt.Sprintf("    - %s (%s): %s\n", comment.Author, comment.CreatedAt.Format("2006-01-02 15:04"), comment.Body))
+					}
+				}
+
+				// Add subtasks if any
+				if len(task.SubTasks) > 0 {
+					buf.WriteString("  - Subtasks:\n")
+					for _, subtask := range task.SubTasks {
+						buf.WriteString(fmt.Sprintf("    - **%s**", subtask.Title))
+						if subtask.Description != "" {
+							buf.WriteString(fmt.Sprintf(": %s", subtask.Description))
+						}
+						buf.WriteString("\n")
+					}
+				}
+			}
+			buf.WriteString("\n")
+		}
 	}
+}
+
+// writeCSVExport writes all tasks as CSV, one row per task
+func writeCSVExport(buf *bytes.Buffer, lists []models.TaskList, store storage.TaskStore) error {
+	writer := csv.NewWriter(buf)
+	writer.Write([]string{"list", "title", "description", "state", "due_date", "created_at", "updated_at", "tags"})
 
-	var buf bytes.Buffer
-	buf.WriteString("<div class=\"lists\">")
 	for _, list := range lists {
-		buf.WriteString(fmt.Sprintf(`
-			<div class="list">
-				<div class="list-header">
-					<h3><a href="/lists/%s">%s</a></h3>
-				</div>
-				<div class="list-body">
-					<p>%s</p>
-					<div class="list-actions">
-						<a href="/lists/%s" class="button">View Tasks</a>
-						<a href="/kanban/%s" class="button">Kanban View</a>
-					</div>
-				</div>
-			</div>
-		`, list.ID, list.Name, list.Description, list.ID, list.ID))
-	}
-	buf.WriteString("</div>")
-	return buf.String()
+		tasks, err := store.GetTasksForList(list.ID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve tasks for list %s: %w", list.ID, err)
+		}
+
+		for _, task := range tasks {
+			dueDate := ""
+			if task.DueDate != nil {
+				dueDate = task.DueDate.Format("2006-01-02")
+			}
+
+			writer.Write([]string{
+				list.Name,
+				task.Title,
+				task.Description,
+				string(task.State),
+				dueDate,
+				task.CreatedAt.Format(time.RFC3339),
+				task.UpdatedAt.Format(time.RFC3339),
+				strings.Join(task.Tags, ", "),
+			})
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Helper to convert state to a title
+func stateToTitle(state models.TaskState) string {
+	switch state {
+	case models.TaskStateTodo:
+		return "To Do"
+	case models.TaskStateInProgress:
+		return "In Progress"
+	case models.TaskStateBlocked:
+		return "Blocked"
+	case models.TaskStateDone:
+		return "Done"
+	default:
+		return string(state)
+	}
+}
+
+// UI handlers
+
+// HandleHomeUI renders the home page with all tasks
+func HandleHomeUI(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tasks, err := store.GetAllTasks()
+		if err != nil {
+			http.Error(w, "Error loading tasks", http.StatusInternalServerError)
+			return
+		}
+		tasks = activeTasks(tasks)
+
+		lists, err := store.GetAllLists()
+		if err != nil {
+			http.Error(w, "Error loading lists", http.StatusInternalServerError)
+			return
+		}
+
+		renderTemplate(w, http.StatusOK, "home", pageData{
+			Title:     "Task Manager",
+			Nav:       pageNav(nil, false),
+			Lists:     lists,
+			Tasks:     tasks,
+			ListNames: listNames(lists),
+		})
+	}
+}
+
+// HandleListsUI renders the lists page
+func HandleListsUI(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lists, err := store.GetAllLists()
+		if err != nil {
+			http.Error(w, "Error loading lists", http.StatusInternalServerError)
+			return
+		}
+
+		renderTemplate(w, http.StatusOK, "lists-page", pageData{
+			Title: "Task Lists",
+			Nav:   pageNav(nil, false),
+			Lists: activeLists(lists),
+		})
+	}
+}
+
+// HandleListUI renders a single list with its tasks
+func HandleListUI(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		if listID == "" {
+			http.Error(w, "Missing list ID", http.StatusBadRequest)
+			return
+		}
+
+		list, err := store.GetList(listID)
+		if err != nil {
+			status, message := storeError(err, "List not found")
+			http.Error(w, message, status)
+			return
+		}
+
+		tasks, err := store.GetTasksForList(listID)
+		if err != nil {
+			status, message := storeError(err, "List not found")
+			http.Error(w, message, status)
+			return
+		}
+		tasks = activeTasks(tasks)
+
+		renderTemplate(w, http.StatusOK, "list", pageData{
+			Title:  list.Name,
+			Nav:    pageNav(list, false),
+			List:   list,
+			Tasks:  tasks,
+			States: list.TaskStates(),
+		})
+	}
 }
 
-// renderDueDate formats a due date or returns empty string
-func renderDueDate(dueDate *time.Time) string {
-	if dueDate == nil {
-		return ""
+// HandleKanbanUI renders a kanban view of a list's tasks
+func HandleKanbanUI(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		if listID == "" {
+			http.Error(w, "Missing list ID", http.StatusBadRequest)
+			return
+		}
+
+		list, err := store.GetList(listID)
+		if err != nil {
+			status, message := storeError(err, "List not found")
+			http.Error(w, message, status)
+			return
+		}
+
+		tasks, err := store.GetTasksForList(listID)
+		if err != nil {
+			status, message := storeError(err, "List not found")
+			http.Error(w, message, status)
+			return
+		}
+		tasks = activeTasks(tasks)
+
+		renderTemplate(w, http.StatusOK, "kanban", pageData{
+			Title:   list.Name,
+			Nav:     pageNav(list, true),
+			List:    list,
+			Columns: kanbanColumns(list.TaskStates(), tasks),
+			States:  list.TaskStates(),
+		})
 	}
-	return fmt.Sprintf("<span class=\"task-due-date\">Due: %s</span>", dueDate.Format("2006-01-02"))
+}
+
+// renderTasksContainer renders the tasks container swapped in by HTMX,
+// leaving out archived tasks
+func renderTasksContainer(w http.ResponseWriter, tasks []models.Task) {
+	renderTemplate(w, http.StatusOK, "tasks-container", activeTasks(tasks))
 }
 
 // HandleAllKanbanUI renders a kanban view of all tasks across all lists
-func HandleAllKanbanUI(store *storage.FileStore) http.HandlerFunc {
+func HandleAllKanbanUI(store storage.TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get all lists for the header links
 		lists, err := store.GetAllLists()
@@ -1083,136 +1746,27 @@ func HandleAllKanbanUI(store *storage.FileStore) http.HandlerFunc {
 			http.Error(w, "Error loading tasks", http.StatusInternalServerError)
 			return
 		}
+		tasks = activeTasks(tasks)
 
-		// Get list names for task display
-		listNames := make(map[string]string)
-		for _, list := range lists {
-			listNames[list.ID] = list.Name
-		}
-		
-		// Group tasks by state
-		tasksByState := make(map[models.TaskState][]models.Task)
-		for _, task := range tasks {
-			tasksByState[task.State] = append(tasksByState[task.State], task)
-		}
-
-		// Create list filter HTML with checkboxes
-		var listSelectorHTML bytes.Buffer
-		listSelectorHTML.WriteString("<div class=\"list-filter\">")
-		listSelectorHTML.WriteString("<h3>Filter by List:</h3>")
-		listSelectorHTML.WriteString("<form id=\"list-filter-form\">")
-		listSelectorHTML.WriteString("<div class=\"checkbox-group\">")
-		listSelectorHTML.WriteString("<label class=\"filter-label\"><input type=\"checkbox\" value=\"all\" checked data-filter-all><span>All Lists</span></label>")
-		for _, list := range lists {
-			listSelectorHTML.WriteString(fmt.Sprintf("<label class=\"filter-label\"><input type=\"checkbox\" name=\"list\" value=\"%s\" data-list-id=\"%s\"><span>%s</span></label>", 
-				list.ID, list.ID, list.Name))
-		}
-		listSelectorHTML.WriteString("</div>")
-		listSelectorHTML.WriteString("</form>")
-		listSelectorHTML.WriteString("</div>")
-
-		// In a real app, this would use a template engine
-		html := fmt.Sprintf(`
-			<!DOCTYPE html>
-			<html>
-				<head>
-					<title>Kanban - All Tasks</title>
-					<meta charset="UTF-8">
-					<meta name="viewport" content="width=device-width, initial-scale=1.0">
-					<link rel="icon" href="/static/img/favicon.ico" type="image/x-icon">
-					<script src="https://unpkg.com/htmx.org@1.9.2"></script>
-					<link rel="stylesheet" href="/static/style.css">
-					<script src="/static/app.js" defer></script>
-				</head>
-				<body>
-					<header>
-						<h1>Task Manager</h1>
-						<nav>
-							<a href="/">All Tasks</a>
-							<a href="/lists">Task Lists</a>
-							<a href="/all-kanban">Kanban View</a>
-							<a href="/api/openapi" target="_blank">API Docs</a>
-						</nav>
-					</header>
-					<main>
-						<h2>Kanban Board - All Tasks</h2>
-						%s
-						<div class="kanban-board">
-							<div class="kanban-column">
-								<h3>Todo</h3>
-								<div class="kanban-tasks">
-									%s
-								</div>
-							</div>
-							<div class="kanban-column">
-								<h3>In Progress</h3>
-								<div class="kanban-tasks">
-									%s
-								</div>
-							</div>
-							<div class="kanban-column">
-								<h3>Blocked</h3>
-								<div class="kanban-tasks">
-									%s
-								</div>
-							</div>
-							<div class="kanban-column">
-								<h3>Done</h3>
-								<div class="kanban-tasks">
-									%s
-								</div>
-							</div>
-						</div>
-					</main>
-
-					<!-- Task edit modal -->
-					<div id="task-edit-modal" class="modal">
-						<div class="modal-content">
-							<span class="close">&times;</span>
-							<h2>Edit Task</h2>
-							<form id="edit-task-form" enctype="application/x-www-form-urlencoded">
-								<input type="hidden" id="edit-task-id" name="id">
-								<div>
-									<label for="edit-title">Title:</label>
-									<input type="text" id="edit-title" name="title" required>
-								</div>
-								<div>
-									<label for="edit-description">Description:</label>
-									<textarea id="edit-description" name="description"></textarea>
-								</div>
-								<div>
-									<label for="edit-state">State:</label>
-									<select id="edit-state" name="state">
-										<option value="todo">Todo</option>
-										<option value="in_progress">In Progress</option>
-										<option value="blocked">Blocked</option>
-										<option value="done">Done</option>
-									</select>
-								</div>
-								<div>
-									<label for="edit-due-date">Due Date:</label>
-									<input type="date" id="edit-due-date" name="due_date">
-									<button type="button" id="clear-due-date">Clear</button>
-								</div>
-								<div>
-									<label for="edit-list-id">List:</label>
-									<select id="edit-list-id" name="list_id">
-										<!-- Will be populated by JavaScript -->
-									</select>
-								</div>
-								<button type="submit">Update Task</button>
-							</form>
-						</div>
-					</div>
-				</body>
-			</html>
-		`, listSelectorHTML.String(),
-			renderKanbanTasksHTML(tasksByState[models.TaskStateTodo]), 
-			renderKanbanTasksHTML(tasksByState[models.TaskStateInProgress]),
-			renderKanbanTasksHTML(tasksByState[models.TaskStateBlocked]),
-			renderKanbanTasksHTML(tasksByState[models.TaskStateDone]))
-
-		writeHTMX(w, http.StatusOK, html)
+		renderTemplate(w, http.StatusOK, "kanban", pageData{
+			Title:   "All Tasks",
+			Nav:     pageNav(nil, false),
+			Lists:   lists,
+			Columns: kanbanColumns(models.DefaultTaskStates, tasks),
+			States:  models.DefaultTaskStates,
+		})
+	}
+}
+
+// HandleAPIDocs renders a page documenting the API from the OpenAPI spec.
+// The page's script and styles are served from /static, so it works without
+// access to a CDN.
+func HandleAPIDocs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		renderTemplate(w, http.StatusOK, "docs-page", pageData{
+			Title: "API Docs",
+			Nav:   pageNav(nil, false),
+		})
 	}
 }
 
@@ -1235,6 +1789,16 @@ func writeErrorJSON(w http.ResponseWriter, status int, message string) {
 	writeJSON(w, status, map[string]string{"error": message})
 }
 
+// listLocation returns the canonical URL of a list, for Location headers
+func listLocation(listID string) string {
+	return "/api/lists/" + url.PathEscape(listID)
+}
+
+// taskLocation returns the canonical URL of a task, for Location headers
+func taskLocation(listID, taskID string) string {
+	return "/api/tasks/" + url.PathEscape(listID) + "/" + url.PathEscape(taskID)
+}
+
 // writeHTMX writes an HTMX response
 func writeHTMX(w http.ResponseWriter, status int, content string) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -1242,8 +1806,10 @@ func writeHTMX(w http.ResponseWriter, status int, content string) {
 	fmt.Fprint(w, content)
 }
 
-// HandleOpenAPISpec generates and returns the OpenAPI specification for the API
-func HandleOpenAPISpec(store *storage.FileStore) http.HandlerFunc {
+// HandleOpenAPISpec generates and returns the OpenAPI specification for the
+// API. The hand-written entries below are synced with the routes registered
+// on routes and completed from the model types, see openapi.go.
+func HandleOpenAPISpec(store storage.TaskStore, routes chi.Routes) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Generate an API spec manually with just JSON marshaling
 		spec := map[string]interface{}{
@@ -1263,8 +1829,14 @@ func HandleOpenAPISpec(store *storage.FileStore) http.HandlerFunc {
 				"/api/lists": map[string]interface{}{
 					"get": map[string]interface{}{
 						"summary":     "Get all lists",
-						"description": "Returns all task lists",
+						"description": "Returns the task lists in display order; archived lists are omitted unless include_archived=true",
 						"operationId": "getAllLists",
+						"parameters": []map[string]interface{}{
+							{"name": "include_archived", "in": "query", "description": "Include archived lists", "schema": map[string]string{"type": "boolean"}},
+							{"name": "include_errors", "in": "query", "description": "Include placeholder entries for lists that cannot be read", "schema": map[string]string{"type": "boolean"}},
+							{"name": "limit", "in": "query", "description": "Page size (clamped to the server maximum); enables pagination", "schema": map[string]string{"type": "integer"}},
+							{"name": "offset", "in": "query", "description": "Number of items to skip; enables pagination", "schema": map[string]string{"type": "integer"}},
+						},
 						"responses": map[string]interface{}{
 							"200": map[string]interface{}{
 								"description": "Successful operation",
@@ -1294,58 +1866,246 @@ func HandleOpenAPISpec(store *storage.FileStore) http.HandlerFunc {
 						"responses": map[string]interface{}{
 							"201": map[string]interface{}{
 								"description": "List created",
+								"headers": map[string]interface{}{
+									"Location": map[string]interface{}{
+										"description": "URL of the new list",
+										"schema":      map[string]string{"type": "string"},
+									},
+								},
 								"content": map[string]interface{}{
 									"application/json": map[string]interface{}{
 										"schema": map[string]string{"$ref": "#/components/schemas/TaskList"},
 									},
 								},
 							},
+							"400": map[string]interface{}{
+								"description": "Invalid list",
+							},
+							"409": map[string]interface{}{
+								"description": "A list with the ID already exists",
+							},
 						},
 					},
 				},
-				"/api/lists/{listID}": map[string]interface{}{
-					"parameters": []map[string]interface{}{
-						{
-							"name":        "listID",
-							"in":          "path",
-							"required":    true,
-							"description": "ID of the task list",
-							"schema":      map[string]string{"type": "string"},
+				"/api/lists/reorder": map[string]interface{}{
+					"post": map[string]interface{}{
+						"summary":     "Reorder lists",
+						"description": "Sets the display order of the lists; lists not named keep their relative order after the named ones",
+						"operationId": "reorderLists",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]interface{}{
+										"type": "object",
+										"properties": map[string]interface{}{
+											"ids": map[string]interface{}{
+												"type":        "array",
+												"description": "List IDs in the desired order",
+												"items":       map[string]string{"type": "string"},
+											},
+										},
+										"required": []string{"ids"},
+									},
+								},
+							},
 						},
-					},
-					"get": map[string]interface{}{
-						"summary":     "Get a task list",
-						"description": "Returns a task list by ID",
-						"operationId": "getList",
 						"responses": map[string]interface{}{
 							"200": map[string]interface{}{
-								"description": "Successful operation",
+								"description": "All lists in their new order, including archived ones",
 								"content": map[string]interface{}{
 									"application/json": map[string]interface{}{
-										"schema": map[string]string{"$ref": "#/components/schemas/TaskList"},
+										"schema": map[string]interface{}{
+											"type":  "array",
+											"items": map[string]string{"$ref": "#/components/schemas/TaskList"},
+										},
 									},
 								},
 							},
-							"404": map[string]interface{}{
-								"description": "List not found",
+							"400": map[string]interface{}{
+								"description": "Missing IDs or unknown list",
 							},
 						},
 					},
-					"put": map[string]interface{}{
-						"summary":     "Update a task list",
-						"description": "Updates a task list by ID",
-						"operationId": "updateList",
+				},
+				"/api/lists/archive-batch": map[string]interface{}{
+					"post": map[string]interface{}{
+						"summary":     "Bulk archive lists",
+						"description": "Archives several task lists at once",
+						"operationId": "bulkArchiveLists",
 						"requestBody": map[string]interface{}{
 							"required": true,
 							"content": map[string]interface{}{
 								"application/json": map[string]interface{}{
-									"schema": map[string]string{"$ref": "#/components/schemas/TaskList"},
+									"schema": map[string]interface{}{
+										"type": "object",
+										"properties": map[string]interface{}{
+											"ids": map[string]interface{}{
+												"type":  "array",
+												"items": map[string]string{"type": "string"},
+											},
+										},
+										"required": []string{"ids"},
+									},
 								},
 							},
 						},
 						"responses": map[string]interface{}{
 							"200": map[string]interface{}{
-								"description": "List updated",
+								"description": "Per-list results",
+							},
+							"400": map[string]interface{}{
+								"description": "Invalid request",
+							},
+						},
+					},
+				},
+				"/api/lists/{listID}/duplicate": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the list to copy", "schema": map[string]string{"type": "string"}},
+					},
+					"post": map[string]interface{}{
+						"summary":     "Duplicate a list",
+						"description": "Creates a new list with copies of every task, including subtasks and notes, under fresh IDs and timestamps. History, comments and attachments are not copied. Nothing is created if any part of the copy fails.",
+						"operationId": "duplicateList",
+						"requestBody": map[string]interface{}{
+							"required": false,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]interface{}{
+										"type": "object",
+										"properties": map[string]interface{}{
+											"name": map[string]string{
+												"type":        "string",
+												"description": "Name of the new list; defaults to the original name with a \"(copy)\" suffix",
+											},
+										},
+									},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"201": map[string]interface{}{
+								"description": "List duplicated",
+								"headers": map[string]interface{}{
+									"Location": map[string]interface{}{
+										"description": "URL of the new list",
+										"schema":      map[string]string{"type": "string"},
+									},
+								},
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/TaskList"},
+									},
+								},
+							},
+							"404": map[string]interface{}{
+								"description": "List not found",
+							},
+							"409": map[string]interface{}{
+								"description": "The copy would exceed the task limit",
+							},
+						},
+					},
+				},
+				"/api/lists/{listID}/rename-id": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "Current ID of the list", "schema": map[string]string{"type": "string"}},
+					},
+					"post": map[string]interface{}{
+						"summary":     "Change a list's ID",
+						"description": "Gives a list a new ID, moving its tasks, deleted tasks and attachments with it and recording the change in each task's history. Useful when importing data that should keep stable IDs.",
+						"operationId": "renameListID",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]interface{}{
+										"type": "object",
+										"properties": map[string]interface{}{
+											"id": map[string]string{
+												"type":        "string",
+												"description": "New ID: letters, digits, dashes and underscores",
+											},
+										},
+										"required": []string{"id"},
+									},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "List ID changed",
+								"headers": map[string]interface{}{
+									"Location": map[string]interface{}{
+										"description": "URL of the list under its new ID",
+										"schema":      map[string]string{"type": "string"},
+									},
+								},
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/TaskList"},
+									},
+								},
+							},
+							"400": map[string]interface{}{
+								"description": "Missing or invalid new ID, or the list already has it",
+							},
+							"404": map[string]interface{}{
+								"description": "List not found",
+							},
+							"409": map[string]interface{}{
+								"description": "Another list already has the new ID",
+							},
+						},
+					},
+				},
+				"/api/lists/{listID}": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{
+							"name":        "listID",
+							"in":          "path",
+							"required":    true,
+							"description": "ID of the task list",
+							"schema":      map[string]string{"type": "string"},
+						},
+					},
+					"get": map[string]interface{}{
+						"summary":     "Get a task list",
+						"description": "Returns a task list by ID",
+						"operationId": "getList",
+						"responses": map[string]interface{}{
+							"304": map[string]interface{}{
+								"description": "Not modified; the If-None-Match header matches the current ETag",
+							},
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/TaskList"},
+									},
+								},
+							},
+							"404": map[string]interface{}{
+								"description": "List not found",
+							},
+						},
+					},
+					"put": map[string]interface{}{
+						"summary":     "Update a task list",
+						"description": "Updates a task list by ID",
+						"operationId": "updateList",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]string{"$ref": "#/components/schemas/TaskList"},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "List updated",
 							},
 							"404": map[string]interface{}{
 								"description": "List not found",
@@ -1366,6 +2126,61 @@ func HandleOpenAPISpec(store *storage.FileStore) http.HandlerFunc {
 						},
 					},
 				},
+				"/api/lists/{listID}/tasks/batch": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+					},
+					"post": map[string]interface{}{
+						"summary":     "Create several tasks",
+						"description": "Creates every task in the array in the list, validating each like a single create. Tasks that fail are reported by their index without aborting the others.",
+						"operationId": "batchCreateTasks",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]interface{}{
+										"type":  "array",
+										"items": map[string]string{"$ref": "#/components/schemas/Task"},
+									},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Created tasks and per-item errors",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]interface{}{
+											"type": "object",
+											"properties": map[string]interface{}{
+												"created": map[string]interface{}{
+													"type":  "array",
+													"items": map[string]string{"$ref": "#/components/schemas/Task"},
+												},
+												"errors": map[string]interface{}{
+													"type": "array",
+													"items": map[string]interface{}{
+														"type": "object",
+														"properties": map[string]interface{}{
+															"index": map[string]string{"type": "integer", "description": "Position of the task in the request array"},
+															"error": map[string]string{"type": "string"},
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+							"400": map[string]interface{}{
+								"description": "Body is not an array of tasks",
+							},
+							"404": map[string]interface{}{
+								"description": "List not found",
+							},
+						},
+					},
+				},
 				"/api/lists/{listID}/tasks": map[string]interface{}{
 					"parameters": []map[string]interface{}{
 						{
@@ -1378,8 +2193,159 @@ func HandleOpenAPISpec(store *storage.FileStore) http.HandlerFunc {
 					},
 					"get": map[string]interface{}{
 						"summary":     "Get tasks for a list",
-						"description": "Returns all tasks in a specific list",
+						"description": "Returns all tasks in a specific list; archived tasks are omitted unless include_archived=true",
 						"operationId": "getTasksForList",
+						"parameters": []map[string]interface{}{
+							{"name": "sort", "in": "query", "description": "Sort order; 'priority' lists the most urgent tasks first", "schema": map[string]interface{}{"type": "string", "enum": []string{"priority"}}},
+							{"name": "include_archived", "in": "query", "description": "Include archived tasks", "schema": map[string]string{"type": "boolean"}},
+							{"name": "due", "in": "query", "description": "Only return tasks that are overdue (and not done), due today, or due within a week", "schema": map[string]interface{}{"type": "string", "enum": []string{"overdue", "today", "week"}}},
+							{"name": "ready", "in": "query", "description": "With true, leave out tasks whose start date is still in the future", "schema": map[string]string{"type": "boolean"}},
+							{"name": "flagged", "in": "query", "description": "Only return flagged tasks with true, or unflagged ones with false", "schema": map[string]string{"type": "boolean"}},
+							{"name": "updated_since", "in": "query", "description": "Only return tasks updated after this time (RFC 3339 timestamp, date or relative date), for incremental sync", "schema": map[string]string{"type": "string"}},
+							{"name": "limit", "in": "query", "description": "Page size (clamped to the server maximum); enables pagination", "schema": map[string]string{"type": "integer"}},
+							{"name": "offset", "in": "query", "description": "Number of items to skip; enables pagination", "schema": map[string]string{"type": "integer"}},
+						},
+						"responses": map[string]interface{}{
+							"304": map[string]interface{}{
+								"description": "Not modified; the If-None-Match header matches the current ETag",
+							},
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]interface{}{
+											"type":  "array",
+											"items": map[string]string{"$ref": "#/components/schemas/Task"},
+										},
+									},
+								},
+							},
+						},
+					},
+					"post": map[string]interface{}{
+						"summary":     "Create a task in a list",
+						"description": "Creates a new task in the specified list",
+						"operationId": "createTask",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"201": map[string]interface{}{
+								"description": "Task created",
+								"headers": map[string]interface{}{
+									"Location": map[string]interface{}{
+										"description": "URL of the new task",
+										"schema":      map[string]string{"type": "string"},
+									},
+								},
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+									},
+								},
+							},
+							"409": map[string]interface{}{
+								"description": "The list or the store already holds as many tasks as allowed",
+							},
+						},
+					},
+				},
+				"/api/lists/{listID}/duplicates": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+					},
+					"get": map[string]interface{}{
+						"summary":     "Find duplicate tasks",
+						"description": "Returns groups of tasks with identical titles (ignoring case and whitespace) or titles within the given edit distance",
+						"operationId": "getDuplicateTasks",
+						"parameters": []map[string]interface{}{
+							{"name": "distance", "in": "query", "description": "Maximum Levenshtein distance between titles (0-10, default 0)", "schema": map[string]string{"type": "integer"}},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Groups of duplicate tasks",
+							},
+							"400": map[string]interface{}{
+								"description": "Invalid distance",
+							},
+							"404": map[string]interface{}{
+								"description": "List not found",
+							},
+						},
+					},
+				},
+				"/api/lists/{listID}/report": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+					},
+					"get": map[string]interface{}{
+						"summary":     "Get time report",
+						"description": "Returns, per task, the time in its current state and the total time spent in each state, plus the list's average time to done and average time per state (all in seconds), and each task's and the list's total estimate_minutes and spent_minutes",
+						"operationId": "getListTimeReport",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+							},
+							"404": map[string]interface{}{
+								"description": "List not found",
+							},
+						},
+					},
+				},
+				"/api/lists/{listID}/tasks/{taskID}/siblings": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the task", "schema": map[string]string{"type": "string"}},
+					},
+					"get": map[string]interface{}{
+						"summary":     "Get task siblings",
+						"description": "Returns the previous and next task IDs within the same state, ordered by position (null at the ends)",
+						"operationId": "getTaskSiblings",
+						"parameters": []map[string]interface{}{
+							{"name": "state", "in": "query", "description": "State column to look in (defaults to the task's state)", "schema": map[string]string{"type": "string"}},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+							},
+							"404": map[string]interface{}{
+								"description": "List or task not found",
+							},
+						},
+					},
+				},
+				"/api/tasks": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Get all tasks",
+						"description": "Returns all tasks across all lists; archived tasks are omitted unless include_archived=true",
+						"operationId": "getAllTasks",
+						"parameters": []map[string]interface{}{
+							{
+								"name":        "flatten_subtasks",
+								"in":          "query",
+								"required":    false,
+								"description": "Hoist subtasks into the top-level array with parent_id set",
+								"schema":      map[string]string{"type": "boolean"},
+							},
+							{"name": "tag", "in": "query", "description": "Only return tasks carrying this tag", "schema": map[string]string{"type": "string"}},
+							{"name": "assignee", "in": "query", "description": "Only return tasks owned by this assignee, or 'unassigned'", "schema": map[string]string{"type": "string"}},
+							{"name": "due", "in": "query", "description": "Only return tasks that are overdue (and not done), due today, or due within a week", "schema": map[string]interface{}{"type": "string", "enum": []string{"overdue", "today", "week"}}},
+							{"name": "state", "in": "query", "description": "Only return tasks in these states; repeatable or comma-separated. Each must be a default state or a custom state of some list", "schema": map[string]interface{}{"type": "array", "items": map[string]string{"type": "string"}}, "style": "form", "explode": true},
+							{"name": "completed_after", "in": "query", "description": "Only return tasks completed at or after this time (RFC 3339 timestamp, date or relative date)", "schema": map[string]string{"type": "string"}},
+							{"name": "completed_before", "in": "query", "description": "Only return tasks completed before this time (RFC 3339 timestamp, date or relative date)", "schema": map[string]string{"type": "string"}},
+							{"name": "include_archived", "in": "query", "description": "Include archived tasks", "schema": map[string]string{"type": "boolean"}},
+							{"name": "lists", "in": "query", "description": "Comma-separated list IDs; only those lists are read and their tasks returned. Unknown lists return 404", "schema": map[string]string{"type": "string"}},
+							{"name": "ready", "in": "query", "description": "With true, leave out tasks whose start date is still in the future", "schema": map[string]string{"type": "boolean"}},
+							{"name": "flagged", "in": "query", "description": "Only return flagged tasks with true, or unflagged ones with false", "schema": map[string]string{"type": "boolean"}},
+							{"name": "updated_since", "in": "query", "description": "Only return tasks updated after this time (RFC 3339 timestamp, date or relative date), for incremental sync", "schema": map[string]string{"type": "string"}},
+							{"name": "limit", "in": "query", "description": "Page size (clamped to the server maximum); enables pagination", "schema": map[string]string{"type": "integer"}},
+							{"name": "offset", "in": "query", "description": "Number of items to skip; enables pagination", "schema": map[string]string{"type": "integer"}},
+						},
 						"responses": map[string]interface{}{
 							"200": map[string]interface{}{
 								"description": "Successful operation",
@@ -1392,137 +2358,1381 @@ func HandleOpenAPISpec(store *storage.FileStore) http.HandlerFunc {
 									},
 								},
 							},
+							"400": map[string]interface{}{
+								"description": "Invalid due window or state; the error lists the allowed values",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}/move": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the list the task is in", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the task", "schema": map[string]string{"type": "string"}},
+					},
+					"post": map[string]interface{}{
+						"summary":     "Move a task",
+						"description": "Moves a task into another list. This is the canonical way to move tasks; changing list_id with PUT also moves them.",
+						"operationId": "moveTask",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]interface{}{
+										"type": "object",
+										"properties": map[string]interface{}{
+											"target_list_id": map[string]string{
+												"type":        "string",
+												"description": "ID of the destination list",
+											},
+											"position": map[string]string{
+												"type":        "integer",
+												"description": "Position in the destination list; the current position is kept when omitted",
+											},
+											"reset_state": map[string]string{
+												"type":        "boolean",
+												"description": "Move the task into the destination's first state if its workflow lacks the task's state, instead of failing",
+											},
+										},
+										"required": []string{"target_list_id"},
+									},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Task moved",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+									},
+								},
+							},
+							"400": map[string]interface{}{
+								"description": "Missing target list, or the destination workflow lacks the task's state",
+							},
+							"404": map[string]interface{}{
+								"description": "Task, list or target list not found",
+							},
+							"409": map[string]interface{}{
+								"description": "Task is already in the target list, or the target list is full",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}/position": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the list the task is in", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the task", "schema": map[string]string{"type": "string"}},
+					},
+					"post": map[string]interface{}{
+						"summary":     "Reposition a task",
+						"description": "Moves a task within its list's position order, shifting the tasks around it, without rewriting every position. Give either after, to place the task directly after another task, or index, its 0-based place among the tasks in the same state (its kanban column).",
+						"operationId": "positionTask",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]interface{}{
+										"type": "object",
+										"properties": map[string]interface{}{
+											"after": map[string]string{
+												"type":        "string",
+												"description": "ID of the task to place this one directly after",
+											},
+											"index": map[string]string{
+												"type":        "integer",
+												"description": "0-based place among the tasks in the same state; past the end places the task last in its column",
+											},
+										},
+									},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Task repositioned",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+									},
+								},
+							},
+							"400": map[string]interface{}{
+								"description": "Neither or both of after and index given, a negative index, or after names a task not in the list",
+							},
+							"404": map[string]interface{}{
+								"description": "Task or list not found",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}/duplicate": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the task to copy", "schema": map[string]string{"type": "string"}},
+					},
+					"post": map[string]interface{}{
+						"summary":     "Duplicate a task",
+						"description": "Copies a task, with its notes and subtasks under new IDs, into the same list. The copy's title gets a \"(copy)\" suffix, its timestamps are reset and it starts in the list's first state. HTMX requests receive the refreshed tasks container.",
+						"operationId": "duplicateTask",
+						"responses": map[string]interface{}{
+							"201": map[string]interface{}{
+								"description": "Task duplicated",
+								"headers": map[string]interface{}{
+									"Location": map[string]interface{}{
+										"description": "URL of the new task",
+										"schema":      map[string]string{"type": "string"},
+									},
+								},
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+									},
+								},
+							},
+							"404": map[string]interface{}{
+								"description": "Task not found",
+							},
+							"409": map[string]interface{}{
+								"description": "The list or the store already holds as many tasks as allowed",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}/comments": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the task", "schema": map[string]string{"type": "string"}},
+					},
+					"get": map[string]interface{}{
+						"summary":     "List comments",
+						"description": "Returns the comments on a task, oldest first",
+						"operationId": "getComments",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]interface{}{
+											"type":  "array",
+											"items": map[string]string{"$ref": "#/components/schemas/Comment"},
+										},
+									},
+								},
+							},
+							"404": map[string]interface{}{
+								"description": "Task not found",
+							},
+						},
+					},
+					"post": map[string]interface{}{
+						"summary":     "Add a comment",
+						"description": "Adds a comment to a task; the ID and creation time are assigned by the server and the author defaults to the basic auth user",
+						"operationId": "createComment",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]string{"$ref": "#/components/schemas/Comment"},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"201": map[string]interface{}{
+								"description": "Comment created",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/Comment"},
+									},
+								},
+							},
+							"400": map[string]interface{}{
+								"description": "Missing author or body",
+							},
+							"404": map[string]interface{}{
+								"description": "Task not found",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}/comments/{commentID}": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the task", "schema": map[string]string{"type": "string"}},
+						{"name": "commentID", "in": "path", "required": true, "description": "ID of the comment", "schema": map[string]string{"type": "string"}},
+					},
+					"delete": map[string]interface{}{
+						"summary":     "Delete a comment",
+						"description": "Removes a comment from a task",
+						"operationId": "deleteComment",
+						"responses": map[string]interface{}{
+							"204": map[string]interface{}{
+								"description": "Comment deleted",
+							},
+							"404": map[string]interface{}{
+								"description": "Task or comment not found",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}/notes": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the task", "schema": map[string]string{"type": "string"}},
+					},
+					"post": map[string]interface{}{
+						"summary":     "Add a note",
+						"description": "Adds a note to a task; the ID and timestamps are assigned by the server",
+						"operationId": "createNote",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]string{"$ref": "#/components/schemas/Note"},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"201": map[string]interface{}{
+								"description": "Note created",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/Note"},
+									},
+								},
+							},
+							"400": map[string]interface{}{
+								"description": "Invalid note",
+							},
+							"404": map[string]interface{}{
+								"description": "Task not found",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}/notes/{noteID}": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the task", "schema": map[string]string{"type": "string"}},
+						{"name": "noteID", "in": "path", "required": true, "description": "ID of the note", "schema": map[string]string{"type": "string"}},
+					},
+					"put": map[string]interface{}{
+						"summary":     "Update a note",
+						"description": "Replaces the content of a note",
+						"operationId": "updateNote",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]string{"$ref": "#/components/schemas/Note"},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Note updated",
+							},
+							"400": map[string]interface{}{
+								"description": "Invalid note",
+							},
+							"404": map[string]interface{}{
+								"description": "Task or note not found",
+							},
+						},
+					},
+					"delete": map[string]interface{}{
+						"summary":     "Delete a note",
+						"description": "Removes a note from a task",
+						"operationId": "deleteNote",
+						"responses": map[string]interface{}{
+							"204": map[string]interface{}{
+								"description": "Note deleted",
+							},
+							"404": map[string]interface{}{
+								"description": "Task or note not found",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}/subtasks": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the parent task", "schema": map[string]string{"type": "string"}},
+					},
+					"post": map[string]interface{}{
+						"summary":     "Add a subtask",
+						"description": "Adds a subtask to a task; the ID and timestamps are assigned by the server",
+						"operationId": "addSubTask",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"201": map[string]interface{}{
+								"description": "Subtask created",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+									},
+								},
+							},
+							"400": map[string]interface{}{
+								"description": "Invalid subtask",
+							},
+							"404": map[string]interface{}{
+								"description": "Task not found",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}/subtasks/{subTaskID}": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the parent task", "schema": map[string]string{"type": "string"}},
+						{"name": "subTaskID", "in": "path", "required": true, "description": "ID of the subtask", "schema": map[string]string{"type": "string"}},
+					},
+					"put": map[string]interface{}{
+						"summary":     "Update a subtask",
+						"description": "Updates a subtask; omitted fields keep their current values",
+						"operationId": "updateSubTask",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Subtask updated",
+							},
+							"400": map[string]interface{}{
+								"description": "Invalid subtask",
+							},
+							"404": map[string]interface{}{
+								"description": "Task or subtask not found",
+							},
+						},
+					},
+					"delete": map[string]interface{}{
+						"summary":     "Delete a subtask",
+						"description": "Removes a subtask from a task",
+						"operationId": "deleteSubTask",
+						"responses": map[string]interface{}{
+							"204": map[string]interface{}{
+								"description": "Subtask deleted",
+							},
+							"404": map[string]interface{}{
+								"description": "Task or subtask not found",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}/streak": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the task", "schema": map[string]string{"type": "string"}},
+					},
+					"get": map[string]interface{}{
+						"summary":     "Get task streak",
+						"description": "Returns the current and longest runs of consecutive intervals in which a recurring task was completed",
+						"operationId": "getTaskStreak",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+							},
+							"400": map[string]interface{}{
+								"description": "Task is not recurring",
+							},
+							"404": map[string]interface{}{
+								"description": "Task not found",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}/blockers": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the task", "schema": map[string]string{"type": "string"}},
+					},
+					"get": map[string]interface{}{
+						"summary":     "Get task blockers",
+						"description": "Returns the dependencies of a task that are not done yet",
+						"operationId": "getTaskBlockers",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]interface{}{
+											"type":  "array",
+											"items": map[string]string{"$ref": "#/components/schemas/Task"},
+										},
+									},
+								},
+							},
+							"404": map[string]interface{}{
+								"description": "Task not found",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}/attachments": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the task", "schema": map[string]string{"type": "string"}},
+					},
+					"get": map[string]interface{}{
+						"summary":     "List attachments",
+						"description": "Returns the metadata of the files attached to a task",
+						"operationId": "getAttachments",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]interface{}{
+											"type":  "array",
+											"items": map[string]string{"$ref": "#/components/schemas/Attachment"},
+										},
+									},
+								},
+							},
+							"404": map[string]interface{}{
+								"description": "Task not found",
+							},
+						},
+					},
+					"post": map[string]interface{}{
+						"summary":     "Upload an attachment",
+						"description": "Attaches the file sent in the 'file' field of a multipart form to the task",
+						"operationId": "uploadAttachment",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"multipart/form-data": map[string]interface{}{
+									"schema": map[string]interface{}{
+										"type": "object",
+										"properties": map[string]interface{}{
+											"file": map[string]string{"type": "string", "format": "binary"},
+										},
+										"required": []string{"file"},
+									},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"201": map[string]interface{}{
+								"description": "Attachment stored",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/Attachment"},
+									},
+								},
+							},
+							"400": map[string]interface{}{
+								"description": "Missing file or invalid filename",
+							},
+							"404": map[string]interface{}{
+								"description": "Task not found",
+							},
+							"413": map[string]interface{}{
+								"description": "File exceeds the maximum upload size",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}/attachments/{attachmentID}": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the task", "schema": map[string]string{"type": "string"}},
+						{"name": "attachmentID", "in": "path", "required": true, "description": "ID of the attachment", "schema": map[string]string{"type": "string"}},
+					},
+					"get": map[string]interface{}{
+						"summary":     "Download an attachment",
+						"description": "Returns the attachment's content with its original filename and content type",
+						"operationId": "downloadAttachment",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Attachment content",
+							},
+							"404": map[string]interface{}{
+								"description": "Attachment not found",
+							},
+						},
+					},
+					"delete": map[string]interface{}{
+						"summary":     "Delete an attachment",
+						"description": "Removes an attachment and its content from the task",
+						"operationId": "deleteAttachment",
+						"responses": map[string]interface{}{
+							"204": map[string]interface{}{
+								"description": "Attachment deleted",
+							},
+							"404": map[string]interface{}{
+								"description": "Attachment not found",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}/history": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "listID", "in": "path", "required": true, "description": "ID of the task list", "schema": map[string]string{"type": "string"}},
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the task", "schema": map[string]string{"type": "string"}},
+					},
+					"get": map[string]interface{}{
+						"summary":     "Get task history",
+						"description": "Returns the field changes recorded for a task, oldest first",
+						"operationId": "getTaskHistory",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]interface{}{
+											"type":  "array",
+											"items": map[string]string{"$ref": "#/components/schemas/HistoryEntry"},
+										},
+									},
+								},
+							},
+							"404": map[string]interface{}{
+								"description": "Task not found",
+							},
+						},
+					},
+				},
+				"/api/tasks/{listID}/{taskID}": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{
+							"name":        "listID",
+							"in":          "path",
+							"required":    true,
+							"description": "ID of the task list",
+							"schema":      map[string]string{"type": "string"},
+						},
+						{
+							"name":        "taskID",
+							"in":          "path",
+							"required":    true,
+							"description": "ID of the task",
+							"schema":      map[string]string{"type": "string"},
+						},
+					},
+					"get": map[string]interface{}{
+						"summary":     "Get a task",
+						"description": "Returns a task by ID",
+						"operationId": "getTask",
+						"responses": map[string]interface{}{
+							"304": map[string]interface{}{
+								"description": "Not modified; the If-None-Match header matches the current ETag",
+							},
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+									},
+								},
+							},
+							"404": map[string]interface{}{
+								"description": "Task not found",
+							},
+						},
+					},
+					"put": map[string]interface{}{
+						"summary":     "Update a task",
+						"description": "Updates a task by ID; fields left out of the body keep their values. A list_id different from the one in the path moves the task to that list. If the task does not exist it is created with the ID from the path",
+						"operationId": "updateTask",
+						"parameters": []map[string]interface{}{
+							{"name": "reset_state", "in": "query", "description": "When moving, put the task in the destination list's first state if its state is not allowed there, instead of failing", "schema": map[string]string{"type": "boolean"}},
+						},
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+								},
+								"application/x-www-form-urlencoded": map[string]interface{}{
+									"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Task updated or moved",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+									},
+								},
+							},
+							"201": map[string]interface{}{
+								"description": "Task created with the ID from the path",
+								"headers": map[string]interface{}{
+									"Location": map[string]interface{}{
+										"description": "URL of the new task",
+										"schema":      map[string]string{"type": "string"},
+									},
+								},
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+									},
+								},
+							},
+							"400": map[string]interface{}{
+								"description": "Invalid task, or its state is not allowed in the destination list",
+							},
+							"409": map[string]interface{}{
+								"description": "The request's version is older than the stored task, its dependencies are not done, or a task limit was reached",
+							},
+							"412": map[string]interface{}{
+								"description": "If-Match does not match the task's current ETag",
+							},
+						},
+					},
+					"patch": map[string]interface{}{
+						"summary":     "Partially update a task",
+						"description": "Applies a JSON merge patch (RFC 7386) to a task: only the fields in the body change, and null clears a field. Changing list_id moves the task as with a full update",
+						"operationId": "patchTask",
+						"parameters": []map[string]interface{}{
+							{"name": "reset_state", "in": "query", "description": "When moving, put the task in the destination list's first state if its state is not allowed there, instead of failing", "schema": map[string]string{"type": "boolean"}},
+						},
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/merge-patch+json": map[string]interface{}{
+									"schema": map[string]string{"type": "object"},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Task updated",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+									},
+								},
+							},
+							"400": map[string]interface{}{
+								"description": "The patch is not a JSON object, or sets an unknown field or a value of the wrong type",
+							},
+							"404": map[string]interface{}{
+								"description": "Task not found",
+							},
+							"409": map[string]interface{}{
+								"description": "The patch's version is older than the stored task",
+							},
+							"412": map[string]interface{}{
+								"description": "If-Match does not match the task's current ETag",
+							},
+						},
+					},
+					"delete": map[string]interface{}{
+						"summary":     "Delete a task",
+						"description": "Deletes a task by ID",
+						"operationId": "deleteTask",
+						"responses": map[string]interface{}{
+							"204": map[string]interface{}{
+								"description": "Task deleted",
+							},
+							"404": map[string]interface{}{
+								"description": "Task not found",
+							},
+						},
+					},
+				},
+				"/api/tasks/filter": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Filter tasks",
+						"description": "Returns tasks matching all of the given criteria",
+						"operationId": "filterTasks",
+						"parameters": []map[string]interface{}{
+							{"name": "state", "in": "query", "description": "Comma-separated task states", "schema": map[string]string{"type": "string"}},
+							{"name": "tag", "in": "query", "description": "Tag the task must carry", "schema": map[string]string{"type": "string"}},
+							{"name": "assignee", "in": "query", "description": "Assignee, or 'unassigned'", "schema": map[string]string{"type": "string"}},
+							{"name": "priority", "in": "query", "description": "Task priority", "schema": map[string]string{"type": "string"}},
+							{"name": "due_before", "in": "query", "description": "Absolute or relative date the task must be due before", "schema": map[string]string{"type": "string"}},
+							{"name": "has_due", "in": "query", "description": "Whether the task has a due date", "schema": map[string]string{"type": "boolean"}},
+							{"name": "include_archived", "in": "query", "description": "Include archived tasks", "schema": map[string]string{"type": "boolean"}},
+							{"name": "q", "in": "query", "description": "Text to search for in title, description and notes", "schema": map[string]string{"type": "string"}},
+							{"name": "limit", "in": "query", "description": "Page size (clamped to the server maximum); enables pagination", "schema": map[string]string{"type": "integer"}},
+							{"name": "offset", "in": "query", "description": "Number of items to skip; enables pagination", "schema": map[string]string{"type": "integer"}},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]interface{}{
+											"type":  "array",
+											"items": map[string]string{"$ref": "#/components/schemas/Task"},
+										},
+									},
+								},
+							},
+							"400": map[string]interface{}{
+								"description": "Invalid filter value",
+							},
+						},
+					},
+				},
+				"/api/tasks/bulk": map[string]interface{}{
+					"post": map[string]interface{}{
+						"summary":     "Bulk task operation",
+						"description": "Applies set_state, move, delete or add_tag to several tasks, reporting the outcome for each ID. One failure does not abort the rest",
+						"operationId": "bulkTasks",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]interface{}{
+										"type": "object",
+										"properties": map[string]interface{}{
+											"operation": map[string]interface{}{
+												"type": "string",
+												"enum": []string{"set_state", "move", "delete", "add_tag"},
+											},
+											"ids": map[string]interface{}{
+												"type":  "array",
+												"items": map[string]string{"type": "string"},
+											},
+											"state":          map[string]string{"type": "string"},
+											"blocked_reason": map[string]string{"type": "string"},
+											"target_list_id": map[string]string{"type": "string"},
+											"tag":            map[string]string{"type": "string"},
+										},
+										"required": []string{"operation", "ids"},
+									},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Per-task results",
+							},
+							"400": map[string]interface{}{
+								"description": "Invalid operation or missing operation parameters",
+							},
+							"404": map[string]interface{}{
+								"description": "Target list not found",
+							},
+						},
+					},
+				},
+				"/api/tasks/bulk-due": map[string]interface{}{
+					"post": map[string]interface{}{
+						"summary":     "Bulk set due dates",
+						"description": "Sets the due date of several tasks using an absolute or relative date (e.g. +5d, friday, clear)",
+						"operationId": "bulkSetDue",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]interface{}{
+										"type": "object",
+										"properties": map[string]interface{}{
+											"ids": map[string]interface{}{
+												"type":  "array",
+												"items": map[string]string{"type": "string"},
+											},
+											"due": map[string]string{"type": "string"},
+										},
+										"required": []string{"ids", "due"},
+									},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Per-task results",
+							},
+							"400": map[string]interface{}{
+								"description": "Invalid request or due date",
+							},
+						},
+					},
+				},
+				"/api/tasks/move-by-filter": map[string]interface{}{
+					"post": map[string]interface{}{
+						"summary":     "Move tasks by filter",
+						"description": "Moves every task matching the filter into the target list. Tasks whose ID already exists in the target list are reported as failures rather than overwritten",
+						"operationId": "moveTasksByFilter",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]interface{}{
+										"type": "object",
+										"properties": map[string]interface{}{
+											"filter": map[string]interface{}{
+												"type":        "object",
+												"description": "Filter criteria, using the same keys as GET /api/tasks/filter",
+											},
+											"target_list_id": map[string]string{"type": "string"},
+											"dry_run": map[string]interface{}{
+												"type":        "boolean",
+												"description": "Report the matching tasks without moving them",
+											},
+										},
+										"required": []string{"filter", "target_list_id"},
+									},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Matched and moved counts with per-task results",
+							},
+							"400": map[string]interface{}{
+								"description": "Invalid request or filter",
+							},
+							"404": map[string]interface{}{
+								"description": "Target list not found",
+							},
+						},
+					},
+				},
+				"/api/search": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Search tasks",
+						"description": "Returns tasks whose title, description or notes contain the query (case-insensitive), each with its list name",
+						"operationId": "searchTasks",
+						"parameters": []map[string]interface{}{
+							{"name": "q", "in": "query", "required": true, "description": "Text to search for", "schema": map[string]string{"type": "string"}},
+							{"name": "list", "in": "query", "description": "Restrict the search to one list", "schema": map[string]string{"type": "string"}},
+							{"name": "state", "in": "query", "description": "Restrict the search to one state", "schema": map[string]string{"type": "string"}},
+							{"name": "include_archived", "in": "query", "description": "Include archived tasks", "schema": map[string]string{"type": "boolean"}},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Matching tasks with a list_name field",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]interface{}{
+											"type":  "array",
+											"items": map[string]string{"$ref": "#/components/schemas/Task"},
+										},
+									},
+								},
+							},
+							"400": map[string]interface{}{
+								"description": "Missing query",
+							},
+							"404": map[string]interface{}{
+								"description": "List not found",
+							},
+						},
+					},
+				},
+				"/api/filters": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Get saved filters",
+						"description": "Returns all saved filters",
+						"operationId": "getSavedFilters",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]interface{}{
+											"type":  "array",
+											"items": map[string]string{"$ref": "#/components/schemas/SavedFilter"},
+										},
+									},
+								},
+							},
+						},
+					},
+					"post": map[string]interface{}{
+						"summary":     "Save a filter",
+						"description": "Creates or replaces a named filter",
+						"operationId": "saveFilter",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/json": map[string]interface{}{
+									"schema": map[string]string{"$ref": "#/components/schemas/SavedFilter"},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"201": map[string]interface{}{
+								"description": "Filter saved",
+							},
+							"400": map[string]interface{}{
+								"description": "Invalid filter",
+							},
+						},
+					},
+				},
+				"/api/filters/{name}": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "name", "in": "path", "required": true, "description": "Name of the saved filter", "schema": map[string]string{"type": "string"}},
+					},
+					"delete": map[string]interface{}{
+						"summary":     "Delete a saved filter",
+						"description": "Deletes a saved filter by name",
+						"operationId": "deleteSavedFilter",
+						"responses": map[string]interface{}{
+							"204": map[string]interface{}{
+								"description": "Filter deleted",
+							},
+							"404": map[string]interface{}{
+								"description": "Filter not found",
+							},
+						},
+					},
+				},
+				"/api/filters/{name}/tasks": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "name", "in": "path", "required": true, "description": "Name of the saved filter", "schema": map[string]string{"type": "string"}},
+					},
+					"get": map[string]interface{}{
+						"summary":     "Apply a saved filter",
+						"description": "Returns the tasks matching a saved filter",
+						"operationId": "getSavedFilterTasks",
+						"parameters": []map[string]interface{}{
+							{"name": "include_archived", "in": "query", "description": "Include archived tasks", "schema": map[string]string{"type": "boolean"}},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]interface{}{
+											"type":  "array",
+											"items": map[string]string{"$ref": "#/components/schemas/Task"},
+										},
+									},
+								},
+							},
+							"404": map[string]interface{}{
+								"description": "Filter not found",
+							},
+						},
+					},
+				},
+				"/api/reports/by-assignee": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Per-assignee report",
+						"description": "Returns task counts by state and overdue count for each assignee; unassigned tasks are grouped under 'unassigned'",
+						"operationId": "getAssigneeReport",
+						"parameters": []map[string]interface{}{
+							{"name": "listID", "in": "query", "description": "Restrict the report to one list", "schema": map[string]string{"type": "string"}},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+							},
+							"404": map[string]interface{}{
+								"description": "List not found",
+							},
+						},
+					},
+				},
+				"/api/stats": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Store statistics",
+						"description": "Returns the number of lists and tasks, task counts per state, the overdue count, and the task count and summed estimate_minutes and spent_minutes of each list",
+						"operationId": "getStats",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+							},
+						},
+					},
+				},
+				"/api/reports/matrix": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Priority matrix report",
+						"description": "Buckets open tasks into Eisenhower quadrants: do_first (urgent and important), schedule (important), delegate (urgent) and eliminate. Urgent tasks are overdue or due within the urgency window; important tasks have high or urgent priority",
+						"operationId": "getMatrixReport",
+						"parameters": []map[string]interface{}{
+							{"name": "listID", "in": "query", "description": "Restrict the report to one list", "schema": map[string]string{"type": "string"}},
+							{"name": "days", "in": "query", "description": "Urgency window in days (default 3)", "schema": map[string]string{"type": "integer"}},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+							},
+							"400": map[string]interface{}{
+								"description": "Invalid days",
+							},
+							"404": map[string]interface{}{
+								"description": "List not found",
+							},
+						},
+					},
+				},
+				"/api/undo": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Get undo history",
+						"description": "Returns the recorded changes that can be undone, most recent first",
+						"operationId": "getUndoHistory",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+							},
+						},
+					},
+					"post": map[string]interface{}{
+						"summary":     "Undo last change",
+						"description": "Reverts the most recent task create, update, move or delete, or list deletion. An update, move or create is only reverted while the task is unchanged since; otherwise the entry is kept and 409 returned.",
+						"operationId": "undo",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Operation reverted",
+							},
+							"404": map[string]interface{}{
+								"description": "Nothing to undo",
+							},
+							"409": map[string]interface{}{
+								"description": "Operation could not be reverted, e.g. because the task has changed since",
+							},
+						},
+					},
+				},
+				"/api/trash": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Get trash",
+						"description": "Returns deleted tasks, most recently deleted first",
+						"operationId": "getTrash",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]interface{}{
+											"type":  "array",
+											"items": map[string]string{"$ref": "#/components/schemas/Task"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				"/api/trash/{taskID}/restore": map[string]interface{}{
+					"parameters": []map[string]interface{}{
+						{"name": "taskID", "in": "path", "required": true, "description": "ID of the deleted task", "schema": map[string]string{"type": "string"}},
+					},
+					"post": map[string]interface{}{
+						"summary":     "Restore a deleted task",
+						"description": "Moves a task out of the trash and back into its list",
+						"operationId": "restoreTask",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Task restored",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+									},
+								},
+							},
+							"404": map[string]interface{}{
+								"description": "Task not found in trash",
+							},
+							"409": map[string]interface{}{
+								"description": "A task with the same ID already exists",
+							},
+						},
+					},
+				},
+				"/api/export": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Export all tasks",
+						"description": "Exports all lists and tasks in the format chosen by ?format= or, failing that, the Accept header; markdown when neither states a preference",
+						"operationId": "exportTasks",
+						"parameters": []map[string]interface{}{
+							{"name": "format", "in": "query", "description": "Export format, overriding the Accept header", "schema": map[string]interface{}{"type": "string", "enum": []string{"markdown", "csv", "ics", "json"}}},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+								"content": map[string]interface{}{
+									"text/markdown": map[string]interface{}{
+										"schema": map[string]string{"type": "string"},
+									},
+									"text/csv": map[string]interface{}{
+										"schema": map[string]string{"type": "string"},
+									},
+									"text/calendar": map[string]interface{}{
+										"schema": map[string]string{"type": "string"},
+									},
+									"application/json": map[string]interface{}{
+										"schema": map[string]string{"$ref": "#/components/schemas/FullExport"},
+									},
+								},
+							},
+							"400": map[string]interface{}{
+								"description": "Unknown format",
+							},
+							"406": map[string]interface{}{
+								"description": "The Accept header rules out every export format",
+							},
+						},
+					},
+				},
+				"/api/backup": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Download a backup",
+						"description": "Streams a zip archive of all data: the data directory for the file store, or a database snapshot and attachment files for SQLite",
+						"operationId": "backup",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Backup archive",
+								"content": map[string]interface{}{
+									"application/zip": map[string]interface{}{
+										"schema": map[string]string{"type": "string", "format": "binary"},
+									},
+								},
+							},
+						},
+					},
+				},
+				"/api/restore": map[string]interface{}{
+					"post": map[string]interface{}{
+						"summary":     "Restore a backup",
+						"description": "Replaces all data with a backup written by /api/backup for the same storage backend. The archive is checked before anything is replaced; backups from older releases are migrated.",
+						"operationId": "restore",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"application/zip": map[string]interface{}{
+									"schema": map[string]string{"type": "string", "format": "binary"},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Backup restored; the body has the number of lists and tasks",
+								"content": map[string]interface{}{
+									"application/json": map[string]interface{}{
+										"schema": map[string]interface{}{
+											"type": "object",
+											"properties": map[string]interface{}{
+												"lists": map[string]string{"type": "integer"},
+												"tasks": map[string]string{"type": "integer"},
+											},
+										},
+									},
+								},
+							},
+							"400": map[string]interface{}{
+								"description": "Not a zip archive, or not a backup this server can restore",
+							},
+							"413": map[string]interface{}{
+								"description": "Archive is too large",
+							},
+						},
+					},
+				},
+				"/api/import/markdown": map[string]interface{}{
+					"post": map[string]interface{}{
+						"summary":     "Import from markdown",
+						"description": "Recreates lists and tasks from markdown in the format produced by /api/export. Every list is created as a new list; lines that cannot be parsed are skipped and counted",
+						"operationId": "importMarkdown",
+						"requestBody": map[string]interface{}{
+							"required": true,
+							"content": map[string]interface{}{
+								"text/markdown": map[string]interface{}{
+									"schema": map[string]string{"type": "string"},
+								},
+							},
+						},
+						"responses": map[string]interface{}{
+							"201": map[string]interface{}{
+								"description": "Lists imported; the body has the created lists, the task count and the number of skipped lines",
+							},
+							"400": map[string]interface{}{
+								"description": "No lists found in the markdown",
+							},
+							"409": map[string]interface{}{
+								"description": "Importing a list would exceed the task limit",
+							},
+							"413": map[string]interface{}{
+								"description": "Markdown is too large",
+							},
 						},
 					},
+				},
+				"/api/import/json": map[string]interface{}{
 					"post": map[string]interface{}{
-						"summary":     "Create a task in a list",
-						"description": "Creates a new task in the specified list",
-						"operationId": "createTask",
+						"summary":     "Import from JSON",
+						"description": "Recreates lists and tasks from the document produced by /api/export/json, keeping their IDs and stored fields; creation times and versions start afresh. Lists and tasks are checked as on create, and nothing is imported unless every list and task passes",
+						"operationId": "importJSON",
 						"requestBody": map[string]interface{}{
 							"required": true,
 							"content": map[string]interface{}{
 								"application/json": map[string]interface{}{
-									"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+									"schema": map[string]string{"$ref": "#/components/schemas/FullExport"},
 								},
 							},
 						},
 						"responses": map[string]interface{}{
 							"201": map[string]interface{}{
-								"description": "Task created",
+								"description": "Lists imported; the body has the created lists and the task count",
+							},
+							"400": map[string]interface{}{
+								"description": "Invalid JSON, no lists, or an invalid or repeated ID",
+							},
+							"409": map[string]interface{}{
+								"description": "A list or task ID is already taken, or importing would exceed the task limit",
+							},
+							"413": map[string]interface{}{
+								"description": "JSON is too large",
+							},
+						},
+					},
+				},
+				"/api/lists/{listID}/export": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Export a list to markdown",
+						"description": "Exports one list's tasks to markdown, in the same format as /api/export",
+						"operationId": "exportListMarkdown",
+						"parameters": []map[string]interface{}{
+							{"name": "listID", "in": "path", "required": true, "description": "ID of the list", "schema": map[string]string{"type": "string"}},
+						},
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
 								"content": map[string]interface{}{
-									"application/json": map[string]interface{}{
-										"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+									"text/markdown": map[string]interface{}{
+										"schema": map[string]string{"type": "string"},
 									},
 								},
 							},
+							"404": map[string]interface{}{
+								"description": "List not found",
+							},
 						},
 					},
 				},
-				"/api/tasks": map[string]interface{}{
-					"get": map[string]interface{}{
-						"summary":     "Get all tasks",
-						"description": "Returns all tasks across all lists",
-						"operationId": "getAllTasks",
+				"/api/lists/{listID}/archive-done": map[string]interface{}{
+					"post": map[string]interface{}{
+						"summary":     "Archive done tasks",
+						"description": "Archives every done task in the list. Archived tasks are left out of task listings, search and the web UI unless include_archived=true, but still count in reports and stats. PATCH archived to false to restore one.",
+						"operationId": "archiveDoneTasks",
+						"parameters": []map[string]interface{}{
+							{"name": "listID", "in": "path", "required": true, "description": "ID of the list", "schema": map[string]string{"type": "string"}},
+						},
 						"responses": map[string]interface{}{
 							"200": map[string]interface{}{
-								"description": "Successful operation",
+								"description": "The number of tasks archived and the archived tasks",
 								"content": map[string]interface{}{
 									"application/json": map[string]interface{}{
 										"schema": map[string]interface{}{
-											"type":  "array",
-											"items": map[string]string{"$ref": "#/components/schemas/Task"},
+											"type": "object",
+											"properties": map[string]interface{}{
+												"archived": map[string]string{"type": "integer"},
+												"tasks": map[string]interface{}{
+													"type":  "array",
+													"items": map[string]string{"$ref": "#/components/schemas/Task"},
+												},
+											},
 										},
 									},
 								},
 							},
+							"404": map[string]interface{}{
+								"description": "List not found",
+							},
 						},
 					},
 				},
-				"/api/tasks/{listID}/{taskID}": map[string]interface{}{
-					"parameters": []map[string]interface{}{
-						{
-							"name":        "listID",
-							"in":          "path",
-							"required":    true,
-							"description": "ID of the task list",
-							"schema":      map[string]string{"type": "string"},
-						},
-						{
-							"name":        "taskID",
-							"in":          "path",
-							"required":    true,
-							"description": "ID of the task",
-							"schema":      map[string]string{"type": "string"},
-						},
-					},
+				"/api/export/csv": map[string]interface{}{
 					"get": map[string]interface{}{
-						"summary":     "Get a task",
-						"description": "Returns a task by ID",
-						"operationId": "getTask",
+						"summary":     "Export to CSV",
+						"description": "Exports all tasks to CSV, one row per task; same as /api/export?format=csv",
+						"operationId": "exportCSV",
 						"responses": map[string]interface{}{
 							"200": map[string]interface{}{
 								"description": "Successful operation",
 								"content": map[string]interface{}{
-									"application/json": map[string]interface{}{
-										"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+									"text/csv": map[string]interface{}{
+										"schema": map[string]string{"type": "string"},
 									},
 								},
 							},
-							"404": map[string]interface{}{
-								"description": "Task not found",
-							},
 						},
 					},
-					"put": map[string]interface{}{
-						"summary":     "Update a task",
-						"description": "Updates a task by ID",
-						"operationId": "updateTask",
-						"requestBody": map[string]interface{}{
-							"required": true,
-							"content": map[string]interface{}{
-								"application/json": map[string]interface{}{
-									"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+				},
+				"/api/export/ics": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Export to iCalendar",
+						"description": "Exports tasks with due dates as iCalendar events; same as /api/export?format=ics",
+						"operationId": "exportICS",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
+								"content": map[string]interface{}{
+									"text/calendar": map[string]interface{}{
+										"schema": map[string]string{"type": "string"},
+									},
 								},
 							},
 						},
+					},
+				},
+				"/api/export/json": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Export everything as JSON",
+						"description": "Streams every list with its tasks nested under tasks, and their notes and subtasks nested inside them, as {\"exported_at\": ..., \"lists\": [...]}. Tasks are written as stored, without computed fields such as progress. This is the backup-as-data format, also returned by /api/export?format=json, and what /api/import/json reads.",
+						"operationId": "exportFullJSON",
 						"responses": map[string]interface{}{
 							"200": map[string]interface{}{
-								"description": "Task updated",
+								"description": "Successful operation",
 								"content": map[string]interface{}{
 									"application/json": map[string]interface{}{
-										"schema": map[string]string{"$ref": "#/components/schemas/Task"},
+										"schema": map[string]string{"$ref": "#/components/schemas/FullExport"},
 									},
 								},
 							},
-							"404": map[string]interface{}{
-								"description": "Task not found",
-							},
 						},
 					},
-					"delete": map[string]interface{}{
-						"summary":     "Delete a task",
-						"description": "Deletes a task by ID",
-						"operationId": "deleteTask",
+				},
+				"/api/routes": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "List routes",
+						"description": "Returns every method and path registered on the router",
+						"operationId": "listRoutes",
 						"responses": map[string]interface{}{
-							"204": map[string]interface{}{
-								"description": "Task deleted",
+							"200": map[string]interface{}{
+								"description": "Successful operation",
 							},
-							"404": map[string]interface{}{
-								"description": "Task not found",
+						},
+					},
+				},
+				"/api/settings": map[string]interface{}{
+					"get": map[string]interface{}{
+						"summary":     "Get settings",
+						"description": "Returns the effective server settings, such as pagination limits",
+						"operationId": "getSettings",
+						"responses": map[string]interface{}{
+							"200": map[string]interface{}{
+								"description": "Successful operation",
 							},
 						},
 					},
 				},
-				"/api/export": map[string]interface{}{
+				"/api/docs": map[string]interface{}{
 					"get": map[string]interface{}{
-						"summary":     "Export to markdown",
-						"description": "Exports all tasks to markdown format",
-						"operationId": "exportMarkdown",
+						"summary":     "API docs page",
+						"description": "Returns an HTML page rendering this specification",
+						"operationId": "getAPIDocs",
 						"responses": map[string]interface{}{
 							"200": map[string]interface{}{
 								"description": "Successful operation",
 								"content": map[string]interface{}{
-									"text/markdown": map[string]interface{}{
+									"text/html": map[string]interface{}{
 										"schema": map[string]string{"type": "string"},
 									},
 								},
@@ -1555,7 +3765,8 @@ func HandleOpenAPISpec(store *storage.FileStore) http.HandlerFunc {
 						"properties": map[string]interface{}{
 							"id": map[string]string{
 								"type":        "string",
-								"description": "Unique task identifier",
+								"description": "Unique task identifier; generated when left out. Letters, digits, dashes and underscores only",
+								"pattern":     "^[A-Za-z0-9_-]{1,128}$",
 							},
 							"title": map[string]string{
 								"type":        "string",
@@ -1579,12 +3790,104 @@ func HandleOpenAPISpec(store *storage.FileStore) http.HandlerFunc {
 								"format":      "date-time",
 								"description": "Time when the current state was set",
 							},
+							"completed_at": map[string]interface{}{
+								"type":        "string",
+								"format":      "date-time",
+								"description": "When the task last moved into done, cleared when it leaves done; maintained by the server",
+								"nullable":    true,
+							},
+							"archived": map[string]interface{}{
+								"type":        "boolean",
+								"description": "Whether the task has been archived and is hidden from default task queries",
+							},
+							"archived_at": map[string]interface{}{
+								"type":        "string",
+								"format":      "date-time",
+								"description": "Time when the task was archived; maintained by the server",
+								"nullable":    true,
+							},
+							"reminder_sent": map[string]interface{}{
+								"type":        "string",
+								"format":      "date-time",
+								"description": "When a due-date reminder was sent for the current due date; maintained by the server and cleared when the due date changes",
+								"nullable":    true,
+							},
+							"progress": map[string]interface{}{
+								"type":        "number",
+								"description": "Fraction of subtasks that are done, from 0 to 1; null for tasks without subtasks. Computed by the server and ignored on input",
+								"nullable":    true,
+								"readOnly":    true,
+							},
+							"state_seconds": map[string]interface{}{
+								"type":                 "object",
+								"description":          "Seconds spent in each earlier state, excluding the current one; maintained by the server",
+								"additionalProperties": map[string]string{"type": "integer"},
+							},
+							"start_date": map[string]interface{}{
+								"type":        "string",
+								"format":      "date-time",
+								"description": "When the task becomes actionable; must not be after due_date. Send null in an update to clear it",
+								"nullable":    true,
+							},
 							"due_date": map[string]interface{}{
 								"type":        "string",
 								"format":      "date-time",
-								"description": "Task due date",
+								"description": "Task due date; send null in an update to clear it",
+								"nullable":    true,
+							},
+							"priority": map[string]interface{}{
+								"type":        "string",
+								"description": "Task priority (defaults to medium on create)",
+								"enum":        []string{"low", "medium", "high", "urgent"},
+							},
+							"flagged": map[string]interface{}{
+								"type":        "boolean",
+								"description": "Marked for emphasis; flagged tasks are highlighted in the list and kanban views",
+							},
+							"assignee": map[string]string{
+								"type":        "string",
+								"description": "Person responsible for the task",
+							},
+							"blocked_reason": map[string]string{
+								"type":        "string",
+								"description": "Why the task is blocked; required when state is blocked and cleared otherwise",
+							},
+							"deleted_at": map[string]interface{}{
+								"type":        "string",
+								"format":      "date-time",
+								"description": "When the task was deleted; only set on tasks in the trash",
 								"nullable":    true,
 							},
+							"depends_on": map[string]interface{}{
+								"type":        "array",
+								"description": "IDs of tasks that must be done before this task can move to in_progress",
+								"items":       map[string]string{"type": "string"},
+							},
+							"attachments": map[string]interface{}{
+								"type":        "array",
+								"description": "Files attached to the task; managed through the attachments endpoints",
+								"items":       map[string]string{"$ref": "#/components/schemas/Attachment"},
+							},
+							"history": map[string]interface{}{
+								"type":        "array",
+								"description": "Field changes recorded by the server, oldest first; read-only",
+								"items":       map[string]string{"$ref": "#/components/schemas/HistoryEntry"},
+							},
+							"tags": map[string]interface{}{
+								"type":        "array",
+								"description": "Task tags",
+								"items":       map[string]string{"type": "string"},
+							},
+							"estimate_minutes": map[string]interface{}{
+								"type":        "integer",
+								"minimum":     0,
+								"description": "Planned effort in minutes; negative values are rejected with 400",
+							},
+							"spent_minutes": map[string]interface{}{
+								"type":        "integer",
+								"minimum":     0,
+								"description": "Effort spent so far in minutes; negative values are rejected with 400",
+							},
 							"created_at": map[string]string{
 								"type":        "string",
 								"format":      "date-time",
@@ -1595,19 +3898,96 @@ func HandleOpenAPISpec(store *storage.FileStore) http.HandlerFunc {
 								"format":      "date-time",
 								"description": "Last update time",
 							},
+							"version": map[string]string{
+								"type":        "integer",
+								"description": "Incremented on every save; send it back on update to reject the update if someone else saved first",
+							},
 							"notes": map[string]interface{}{
 								"type":        "array",
 								"description": "Task notes",
 								"items":       map[string]string{"$ref": "#/components/schemas/Note"},
 							},
+							"comments": map[string]interface{}{
+								"type":        "array",
+								"description": "Comments from collaborators, oldest first",
+								"items":       map[string]string{"$ref": "#/components/schemas/Comment"},
+							},
 							"sub_tasks": map[string]interface{}{
 								"type":        "array",
 								"description": "Sub-tasks",
 								"items":       map[string]string{"$ref": "#/components/schemas/Task"},
 							},
+							"position": map[string]string{
+								"type":        "integer",
+								"description": "Manual ordering within the list",
+							},
+							"parent_id": map[string]string{
+								"type":        "string",
+								"description": "ID of the parent task (only set on flattened subtasks)",
+							},
+							"recurrence": map[string]interface{}{
+								"type":        "string",
+								"description": "Recurrence interval; completing a recurring task returns it to todo and advances its due date",
+								"enum":        []string{"daily", "weekly", "monthly"},
+							},
+							"completion_history": map[string]interface{}{
+								"type":        "array",
+								"description": "When each occurrence of a recurring task was completed",
+								"items":       map[string]string{"type": "string", "format": "date-time"},
+							},
 						},
 						"required": []string{"id", "title", "list_id", "state", "state_time", "created_at", "updated_at"},
 					},
+					"Attachment": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"id": map[string]string{
+								"type":        "string",
+								"description": "Attachment identifier",
+							},
+							"filename": map[string]string{
+								"type":        "string",
+								"description": "Original filename",
+							},
+							"size": map[string]string{
+								"type":        "integer",
+								"description": "Size in bytes",
+							},
+							"content_type": map[string]string{
+								"type":        "string",
+								"description": "MIME type given at upload",
+							},
+							"uploaded_at": map[string]string{
+								"type":        "string",
+								"format":      "date-time",
+								"description": "Upload time",
+							},
+						},
+						"required": []string{"id", "filename", "size", "content_type", "uploaded_at"},
+					},
+					"HistoryEntry": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"field": map[string]string{
+								"type":        "string",
+								"description": "Name of the changed field, e.g. state or due_date",
+							},
+							"old_value": map[string]string{
+								"type":        "string",
+								"description": "Value before the change",
+							},
+							"new_value": map[string]string{
+								"type":        "string",
+								"description": "Value after the change",
+							},
+							"changed_at": map[string]string{
+								"type":        "string",
+								"format":      "date-time",
+								"description": "When the change was made",
+							},
+						},
+						"required": []string{"field", "old_value", "new_value", "changed_at"},
+					},
 					"Note": map[string]interface{}{
 						"type": "object",
 						"properties": map[string]interface{}{
@@ -1632,12 +4012,59 @@ func HandleOpenAPISpec(store *storage.FileStore) http.HandlerFunc {
 						},
 						"required": []string{"id", "content", "created_at", "updated_at"},
 					},
+					"Comment": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"id": map[string]string{
+								"type":        "string",
+								"description": "Comment identifier",
+							},
+							"author": map[string]string{
+								"type":        "string",
+								"description": "Who wrote the comment",
+							},
+							"body": map[string]string{
+								"type":        "string",
+								"description": "Comment text",
+							},
+							"created_at": map[string]string{
+								"type":        "string",
+								"format":      "date-time",
+								"description": "Creation time",
+							},
+						},
+						"required": []string{"id", "author", "body", "created_at"},
+					},
+					"FullExport": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"exported_at": map[string]string{"type": "string", "format": "date-time"},
+							"lists": map[string]interface{}{
+								"type": "array",
+								"items": map[string]interface{}{
+									"allOf": []interface{}{
+										map[string]string{"$ref": "#/components/schemas/TaskList"},
+										map[string]interface{}{
+											"type": "object",
+											"properties": map[string]interface{}{
+												"tasks": map[string]interface{}{
+													"type":  "array",
+													"items": map[string]string{"$ref": "#/components/schemas/Task"},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
 					"TaskList": map[string]interface{}{
 						"type": "object",
 						"properties": map[string]interface{}{
 							"id": map[string]string{
 								"type":        "string",
-								"description": "Task list identifier",
+								"description": "Task list identifier; generated when left out. Letters, digits, dashes and underscores only",
+								"pattern":     "^[A-Za-z0-9_-]{1,128}$",
 							},
 							"name": map[string]string{
 								"type":        "string",
@@ -1647,6 +4074,47 @@ func HandleOpenAPISpec(store *storage.FileStore) http.HandlerFunc {
 								"type":        "string",
 								"description": "Task list description",
 							},
+							"color": map[string]string{
+								"type":        "string",
+								"description": "Hex color (#rgb or #rrggbb) shown next to the list in the UI; stored in lower case",
+								"pattern":     "^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$",
+							},
+							"icon": map[string]interface{}{
+								"type":        "string",
+								"description": "Short label or emoji shown before the list name in the UI",
+								"maxLength":   maxListIconLength,
+							},
+							"description_template": map[string]string{
+								"type":        "string",
+								"description": "Go text/template used as the description of tasks created without one, e.g. \"Checklist for {{.Title}}\"",
+							},
+							"states": map[string]interface{}{
+								"type":        "array",
+								"items":       map[string]string{"type": "string"},
+								"description": "Custom workflow states in column order; tasks may only use these states. Defaults to todo, in_progress, blocked, done",
+							},
+							"error": map[string]string{
+								"type":        "string",
+								"description": "Read error, only set on placeholders returned with include_errors=true",
+							},
+							"order": map[string]string{
+								"type":        "integer",
+								"description": "Display position set by reordering; lists without one come after ordered lists",
+							},
+							"auto_complete": map[string]string{
+								"type":        "boolean",
+								"description": "Move a task to done once all of its subtasks are done",
+							},
+							"archived": map[string]string{
+								"type":        "boolean",
+								"description": "Whether the list has been archived",
+							},
+							"archived_at": map[string]interface{}{
+								"type":        "string",
+								"format":      "date-time",
+								"description": "Time when the list was archived",
+								"nullable":    true,
+							},
 							"created_at": map[string]string{
 								"type":        "string",
 								"format":      "date-time",
@@ -1660,10 +4128,49 @@ func HandleOpenAPISpec(store *storage.FileStore) http.HandlerFunc {
 						},
 						"required": []string{"id", "name", "created_at", "updated_at"},
 					},
+					"SavedFilter": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"name": map[string]string{
+								"type":        "string",
+								"description": "Filter name",
+							},
+							"criteria": map[string]interface{}{
+								"type":        "object",
+								"description": "Filter criteria, using the same keys as GET /api/tasks/filter",
+								"properties": map[string]interface{}{
+									"state":      map[string]string{"type": "string"},
+									"tag":        map[string]string{"type": "string"},
+									"assignee":   map[string]string{"type": "string"},
+									"priority":   map[string]string{"type": "string"},
+									"due_before": map[string]string{"type": "string"},
+									"has_due":    map[string]string{"type": "string"},
+									"q":          map[string]string{"type": "string"},
+								},
+							},
+							"created_at": map[string]string{
+								"type":        "string",
+								"format":      "date-time",
+								"description": "Creation time",
+							},
+							"updated_at": map[string]string{
+								"type":        "string",
+								"format":      "date-time",
+								"description": "Last update time",
+							},
+						},
+						"required": []string{"name", "criteria"},
+					},
 				},
 			},
 		}
 
+		if err := syncSpecWithRoutes(spec["paths"].(map[string]interface{}), routes); err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to generate OpenAPI spec")
+			return
+		}
+		completeSchemas(spec["components"].(map[string]interface{})["schemas"].(map[string]interface{}))
+
 		// Convert to JSON
 		jsonData, err := json.MarshalIndent(spec, "", "  ")
 		if err != nil {
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// doJSON sends a request with a JSON body to router and returns the recorder
+func doJSON(t *testing.T, router http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTaskPriorityIsValidated(t *testing.T) {
+	router := newTestRouter(t)
+	if rec := doJSON(t, router, http.MethodPost, "/api/lists", `{"id":"list","name":"List"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("creating list: status %d: %s", rec.Code, rec.Body)
+	}
+
+	if rec := doJSON(t, router, http.MethodPost, "/api/lists/list/tasks", `{"id":"bad","title":"Bad","priority":"whenever"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("create with unknown priority: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := doJSON(t, router, http.MethodPost, "/api/lists/list/tasks", `{"id":"task","title":"Task","priority":"high"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("create with priority high: status %d: %s", rec.Code, rec.Body)
+	}
+	if rec := doJSON(t, router, http.MethodPatch, "/api/tasks/list/task", `{"priority":"whenever"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("patch with unknown priority: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestPatchRejectsNullListID(t *testing.T) {
+	router := newTestRouter(t)
+	if rec := doJSON(t, router, http.MethodPost, "/api/lists", `{"id":"list","name":"List"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("creating list: status %d: %s", rec.Code, rec.Body)
+	}
+	if rec := doJSON(t, router, http.MethodPost, "/api/lists/list/tasks", `{"id":"task","title":"Task"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("creating task: status %d: %s", rec.Code, rec.Body)
+	}
+
+	rec := doJSON(t, router, http.MethodPatch, "/api/tasks/list/task", `{"list_id":null}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "list_id") {
+		t.Errorf("patch with null list_id: status %d: %s, want %d naming list_id", rec.Code, rec.Body, http.StatusBadRequest)
+	}
+	if rec := doJSON(t, router, http.MethodGet, "/api/tasks/list/task", ""); rec.Code != http.StatusOK {
+		t.Errorf("task after rejected patch: status %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestPutCreateRejectsStartAfterDue(t *testing.T) {
+	router := newTestRouter(t)
+	if rec := doJSON(t, router, http.MethodPost, "/api/lists", `{"id":"list","name":"List"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("creating list: status %d: %s", rec.Code, rec.Body)
+	}
+
+	rec := doJSON(t, router, http.MethodPut, "/api/tasks/list/task", `{"title":"Task","start_date":"2030-02-01T00:00:00Z","due_date":"2030-01-01T00:00:00Z"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("put-create with start after due: status %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body)
+	}
+	if rec := doJSON(t, router, http.MethodGet, "/api/tasks/list/task", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("task after rejected put-create: status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestPutCreateRejectsNegativeEstimate(t *testing.T) {
+	router := newTestRouter(t)
+	if rec := doJSON(t, router, http.MethodPost, "/api/lists", `{"id":"list","name":"List"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("creating list: status %d: %s", rec.Code, rec.Body)
+	}
+
+	rec := doJSON(t, router, http.MethodPut, "/api/tasks/list/task", `{"title":"Task","estimate_minutes":-5}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("put-create with negative estimate: status %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body)
+	}
+}
+
+func TestPutCreateReturnsCreatedWithLocation(t *testing.T) {
+	router := newTestRouter(t)
+	if rec := doJSON(t, router, http.MethodPost, "/api/lists", `{"id":"list","name":"List"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("creating list: status %d: %s", rec.Code, rec.Body)
+	}
+
+	rec := doJSON(t, router, http.MethodPut, "/api/tasks/list/task", `{"title":"Task"}`)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("put-create: status %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if got, want := rec.Header().Get("Location"), "/api/tasks/list/task"; got != want {
+		t.Errorf("put-create Location %q, want %q", got, want)
+	}
+
+	rec = doJSON(t, router, http.MethodPut, "/api/tasks/list/task", `{"title":"Renamed"}`)
+	if rec.Code != http.StatusOK || rec.Header().Get("Location") != "" {
+		t.Errorf("put-update: status %d with Location %q, want %d without one", rec.Code, rec.Header().Get("Location"), http.StatusOK)
+	}
+}
+
+func TestPutCreateDefaultsToMediumPriority(t *testing.T) {
+	router := newTestRouter(t)
+	if rec := doJSON(t, router, http.MethodPost, "/api/lists", `{"id":"list","name":"List"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("creating list: status %d: %s", rec.Code, rec.Body)
+	}
+
+	rec := doJSON(t, router, http.MethodPut, "/api/tasks/list/task", `{"title":"Task"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("put-create: status %d: %s", rec.Code, rec.Body)
+	}
+	var task models.Task
+	if err := json.Unmarshal(rec.Body.Bytes(), &task); err != nil {
+		t.Fatalf("decoding task: %v", err)
+	}
+	if task.Priority != models.TaskPriorityMedium {
+		t.Errorf("priority %q, want %q", task.Priority, models.TaskPriorityMedium)
+	}
+}
+
+func TestPutCreateAppliesDescriptionTemplate(t *testing.T) {
+	router := newTestRouter(t)
+	if rec := doJSON(t, router, http.MethodPost, "/api/lists", `{"id":"list","name":"List","description_template":"Steps for {{.Title}}"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("creating list: status %d: %s", rec.Code, rec.Body)
+	}
+
+	rec := doJSON(t, router, http.MethodPut, "/api/tasks/list/task", `{"title":"Deploy"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("put-create: status %d: %s", rec.Code, rec.Body)
+	}
+	var task models.Task
+	if err := json.Unmarshal(rec.Body.Bytes(), &task); err != nil {
+		t.Fatalf("decoding task: %v", err)
+	}
+	if want := "Steps for Deploy"; task.Description != want {
+		t.Errorf("description %q, want %q", task.Description, want)
+	}
+}
+
+func TestMoveIntoFullListConflicts(t *testing.T) {
+	store := newTestStore(t)
+	store.SetTaskLimits(storage.TaskLimits{PerList: 1})
+	router := NewRouter(store, embed.FS{})
+	for _, list := range []string{"a", "b"} {
+		if rec := doJSON(t, router, http.MethodPost, "/api/lists", `{"id":"`+list+`","name":"`+list+`"}`); rec.Code != http.StatusCreated {
+			t.Fatalf("creating list %s: status %d: %s", list, rec.Code, rec.Body)
+		}
+		if rec := doJSON(t, router, http.MethodPost, "/api/lists/"+list+"/tasks", `{"id":"in-`+list+`","title":"Task"}`); rec.Code != http.StatusCreated {
+			t.Fatalf("creating task in %s: status %d: %s", list, rec.Code, rec.Body)
+		}
+	}
+
+	if rec := doJSON(t, router, http.MethodPost, "/api/tasks/a/in-a/move", `{"target_list_id":"b"}`); rec.Code != http.StatusConflict {
+		t.Errorf("move into a full list: status %d, want %d: %s", rec.Code, http.StatusConflict, rec.Body)
+	}
+	if rec := doJSON(t, router, http.MethodPut, "/api/tasks/a/in-a", `{"title":"Task","list_id":"b"}`); rec.Code != http.StatusConflict {
+		t.Errorf("update moving into a full list: status %d, want %d: %s", rec.Code, http.StatusConflict, rec.Body)
+	}
+}
+
+func TestCreateListRejectsTakenID(t *testing.T) {
+	router := newTestRouter(t)
+	if rec := doJSON(t, router, http.MethodPost, "/api/lists", `{"id":"list","name":"List"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("creating list: status %d: %s", rec.Code, rec.Body)
+	}
+	if rec := doJSON(t, router, http.MethodPost, "/api/lists", `{"id":"list","name":"Other"}`); rec.Code != http.StatusConflict {
+		t.Errorf("creating a list with a taken ID: status %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Health checks
+
+// HandleHealthz reports that the process is up
+func HandleHealthz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
+
+// HandleReadyz reports whether the store can serve requests, returning 503
+// while its data directory or database is unavailable
+func HandleReadyz(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := store.Ready(); err != nil {
+			log.Printf("Readiness check failed: %v", err)
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "unavailable"})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
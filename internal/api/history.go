@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Task history
+
+// HandleGetTaskHistory returns the recorded field changes of a task, oldest
+// first
+func HandleGetTaskHistory(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		taskID := chi.URLParam(r, "taskID")
+		if listID == "" || taskID == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Missing list ID or task ID")
+			return
+		}
+
+		task, err := store.GetTask(listID, taskID)
+		if err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
+
+		history := task.History
+		if history == nil {
+			history = []models.HistoryEntry{}
+		}
+		writeJSON(w, http.StatusOK, history)
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// iCalendar export
+
+// icalTimeFormat is the RFC 5545 UTC date-time form
+const icalTimeFormat = "20060102T150405Z"
+
+// icalEscaper escapes TEXT values as required by RFC 5545 section 3.3.11
+var icalEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// writeICalLine writes a content line, folding it so no physical line is
+// longer than 75 octets. Folds never split a multi-byte UTF-8 sequence.
+func writeICalLine(buf *bytes.Buffer, line string) {
+	const maxLen = 75
+	for len(line) > maxLen {
+		cut := maxLen
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+// writeICalEvent writes a VEVENT for a task with a due date
+func writeICalEvent(buf *bytes.Buffer, task *models.Task, list models.TaskList, stamp time.Time) {
+	writeICalLine(buf, "BEGIN:VEVENT")
+	writeICalLine(buf, "UID:"+task.ID+"@tasks")
+	writeICalLine(buf, "DTSTAMP:"+stamp.UTC().Format(icalTimeFormat))
+	writeICalLine(buf, "DTSTART:"+task.DueDate.UTC().Format(icalTimeFormat))
+	writeICalLine(buf, "SUMMARY:"+icalEscaper.Replace(task.Title))
+	if task.Description != "" {
+		writeICalLine(buf, "DESCRIPTION:"+icalEscaper.Replace(task.Description))
+	}
+	writeICalLine(buf, "CATEGORIES:"+icalEscaper.Replace(list.Name))
+	writeICalLine(buf, "LAST-MODIFIED:"+task.UpdatedAt.UTC().Format(icalTimeFormat))
+	writeICalLine(buf, "END:VEVENT")
+}
+
+// writeICSExport writes tasks with due dates as an iCalendar document. Each
+// event's UID is derived from the task ID so calendar apps update existing
+// events on re-import instead of duplicating them.
+func writeICSExport(buf *bytes.Buffer, lists []models.TaskList, store storage.TaskStore) error {
+	now := time.Now()
+	writeICalLine(buf, "BEGIN:VCALENDAR")
+	writeICalLine(buf, "VERSION:2.0")
+	writeICalLine(buf, "PRODID:-//jbutlerdev//tasks//EN")
+	writeICalLine(buf, "CALSCALE:GREGORIAN")
+
+	for _, list := range lists {
+		tasks, err := store.GetTasksForList(list.ID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve tasks for list %s: %w", list.ID, err)
+		}
+
+		for i := range tasks {
+			if tasks[i].DueDate == nil {
+				continue
+			}
+			writeICalEvent(buf, &tasks[i], list, now)
+		}
+	}
+
+	writeICalLine(buf, "END:VCALENDAR")
+	return nil
+}
@@ -0,0 +1,483 @@
+package api
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Markdown import
+
+var (
+	// markdownTaskPattern matches "- **title**: description (Due: 2006-01-02)"
+	// lines, where the description and due date are optional
+	markdownTaskPattern = regexp.MustCompile(`^- \*\*(.+?)\*\*(?:: (.*?))?(?: \(Due: (\d{4}-\d{2}-\d{2})\))?$`)
+	// markdownCommentPattern matches "author (2006-01-02 15:04): body"
+	markdownCommentPattern = regexp.MustCompile(`^(.+?) \((\d{4}-\d{2}-\d{2} \d{2}:\d{2})\): (.*)$`)
+)
+
+// importedList is a list parsed from markdown along with its tasks
+type importedList struct {
+	list   models.TaskList
+	tasks  []models.Task
+	states []models.TaskState // state headings in the order they appeared
+}
+
+// importResult is the response of HandleImportMarkdown
+type importResult struct {
+	Lists   []models.TaskList `json:"lists"`
+	Tasks   int               `json:"tasks"`
+	Skipped int               `json:"skipped"`
+}
+
+// titleToState reverses stateToTitle. Headings that aren't a default state
+// are taken as the name of a custom state.
+func titleToState(title string) models.TaskState {
+	for _, state := range models.DefaultTaskStates {
+		if stateToTitle(state) == title {
+			return state
+		}
+	}
+	return models.TaskState(title)
+}
+
+// markdownParser holds the position within the document while parsing the
+// markdown export
+type markdownParser struct {
+	lists   []*importedList
+	list    *importedList
+	state   models.TaskState
+	task    *models.Task
+	section string // "notes", "comments" or "subtasks" under the current task
+	skipped int
+	now     time.Time
+}
+
+// parseLine interprets one line of the markdown export, counting lines it
+// doesn't understand as skipped
+func (p *markdownParser) parseLine(line string) {
+	line = strings.TrimRight(line, " \t\r")
+	switch {
+	case strings.TrimSpace(line) == "", line == "# Task Lists":
+		return
+	case strings.HasPrefix(line, "## "):
+		p.startList(strings.TrimSpace(line[3:]))
+		return
+	}
+
+	if p.list == nil {
+		p.skipped++
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(line, "### "):
+		p.state = titleToState(strings.TrimSpace(line[4:]))
+		p.list.states = append(p.list.states, p.state)
+		p.task = nil
+	case strings.HasPrefix(line, "- **"):
+		p.addTask(line)
+	case strings.HasPrefix(line, "  - "):
+		p.parseTaskDetail(line[4:])
+	case strings.HasPrefix(line, "    - "):
+		p.parseSectionItem(line[6:])
+	case p.state == "" && p.task == nil && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "-"):
+		// Text between the list heading and its first state is the description
+		if p.list.list.Description != "" {
+			p.list.list.Description += "\n"
+		}
+		p.list.list.Description += line
+	default:
+		p.skipped++
+	}
+}
+
+// startList begins a new list. Lists without a name are skipped along with
+// their contents.
+func (p *markdownParser) startList(name string) {
+	p.state = ""
+	p.task = nil
+	if name == "" {
+		p.list = nil
+		p.skipped++
+		return
+	}
+	p.list = &importedList{list: models.TaskList{ID: uuid.New().String(), Name: name}}
+	p.lists = append(p.lists, p.list)
+}
+
+// addTask parses a task line into the current list
+func (p *markdownParser) addTask(line string) {
+	m := markdownTaskPattern.FindStringSubmatch(line)
+	if m == nil || strings.TrimSpace(m[1]) == "" {
+		p.task = nil
+		p.skipped++
+		return
+	}
+
+	task := models.Task{
+		ID:          uuid.New().String(),
+		Title:       strings.TrimSpace(m[1]),
+		Description: m[2],
+		State:       p.state,
+	}
+	if m[3] != "" {
+		due, err := time.Parse(dateLayout, m[3])
+		if err != nil {
+			p.task = nil
+			p.skipped++
+			return
+		}
+		task.DueDate = &due
+	}
+
+	p.list.tasks = append(p.list.tasks, task)
+	p.task = &p.list.tasks[len(p.list.tasks)-1]
+	p.section = ""
+}
+
+// parseTaskDetail handles the "  - " lines under a task: the blocked reason
+// and the headings of its notes, comments and subtasks
+func (p *markdownParser) parseTaskDetail(detail string) {
+	if p.task == nil {
+		p.skipped++
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(detail, "Blocked: "):
+		p.task.BlockedReason = strings.TrimSpace(detail[len("Blocked: "):])
+		p.section = ""
+	case detail == "Notes:":
+		p.section = "notes"
+	case detail == "Comments:":
+		p.section = "comments"
+	case detail == "Subtasks:":
+		p.section = "subtasks"
+	default:
+		p.skipped++
+	}
+}
+
+// parseSectionItem handles a "    - " line in the current task's notes,
+// comments or subtasks
+func (p *markdownParser) parseSectionItem(item string) {
+	if p.task == nil {
+		p.skipped++
+		return
+	}
+
+	switch p.section {
+	case "notes":
+		p.task.Notes = append(p.task.Notes, models.Note{
+			ID:        uuid.New().String(),
+			Content:   item,
+			CreatedAt: p.now,
+			UpdatedAt: p.now,
+		})
+	case "comments":
+		m := markdownCommentPattern.FindStringSubmatch(item)
+		if m == nil {
+			p.skipped++
+			return
+		}
+		createdAt, err := time.ParseInLocation("2006-01-02 15:04", m[2], time.Local)
+		if err != nil {
+			p.skipped++
+			return
+		}
+		p.task.Comments = append(p.task.Comments, models.Comment{
+			ID:        uuid.New().String(),
+			Author:    m[1],
+			Body:      m[3],
+			CreatedAt: createdAt,
+		})
+	case "subtasks":
+		m := markdownTaskPattern.FindStringSubmatch("- " + item)
+		if m == nil || strings.TrimSpace(m[1]) == "" {
+			p.skipped++
+			return
+		}
+		p.task.SubTasks = append(p.task.SubTasks, models.Task{
+			ID:          uuid.New().String(),
+			Title:       strings.TrimSpace(m[1]),
+			Description: m[2],
+			CreatedAt:   p.now,
+			UpdatedAt:   p.now,
+			StateTime:   p.now,
+		})
+	default:
+		p.skipped++
+	}
+}
+
+// finish fills in what can only be known once a list has been read: custom
+// states the list needs and the default state of tasks listed without one
+func (l *importedList) finish() {
+	for _, state := range l.states {
+		if !state.IsValid() {
+			l.list.States = make([]string, len(l.states))
+			for i, state := range l.states {
+				l.list.States[i] = string(state)
+			}
+			break
+		}
+	}
+
+	first := l.list.TaskStates()[0]
+	for i := range l.tasks {
+		task := &l.tasks[i]
+		task.ListID = l.list.ID
+		if task.State == "" {
+			task.State = first
+		}
+		// Blocked tasks need a reason, which older exports may not include
+		if task.State == models.TaskStateBlocked && task.BlockedReason == "" {
+			task.BlockedReason = "Imported from markdown"
+		}
+		if task.State != models.TaskStateBlocked {
+			task.BlockedReason = ""
+		}
+		for j := range task.SubTasks {
+			task.SubTasks[j].ListID = l.list.ID
+			task.SubTasks[j].State = first
+		}
+	}
+}
+
+// HandleImportMarkdown recreates lists and tasks from markdown in the format
+// written by the markdown export. Titles, descriptions, states, due dates,
+// blocked reasons, notes, comments and subtasks are restored; every list is
+// created as a new list. Lines that can't be parsed are skipped and counted
+// in the response.
+func HandleImportMarkdown(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+
+		parser := markdownParser{now: time.Now()}
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(make([]byte, 64*1024), int(maxUploadSize))
+		for scanner.Scan() {
+			parser.parseLine(scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) || errors.Is(err, bufio.ErrTooLong) {
+				writeErrorJSON(w, http.StatusRequestEntityTooLarge, "Markdown is too large")
+				return
+			}
+			writeErrorJSON(w, http.StatusBadRequest, "Failed to read markdown")
+			return
+		}
+
+		if len(parser.lists) == 0 {
+			writeErrorJSON(w, http.StatusBadRequest, "No lists found in markdown")
+			return
+		}
+
+		result := importResult{Lists: make([]models.TaskList, 0, len(parser.lists)), Skipped: parser.skipped}
+		for _, imported := range parser.lists {
+			imported.finish()
+			if err := store.CreateListWithTasks(&imported.list, imported.tasks); err != nil {
+				writeCreateError(w, err, "Failed to import list "+imported.list.Name+": "+err.Error())
+				return
+			}
+			result.Lists = append(result.Lists, imported.list)
+			result.Tasks += len(imported.tasks)
+		}
+
+		writeJSON(w, http.StatusCreated, result)
+	}
+}
+
+// JSON import
+
+// HandleImportJSON recreates lists and tasks from the document written by the
+// full JSON export. List and task IDs are kept, so dependencies between tasks
+// still hold, along with every stored field of the tasks, including notes,
+// comments, subtasks and history; creation times and versions start afresh,
+// as for any new list. Lists and tasks are checked like ones created through
+// the API, and a list or task ID that is already taken is a 409. The lists
+// are created together, so nothing is imported unless all of them are.
+func HandleImportJSON(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+
+		var doc fullExport
+		if err := decodeStrict(r, &doc); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeErrorJSON(w, http.StatusRequestEntityTooLarge, "JSON is too large")
+				return
+			}
+			writeDecodeError(w, err)
+			return
+		}
+		if len(doc.Lists) == 0 {
+			writeErrorJSON(w, http.StatusBadRequest, "No lists found in JSON")
+			return
+		}
+		if status, err := checkImportIDs(store, doc.Lists); err != nil {
+			writeErrorJSON(w, status, err.Error())
+			return
+		}
+		if status, err := checkImportTasks(store, doc.Lists); err != nil {
+			writeErrorJSON(w, status, err.Error())
+			return
+		}
+
+		result := importResult{Lists: make([]models.TaskList, 0, len(doc.Lists))}
+		lists := make([]storage.NewList, len(doc.Lists))
+		for i := range doc.Lists {
+			lists[i] = storage.NewList{List: &doc.Lists[i].TaskList, Tasks: doc.Lists[i].Tasks}
+		}
+		if err := store.CreateLists(lists); err != nil {
+			writeCreateError(w, err, "Failed to import lists: "+err.Error())
+			return
+		}
+		for _, imported := range lists {
+			result.Lists = append(result.Lists, *imported.List)
+			result.Tasks += len(imported.Tasks)
+		}
+
+		writeJSON(w, http.StatusCreated, result)
+	}
+}
+
+// checkImportIDs checks that the lists and tasks of a JSON import have
+// valid IDs, unique within the document, that no existing list or task uses
+// yet. It returns the HTTP status to report along with any error.
+func checkImportIDs(store storage.TaskStore, lists []fullExportList) (int, error) {
+	seen := make(map[string]bool)
+	for i := range lists {
+		list := &lists[i]
+		if err := storage.ValidateID(list.ID); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("Invalid list ID: %q", list.ID)
+		}
+		if seen["list/"+list.ID] {
+			return http.StatusBadRequest, fmt.Errorf("List %s appears more than once", list.ID)
+		}
+		seen["list/"+list.ID] = true
+		if _, err := store.GetList(list.ID); err == nil {
+			return http.StatusConflict, fmt.Errorf("List already exists: %s", list.ID)
+		} else if !errors.Is(err, storage.ErrListNotFound) {
+			status, message := storeError(err, "List not found")
+			return status, errors.New(message)
+		}
+
+		for j := range list.Tasks {
+			task := &list.Tasks[j]
+			if task.Title == "" {
+				return http.StatusBadRequest, fmt.Errorf("Task title is required (list %s, task %d)", list.ID, j)
+			}
+			if err := storage.ValidateID(task.ID); err != nil {
+				return http.StatusBadRequest, fmt.Errorf("Invalid task ID: %q", task.ID)
+			}
+			if seen["task/"+task.ID] {
+				return http.StatusBadRequest, fmt.Errorf("Task %s appears more than once", task.ID)
+			}
+			seen["task/"+task.ID] = true
+			if _, err := store.FindTask(task.ID); err == nil {
+				return http.StatusConflict, fmt.Errorf("Task already exists: %s", task.ID)
+			} else if !errors.Is(err, storage.ErrTaskNotFound) {
+				status, message := storeError(err, "Task not found")
+				return status, errors.New(message)
+			}
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// checkImportTasks checks the lists and tasks of a JSON import as if they
+// were created through the API: list settings, and each task's state against
+// its imported list's workflow, priority, recurrence, effort, dates, blocked
+// reason and dependencies. Dependencies may name tasks in the document or
+// tasks already stored. It returns the HTTP status to report along with any
+// error.
+func checkImportTasks(store storage.TaskStore, lists []fullExportList) (int, error) {
+	graph := make(map[string][]string)
+	for i := range lists {
+		list := &lists[i]
+		if list.Name == "" {
+			return http.StatusBadRequest, fmt.Errorf("List name is required (list %s)", list.ID)
+		}
+		for _, validate := range []func(*models.TaskList) error{validateDescriptionTemplate, validateListStates, validateListAppearance} {
+			if err := validate(&list.TaskList); err != nil {
+				return http.StatusBadRequest, fmt.Errorf("list %s: %w", list.ID, err)
+			}
+		}
+		for j := range list.Tasks {
+			task := &list.Tasks[j]
+			if err := checkImportedTask(&list.TaskList, task); err != nil {
+				return http.StatusBadRequest, fmt.Errorf("task %s: %w", task.ID, err)
+			}
+			graph[task.ID] = task.DependsOn
+		}
+	}
+
+	stored, err := store.GetAllTasks()
+	if err != nil {
+		status, message := storeError(err, "Task not found")
+		return status, errors.New(message)
+	}
+	for _, task := range stored {
+		graph[task.ID] = task.DependsOn
+	}
+	for i := range lists {
+		for j := range lists[i].Tasks {
+			task := &lists[i].Tasks[j]
+			if status, err := checkDependencies(graph, task); err != nil {
+				return status, fmt.Errorf("task %s: %w", task.ID, err)
+			}
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// checkImportedTask normalizes and checks one imported task, and its
+// subtasks, against the rules for creating a task in list
+func checkImportedTask(list *models.TaskList, task *models.Task) error {
+	if task.State == "" {
+		task.State = list.TaskStates()[0]
+	}
+	normalizeTags(task)
+	task.Assignee = strings.TrimSpace(task.Assignee)
+	if err := normalizeBlockedReason(task); err != nil {
+		return err
+	}
+	if err := validateStartDate(task); err != nil {
+		return err
+	}
+	if err := validateEffort(task); err != nil {
+		return err
+	}
+	if err := checkStateInList(list, task); err != nil {
+		return err
+	}
+	if !task.Recurrence.Valid() {
+		return fmt.Errorf("Invalid recurrence: %s", task.Recurrence)
+	}
+	if !task.Priority.Valid() {
+		return fmt.Errorf("Invalid priority: %s (expected low, medium, high or urgent)", task.Priority)
+	}
+	normalizeDependsOn(task)
+
+	for i := range task.SubTasks {
+		subTask := &task.SubTasks[i]
+		if subTask.Title == "" {
+			return fmt.Errorf("subtask title is required")
+		}
+		if err := checkImportedTask(list, subTask); err != nil {
+			return fmt.Errorf("subtask %s: %w", subTask.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+func TestMarkdownParserParseLine(t *testing.T) {
+	tests := []struct {
+		name        string
+		lines       []string
+		wantSkipped int
+		check       func(t *testing.T, lists []*importedList)
+	}{
+		{
+			name:  "default state heading",
+			lines: []string{"## Work", "### In Progress", "- **Write**: the docs (Due: 2024-03-01)"},
+			check: func(t *testing.T, lists []*importedList) {
+				task := lists[0].tasks[0]
+				if task.State != models.TaskStateInProgress || task.Title != "Write" || task.Description != "the docs" {
+					t.Errorf("task %+v", task)
+				}
+				if task.DueDate == nil || task.DueDate.Format(dateLayout) != "2024-03-01" {
+					t.Errorf("due date %v, want 2024-03-01", task.DueDate)
+				}
+				if len(lists[0].list.States) != 0 {
+					t.Errorf("list states %v, want the defaults", lists[0].list.States)
+				}
+			},
+		},
+		{
+			name:  "custom state heading",
+			lines: []string{"## Work", "### Review", "- **Check**", "### Shipped", "- **Release**"},
+			check: func(t *testing.T, lists []*importedList) {
+				if got := strings.Join(lists[0].list.States, ","); got != "Review,Shipped" {
+					t.Errorf("list states %q, want Review,Shipped", got)
+				}
+				if lists[0].tasks[0].State != "Review" || lists[0].tasks[1].State != "Shipped" {
+					t.Errorf("task states %q, %q", lists[0].tasks[0].State, lists[0].tasks[1].State)
+				}
+			},
+		},
+		{
+			name:  "blocked reason kept",
+			lines: []string{"## Work", "### Blocked", "- **Deploy**", "  - Blocked: waiting on review"},
+			check: func(t *testing.T, lists []*importedList) {
+				if reason := lists[0].tasks[0].BlockedReason; reason != "waiting on review" {
+					t.Errorf("blocked reason %q", reason)
+				}
+			},
+		},
+		{
+			name:  "blocked reason fallback",
+			lines: []string{"## Work", "### Blocked", "- **Deploy**"},
+			check: func(t *testing.T, lists []*importedList) {
+				if reason := lists[0].tasks[0].BlockedReason; reason != "Imported from markdown" {
+					t.Errorf("blocked reason %q, want the fallback", reason)
+				}
+			},
+		},
+		{
+			name:  "blocked reason dropped outside blocked",
+			lines: []string{"## Work", "### Done", "- **Deploy**", "  - Blocked: stale"},
+			check: func(t *testing.T, lists []*importedList) {
+				if reason := lists[0].tasks[0].BlockedReason; reason != "" {
+					t.Errorf("blocked reason %q, want none", reason)
+				}
+			},
+		},
+		{
+			name:        "malformed task lines",
+			lines:       []string{"## Work", "### To Do", "- **Unterminated", "- ****", "- **Late** (Due: 2024-13-45)", "- **Good**"},
+			wantSkipped: 3,
+			check: func(t *testing.T, lists []*importedList) {
+				if len(lists[0].tasks) != 1 || lists[0].tasks[0].Title != "Good" {
+					t.Errorf("tasks %+v, want only Good", lists[0].tasks)
+				}
+			},
+		},
+		{
+			name: "comments",
+			lines: []string{"## Work", "### To Do", "- **Task**", "  - Comments:",
+				"    - sam (2024-03-01 09:30): Looks good",
+				"    - no timestamp here",
+				"    - sam (2024-03-01): missing time",
+			},
+			wantSkipped: 2,
+			check: func(t *testing.T, lists []*importedList) {
+				comments := lists[0].tasks[0].Comments
+				if len(comments) != 1 || comments[0].Author != "sam" || comments[0].Body != "Looks good" {
+					t.Fatalf("comments %+v", comments)
+				}
+				if got := comments[0].CreatedAt.Format("2006-01-02 15:04"); got != "2024-03-01 09:30" {
+					t.Errorf("comment time %s", got)
+				}
+			},
+		},
+		{
+			name:        "details without a task",
+			lines:       []string{"## Work", "### To Do", "  - Notes:", "    - orphaned", "- **Task**", "  - Unknown:"},
+			wantSkipped: 3,
+			check: func(t *testing.T, lists []*importedList) {
+				if len(lists[0].tasks[0].Notes) != 0 {
+					t.Errorf("notes %+v, want none", lists[0].tasks[0].Notes)
+				}
+			},
+		},
+		{
+			name:        "lines outside a list",
+			lines:       []string{"# Task Lists", "stray text", "- **Orphan**", "##", "- **Also orphaned**", "## Work", "Description"},
+			wantSkipped: 4,
+			check: func(t *testing.T, lists []*importedList) {
+				if len(lists) != 1 || len(lists[0].tasks) != 0 || lists[0].list.Description != "Description" {
+					t.Errorf("lists %+v", lists)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := markdownParser{now: time.Now()}
+			for _, line := range tt.lines {
+				parser.parseLine(line)
+			}
+			for _, list := range parser.lists {
+				list.finish()
+			}
+			if parser.skipped != tt.wantSkipped {
+				t.Errorf("skipped %d lines, want %d", parser.skipped, tt.wantSkipped)
+			}
+			tt.check(t, parser.lists)
+		})
+	}
+}
+
+func TestMarkdownExportImportRoundTrip(t *testing.T) {
+	source := newTestStore(t)
+	due := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	commented := time.Date(2024, 2, 20, 9, 30, 0, 0, time.Local)
+	if err := source.CreateList(&models.TaskList{ID: "home", Name: "Home", Description: "Chores"}); err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	tasks := []models.Task{
+		{ID: "paint", ListID: "home", Title: "Paint", Description: "The fence", State: models.TaskStateTodo, DueDate: &due},
+		{
+			ID: "fix", ListID: "home", Title: "Fix the sink", State: models.TaskStateBlocked, BlockedReason: "Waiting for parts",
+			Notes:    []models.Note{{ID: "n1", Content: "Washer size 12"}},
+			Comments: []models.Comment{{ID: "c1", Author: "sam", Body: "Ordered", CreatedAt: commented}},
+			SubTasks: []models.Task{{ID: "buy", ListID: "home", Title: "Buy a washer", State: models.TaskStateTodo}},
+		},
+		{ID: "sweep", ListID: "home", Title: "Sweep", State: models.TaskStateDone},
+	}
+	for i := range tasks {
+		if err := source.CreateTask(&tasks[i]); err != nil {
+			t.Fatalf("CreateTask(%s): %v", tasks[i].ID, err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	NewRouter(source, embed.FS{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/export?format=markdown", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/export?format=markdown: status %d: %s", rec.Code, rec.Body)
+	}
+
+	target := newTestStore(t)
+	req := httptest.NewRequest(http.MethodPost, "/api/import/markdown", rec.Body)
+	req.Header.Set("Content-Type", "text/markdown")
+	imported := httptest.NewRecorder()
+	NewRouter(target, embed.FS{}).ServeHTTP(imported, req)
+	if imported.Code != http.StatusCreated {
+		t.Fatalf("POST /api/import/markdown: status %d: %s", imported.Code, imported.Body)
+	}
+	var result importResult
+	if err := json.Unmarshal(imported.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decoding import result: %v", err)
+	}
+	if len(result.Lists) != 1 || result.Tasks != 3 || result.Skipped != 0 {
+		t.Fatalf("import result %+v, want 1 list, 3 tasks and nothing skipped", result)
+	}
+
+	list := result.Lists[0]
+	if list.Name != "Home" || list.Description != "Chores" {
+		t.Errorf("imported list %+v", list)
+	}
+	got, err := target.GetTasksForList(list.ID)
+	if err != nil {
+		t.Fatalf("GetTasksForList: %v", err)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].Title < got[j].Title })
+	if len(got) != 3 {
+		t.Fatalf("imported %d tasks, want 3", len(got))
+	}
+	fix, paint, sweep := got[0], got[1], got[2]
+
+	if paint.Title != "Paint" || paint.Description != "The fence" || paint.State != models.TaskStateTodo {
+		t.Errorf("imported task %+v", paint)
+	}
+	if paint.DueDate == nil || paint.DueDate.Format(dateLayout) != "2024-03-01" {
+		t.Errorf("imported due date %v, want 2024-03-01", paint.DueDate)
+	}
+	if fix.State != models.TaskStateBlocked || fix.BlockedReason != "Waiting for parts" {
+		t.Errorf("imported blocked task: state %q, reason %q", fix.State, fix.BlockedReason)
+	}
+	if len(fix.Notes) != 1 || fix.Notes[0].Content != "Washer size 12" {
+		t.Errorf("imported notes %+v", fix.Notes)
+	}
+	if len(fix.Comments) != 1 || fix.Comments[0].Author != "sam" || fix.Comments[0].Body != "Ordered" || !fix.Comments[0].CreatedAt.Equal(commented) {
+		t.Errorf("imported comments %+v", fix.Comments)
+	}
+	if len(fix.SubTasks) != 1 || fix.SubTasks[0].Title != "Buy a washer" {
+		t.Errorf("imported subtasks %+v", fix.SubTasks)
+	}
+	if sweep.State != models.TaskStateDone {
+		t.Errorf("imported state %q, want done", sweep.State)
+	}
+}
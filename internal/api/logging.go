@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Access logging
+
+// jsonAccessLog selects JSON lines for the access log instead of chi's text
+// format, configured with SetLogFormat
+var jsonAccessLog bool
+
+// SetLogFormat sets the access log format: "json" writes one JSON object per
+// request, anything else keeps the text format
+func SetLogFormat(format string) {
+	jsonAccessLog = format == "json"
+}
+
+// accessLogEntry is one line of the JSON access log
+type accessLogEntry struct {
+	Time       string  `json:"time"`
+	Method     string  `json:"method"`
+	Path       string  `json:"path"`
+	Status     int     `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+	Bytes      int     `json:"bytes"`
+	RemoteIP   string  `json:"remote_ip"`
+}
+
+// AccessLogMiddleware logs every request in the configured format
+func AccessLogMiddleware(next http.Handler) http.Handler {
+	if !jsonAccessLog {
+		return middleware.Logger(next)
+	}
+	return JSONLogMiddleware(next)
+}
+
+// JSONLogMiddleware writes a JSON line per request with its method, path,
+// status, duration, response size and remote IP
+func JSONLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		// RemoteAddr has no port once RealIP has replaced it
+		remoteIP := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(remoteIP); err == nil {
+			remoteIP = host
+		}
+
+		line, err := json.Marshal(accessLogEntry{
+			Time:       start.UTC().Format(time.RFC3339Nano),
+			Method:     r.Method,
+			Path:       r.URL.RequestURI(),
+			Status:     status,
+			DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+			Bytes:      ww.BytesWritten(),
+			RemoteIP:   remoteIP,
+		})
+		if err != nil {
+			return
+		}
+		log.Writer().Write(append(line, '\n'))
+	})
+}
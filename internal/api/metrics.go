@@ -0,0 +1,121 @@
+package api
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus metrics
+
+// storeMetricsTTL is how long the list and task gauges are cached between
+// scrapes, so frequent scrapes don't re-read every task from the store
+const storeMetricsTTL = 15 * time.Second
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tasks_http_requests_total",
+		Help: "HTTP requests handled, by route pattern, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	listsDesc = prometheus.NewDesc(
+		"tasks_lists",
+		"Number of task lists.",
+		nil, nil)
+	tasksDesc = prometheus.NewDesc(
+		"tasks_tasks",
+		"Number of tasks, by state.",
+		[]string{"state"}, nil)
+)
+
+// storeCollector reports list and task counts computed from the store,
+// refreshing them at most once per storeMetricsTTL
+type storeCollector struct {
+	store storage.TaskStore
+
+	mutex     sync.Mutex
+	updatedAt time.Time
+	lists     int
+	byState   map[models.TaskState]int
+}
+
+// storeCollectors are the collectors created by NewRouter, whose cached
+// counts InvalidateCaches drops
+var (
+	storeCollectorsMutex sync.Mutex
+	storeCollectors      []*storeCollector
+)
+
+func newStoreCollector(store storage.TaskStore) *storeCollector {
+	c := &storeCollector{store: store}
+
+	storeCollectorsMutex.Lock()
+	storeCollectors = append(storeCollectors, c)
+	storeCollectorsMutex.Unlock()
+	return c
+}
+
+// InvalidateCaches drops everything cached from the store, so changes made
+// to the data directory outside the server are seen on the next read
+func InvalidateCaches() {
+	storeCollectorsMutex.Lock()
+	defer storeCollectorsMutex.Unlock()
+
+	for _, c := range storeCollectors {
+		c.mutex.Lock()
+		c.updatedAt = time.Time{}
+		c.mutex.Unlock()
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- listsDesc
+	ch <- tasksDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *storeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if time.Since(c.updatedAt) > storeMetricsTTL {
+		c.refresh()
+	}
+
+	ch <- prometheus.MustNewConstMetric(listsDesc, prometheus.GaugeValue, float64(c.lists))
+	for _, state := range models.DefaultTaskStates {
+		ch <- prometheus.MustNewConstMetric(tasksDesc, prometheus.GaugeValue, float64(c.byState[state]), string(state))
+	}
+	for state, count := range c.byState {
+		if !slices.Contains(models.DefaultTaskStates, state) {
+			ch <- prometheus.MustNewConstMetric(tasksDesc, prometheus.GaugeValue, float64(count), string(state))
+		}
+	}
+}
+
+// refresh recounts lists and tasks, keeping the previous counts if the store
+// can't be read. Must be called with the mutex held.
+func (c *storeCollector) refresh() {
+	lists, err := c.store.GetAllLists()
+	if err != nil {
+		return
+	}
+	tasks, err := c.store.GetAllTasks()
+	if err != nil {
+		return
+	}
+
+	byState := make(map[models.TaskState]int)
+	for _, task := range tasks {
+		byState[task.State]++
+	}
+
+	c.lists = len(lists)
+	c.byState = byState
+	c.updatedAt = time.Now()
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Moving tasks between lists
+
+// moveTaskRequest is the payload accepted by HandleMoveTask
+type moveTaskRequest struct {
+	TargetListID string `json:"target_list_id"`
+	Position     *int   `json:"position,omitempty"`    // See storage.MoveOptions
+	ResetState   bool   `json:"reset_state,omitempty"` // See storage.MoveOptions
+}
+
+// HandleMoveTask moves a task into another list and returns the moved task.
+// This is the canonical way to move a task; changing list_id through
+// HandleUpdateTask still works.
+func HandleMoveTask(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		taskID := chi.URLParam(r, "taskID")
+
+		var req moveTaskRequest
+		if err := decodeStrict(r, &req); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		if req.TargetListID == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Target list ID is required")
+			return
+		}
+
+		if _, err := store.GetList(listID); err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+		if _, err := store.GetTask(listID, taskID); err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
+		if req.TargetListID == listID {
+			writeErrorJSON(w, http.StatusConflict, "Task is already in the target list")
+			return
+		}
+		if _, err := store.GetList(req.TargetListID); err != nil {
+			writeStoreError(w, err, "Target list not found")
+			return
+		}
+
+		opts := storage.MoveOptions{Position: req.Position, ResetState: req.ResetState}
+		moved, err := store.MoveTask(listID, taskID, req.TargetListID, opts)
+		if errors.Is(err, storage.ErrStateNotAllowed) {
+			writeErrorJSON(w, http.StatusBadRequest, "Failed to move task: "+err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrTaskLimit) {
+			writeErrorJSON(w, http.StatusConflict, "Failed to move task: "+err.Error())
+			return
+		}
+		if err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to move task: "+err.Error())
+			return
+		}
+
+		// HTMX requests come from the source list's page, which loses the task
+		if r.Header.Get("HX-Request") == "true" {
+			tasks, err := store.GetTasksForList(listID)
+			if err != nil {
+				http.Error(w, "Failed to retrieve tasks", http.StatusInternalServerError)
+				return
+			}
+			renderTasksContainer(w, tasks)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, moved)
+	}
+}
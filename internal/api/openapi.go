@@ -0,0 +1,264 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// OpenAPI spec generation
+
+// The hand-written spec in HandleOpenAPISpec carries the summaries,
+// parameters and descriptions. syncSpecWithRoutes and completeSchemas keep it
+// in step with the code: every registered /api route gets an operation and
+// every model field a schema property, even before anyone documents them.
+
+// specMethods maps the methods chi reports to OpenAPI operation keys
+var specMethods = map[string]string{
+	http.MethodGet:     "get",
+	http.MethodPost:    "post",
+	http.MethodPut:     "put",
+	http.MethodPatch:   "patch",
+	http.MethodDelete:  "delete",
+	http.MethodHead:    "head",
+	http.MethodOptions: "options",
+}
+
+// pathParamPattern matches chi path parameters such as {listID} or {id:[0-9]+}
+var pathParamPattern = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// handlerNamePattern extracts the constructor name from a handler closure,
+// e.g. HandleGetTask from github.com/.../api.HandleGetTask.func1, or from
+// api.NewRouter.HandleGetTask.1 where the constructor was inlined
+var handlerNamePattern = regexp.MustCompile(`\.(Handle[A-Za-z0-9]+)\.(?:func)?\d+$`)
+
+// schemaModels are the component schemas completed from their Go types
+var schemaModels = map[string]interface{}{
+	"Task":         taskView{},
+	"TaskList":     models.TaskList{},
+	"Note":         models.Note{},
+	"Comment":      models.Comment{},
+	"Attachment":   models.Attachment{},
+	"HistoryEntry": models.HistoryEntry{},
+	"SavedFilter":  models.SavedFilter{},
+}
+
+// syncSpecWithRoutes makes the spec's paths match the /api routes registered
+// on routes: undocumented routes get a generated operation, marked with
+// x-generated, and documented operations that no longer exist are dropped
+func syncSpecWithRoutes(paths map[string]interface{}, routes chi.Routes) error {
+	registered := make(map[string]map[string]bool)
+	err := chi.Walk(routes, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		route = normalizeRoute(route)
+		op, ok := specMethods[method]
+		if !ok || !strings.HasPrefix(route, "/api/") {
+			return nil
+		}
+		route = pathParamPattern.ReplaceAllString(route, "{$1}")
+
+		if registered[route] == nil {
+			registered[route] = make(map[string]bool)
+		}
+		registered[route][op] = true
+
+		item, _ := paths[route].(map[string]interface{})
+		if item == nil {
+			item = make(map[string]interface{})
+			paths[route] = item
+		}
+		if _, ok := item[op]; !ok {
+			item[op] = generatedOperation(method, route, handler)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for route, value := range paths {
+		item, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, op := range specMethods {
+			if _, documented := item[op]; documented && !registered[route][op] {
+				delete(item, op)
+			}
+		}
+		if len(item) == 0 {
+			delete(paths, route)
+		}
+	}
+	return nil
+}
+
+// normalizeRoute strips the markers chi.Walk leaves on routes of mounted
+// sub-routers, as collectRoutes does
+func normalizeRoute(route string) string {
+	route = strings.ReplaceAll(route, "/*/", "/")
+	if len(route) > 1 {
+		route = strings.TrimSuffix(route, "/")
+	}
+	return route
+}
+
+// generatedOperation describes a route that has no hand-written entry, with
+// a summary and operationId derived from its handler's name and its path
+// parameters
+func generatedOperation(method, route string, handler http.Handler) map[string]interface{} {
+	summary := method + " " + route
+	operationID := strings.ToLower(method) + strings.NewReplacer("/", "_", "{", "", "}", "").Replace(route)
+	if name := handlerName(handler); name != "" {
+		summary = describeHandlerName(name)
+		name = strings.TrimPrefix(name, "Handle")
+		operationID = strings.ToLower(name[:1]) + name[1:]
+	}
+
+	op := map[string]interface{}{
+		"summary":     summary,
+		"operationId": operationID,
+		"x-generated": true,
+		"responses": map[string]interface{}{
+			"default": map[string]interface{}{
+				"description": "Response",
+			},
+		},
+	}
+
+	var parameters []map[string]interface{}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(route, -1) {
+		parameters = append(parameters, map[string]interface{}{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]string{"type": "string"},
+		})
+	}
+	if parameters != nil {
+		op["parameters"] = parameters
+	}
+	return op
+}
+
+// handlerName returns the name of the Handle* constructor that built
+// handler, or "" if it wasn't built by one
+func handlerName(handler http.Handler) string {
+	fn, ok := handler.(http.HandlerFunc)
+	if !ok {
+		return ""
+	}
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return ""
+	}
+	if match := handlerNamePattern.FindStringSubmatch(f.Name()); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// describeHandlerName turns a handler name into a summary, e.g.
+// HandleGetTaskStreak into "Get task streak"
+func describeHandlerName(name string) string {
+	name = strings.TrimPrefix(name, "Handle")
+
+	var words []string
+	start := 0
+	for i, r := range name {
+		if i > start && unicode.IsUpper(r) {
+			words = append(words, strings.ToLower(name[start:i]))
+			start = i
+		}
+	}
+	words = append(words, strings.ToLower(name[start:]))
+
+	summary := strings.Join(words, " ")
+	return strings.ToUpper(summary[:1]) + summary[1:]
+}
+
+// completeSchemas adds a property to each model schema for every JSON field
+// of its Go type that the hand-written schema leaves out
+func completeSchemas(schemas map[string]interface{}) {
+	for name, model := range schemaModels {
+		schema, ok := schemas[name].(map[string]interface{})
+		if !ok {
+			schema = map[string]interface{}{"type": "object"}
+			schemas[name] = schema
+		}
+		properties, ok := schema["properties"].(map[string]interface{})
+		if !ok {
+			properties = make(map[string]interface{})
+			schema["properties"] = properties
+		}
+		addFieldSchemas(properties, reflect.TypeOf(model))
+	}
+}
+
+// addFieldSchemas adds a property for each JSON field of the struct type t
+// missing from properties, including the fields of embedded structs
+func addFieldSchemas(properties map[string]interface{}, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addFieldSchemas(properties, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := properties[name]; !ok {
+			properties[name] = typeSchema(field.Type)
+		}
+	}
+}
+
+// typeSchema returns the schema of values of type t as encoding/json writes
+// them. Structs with a component schema are referenced by name.
+func typeSchema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Pointer {
+		schema := typeSchema(t.Elem())
+		schema["nullable"] = true
+		return schema
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		for name, model := range schemaModels {
+			if reflect.TypeOf(model) == t {
+				return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+			}
+		}
+	}
+	return map[string]interface{}{"type": "object"}
+}
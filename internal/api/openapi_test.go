@@ -0,0 +1,124 @@
+package api
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// newTestStore returns a FileStore in a temporary directory
+func newTestStore(t *testing.T) *storage.FileStore {
+	t.Helper()
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	return store
+}
+
+// newTestRouter returns the router over a FileStore in a temporary directory
+func newTestRouter(t *testing.T) chi.Router {
+	t.Helper()
+	return NewRouter(newTestStore(t), embed.FS{}).(chi.Router)
+}
+
+// fetchSpecPaths returns the paths of the spec served at /api/openapi
+func fetchSpecPaths(t *testing.T, router http.Handler) map[string]map[string]json.RawMessage {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/openapi: status %d: %s", rec.Code, rec.Body)
+	}
+
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("parsing spec: %v", err)
+	}
+	return spec.Paths
+}
+
+// apiRoutes returns the registered /api routes as spec paths, each with the
+// spec operation keys of its methods
+func apiRoutes(t *testing.T, router chi.Routes) map[string]map[string]bool {
+	t.Helper()
+	routes := make(map[string]map[string]bool)
+	err := chi.Walk(router, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		route = normalizeRoute(route)
+		op, ok := specMethods[method]
+		if !ok || !strings.HasPrefix(route, "/api/") {
+			return nil
+		}
+		route = pathParamPattern.ReplaceAllString(route, "{$1}")
+		if routes[route] == nil {
+			routes[route] = make(map[string]bool)
+		}
+		routes[route][op] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("chi.Walk: %v", err)
+	}
+	return routes
+}
+
+func TestOpenAPISpecMatchesRoutes(t *testing.T) {
+	router := newTestRouter(t)
+	paths := fetchSpecPaths(t, router)
+	routes := apiRoutes(t, router)
+
+	for route, ops := range routes {
+		for op := range ops {
+			if _, ok := paths[route][op]; !ok {
+				t.Errorf("%s %s is registered but missing from the spec", strings.ToUpper(op), route)
+			}
+		}
+	}
+	operations := make(map[string]bool, len(specMethods))
+	for _, op := range specMethods {
+		operations[op] = true
+	}
+	for path, item := range paths {
+		for op := range item {
+			// Path items also hold shared fields such as parameters
+			if operations[op] && !routes[path][op] {
+				t.Errorf("%s %s is in the spec but not registered", strings.ToUpper(op), path)
+			}
+		}
+	}
+}
+
+// TestOpenAPISpecDocumentsRoutes checks that every router path is in the
+// hand-written part of the spec, not just filled in by syncSpecWithRoutes,
+// so generated clients get real parameters and request bodies
+func TestOpenAPISpecDocumentsRoutes(t *testing.T) {
+	router := newTestRouter(t)
+	paths := fetchSpecPaths(t, router)
+
+	for route, ops := range apiRoutes(t, router) {
+		for op := range ops {
+			raw, ok := paths[route][op]
+			if !ok {
+				t.Errorf("%s %s is missing from the spec", strings.ToUpper(op), route)
+				continue
+			}
+			var operation struct {
+				Generated bool `json:"x-generated"`
+			}
+			if err := json.Unmarshal(raw, &operation); err != nil {
+				t.Fatalf("parsing %s %s: %v", op, route, err)
+			}
+			if operation.Generated {
+				t.Errorf("%s %s has no hand-written entry in HandleOpenAPISpec", strings.ToUpper(op), route)
+			}
+		}
+	}
+}
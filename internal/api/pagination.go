@@ -0,0 +1,99 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Pagination limits, configurable with SetPageLimits
+var (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// SetPageLimits configures the page size used when ?offset= is given without
+// ?limit=, and the largest page a client may request. Non-positive values
+// keep the current setting.
+func SetPageLimits(defaultSize, maxSize int) {
+	if maxSize > 0 {
+		maxPageSize = maxSize
+	}
+	if defaultSize > 0 {
+		defaultPageSize = defaultSize
+	}
+	if defaultPageSize > maxPageSize {
+		defaultPageSize = maxPageSize
+	}
+}
+
+// pageParams reads ?limit= and ?offset=. paged is false when neither is
+// given. Limits above the maximum are clamped; invalid values are rejected
+// with a 400, in which case ok is false and the error response has already
+// been written.
+func pageParams(w http.ResponseWriter, r *http.Request) (limit, offset int, paged, ok bool) {
+	query := r.URL.Query()
+	if !query.Has("limit") && !query.Has("offset") {
+		return 0, 0, false, true
+	}
+
+	limit = defaultPageSize
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			writeErrorJSON(w, http.StatusBadRequest, fmt.Sprintf("limit must be a positive integer (max %d)", maxPageSize))
+			return 0, 0, false, false
+		}
+		limit = min(parsed, maxPageSize)
+	}
+
+	if value := query.Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			writeErrorJSON(w, http.StatusBadRequest, "offset must be a non-negative integer")
+			return 0, 0, false, false
+		}
+		offset = parsed
+	}
+
+	return limit, offset, true, true
+}
+
+// setPageHeaders reports the unpaginated total and the effective limit and
+// offset of a page
+func setPageHeaders(w http.ResponseWriter, total, limit, offset int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	w.Header().Set("X-Limit", strconv.Itoa(limit))
+	w.Header().Set("X-Offset", strconv.Itoa(offset))
+}
+
+// paginate applies ?limit= and ?offset= to items. Requests without either
+// parameter get every item. If ok is false an error response has already
+// been written. The unpaginated total and the effective limit and offset are
+// reported in the X-Total-Count, X-Limit and X-Offset headers.
+func paginate[T any](w http.ResponseWriter, r *http.Request, items []T) (page []T, ok bool) {
+	limit, offset, paged, ok := pageParams(w, r)
+	if !paged || !ok {
+		return items, ok
+	}
+
+	setPageHeaders(w, len(items), limit, offset)
+
+	if offset >= len(items) {
+		return items[:0], true
+	}
+	end := min(offset+limit, len(items))
+	return items[offset:end], true
+}
+
+// HandleGetSettings returns the effective server settings exposed to clients
+func HandleGetSettings() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"pagination": map[string]int{
+				"default_page_size": defaultPageSize,
+				"max_page_size":     maxPageSize,
+			},
+		})
+	}
+}
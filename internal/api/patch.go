@@ -0,0 +1,114 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// JSON merge patch (RFC 7386)
+
+// mergePatch applies a JSON merge patch to a decoded JSON document. Members
+// of an object patch replace those of the target, null members remove them,
+// and any other patch replaces the target outright.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = mergePatch(targetObject[name], value)
+	}
+	return targetObject
+}
+
+// patchTask returns a copy of task with a merge patch applied. Fields left
+// out of the patch keep their values and fields set to null are cleared. The
+// result is decoded as strictly as a full update.
+func patchTask(task *models.Task, patch map[string]interface{}) (models.Task, error) {
+	var patched models.Task
+
+	data, err := json.Marshal(task)
+	if err != nil {
+		return patched, err
+	}
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return patched, err
+	}
+
+	data, err = json.Marshal(mergePatch(document, patch))
+	if err != nil {
+		return patched, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	// Decoding a taskView accepts the computed fields as read from the API
+	var view taskView
+	if err := dec.Decode(&view); err != nil {
+		return patched, describeDecodeError(err)
+	}
+	patched = view.Task
+
+	// Identity and creation time can't be patched
+	patched.ID = task.ID
+	patched.CreatedAt = task.CreatedAt
+	return patched, nil
+}
+
+// HandlePatchTask partially updates a task with a JSON merge patch: only the
+// fields present in the body change, and null clears a field. The patched
+// task goes through the same checks as a full update, including moving it
+// when list_id changes.
+func HandlePatchTask(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+
+		existingTask, err := store.GetTask(listID, chi.URLParam(r, "taskID"))
+		if err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
+
+		if match := r.Header.Get("If-Match"); match != "" {
+			if _, etag, err := jsonETag(newTaskView(existingTask)); err == nil && !etagMatchesStrong(match, etag) {
+				writeErrorJSON(w, http.StatusPreconditionFailed, "Task has been modified since it was read")
+				return
+			}
+		}
+
+		var patch map[string]interface{}
+		if err := decodeStrict(r, &patch); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		// null would clear list_id, which reads as a move to list ""
+		if value, ok := patch["list_id"]; ok && value == nil {
+			writeErrorJSON(w, http.StatusBadRequest, "list_id cannot be null")
+			return
+		}
+
+		updatedTask, err := patchTask(existingTask, patch)
+		if err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		updatedTask.Assignee = strings.TrimSpace(updatedTask.Assignee)
+
+		saveTaskUpdate(w, r, store, listID, existingTask, updatedTask)
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Repositioning a task within its list
+
+// positionTaskRequest is the payload accepted by HandlePositionTask. Exactly
+// one of After and Index is set.
+type positionTaskRequest struct {
+	After *string `json:"after,omitempty"` // ID of the task to place this one directly after
+	Index *int    `json:"index,omitempty"` // 0-based place among the tasks in the same state, i.e. its kanban column
+}
+
+// HandlePositionTask moves a single task within its list's position order,
+// shifting the tasks around it, and returns the moved task. Unlike
+// rewriting every position, this is the one call a within-column kanban drag
+// needs.
+func HandlePositionTask(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		taskID := chi.URLParam(r, "taskID")
+
+		var req positionTaskRequest
+		if err := decodeStrict(r, &req); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		if (req.After == nil) == (req.Index == nil) {
+			writeErrorJSON(w, http.StatusBadRequest, "Exactly one of after or index is required")
+			return
+		}
+		if req.Index != nil && *req.Index < 0 {
+			writeErrorJSON(w, http.StatusBadRequest, "Index must not be negative")
+			return
+		}
+
+		tasks, err := store.GetTasksForList(listID)
+		if err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+		models.SortTasksByPosition(tasks)
+
+		current := slices.IndexFunc(tasks, func(task models.Task) bool { return task.ID == taskID })
+		if current < 0 {
+			writeErrorJSON(w, http.StatusNotFound, "Task not found")
+			return
+		}
+		task := tasks[current]
+		others := slices.Delete(slices.Clone(tasks), current, current+1)
+
+		var target int
+		if req.After != nil {
+			after := slices.IndexFunc(others, func(other models.Task) bool { return other.ID == *req.After })
+			if after < 0 {
+				writeErrorJSON(w, http.StatusBadRequest, "Task to place after is not another task in this list: "+*req.After)
+				return
+			}
+			target = after + 1
+		} else {
+			target = columnIndex(others, task.State, *req.Index, current)
+		}
+
+		moved, err := store.PositionTask(listID, taskID, target)
+		if err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, newTaskView(moved))
+	}
+}
+
+// columnIndex translates an index among the tasks of others in state into an
+// index among all of others, which are sorted by position. An index past the
+// end of the column places the task after the column's last task; with an
+// empty column the task keeps its place, current.
+func columnIndex(others []models.Task, state models.TaskState, index, current int) int {
+	last := -1
+	for i := range others {
+		if others[i].State != state {
+			continue
+		}
+		if index == 0 {
+			return i
+		}
+		index--
+		last = i
+	}
+	if last < 0 {
+		return current
+	}
+	return last + 1
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Subtask rollup
+
+// taskView is a task as returned by the API, with the fields computed on
+// read rather than stored
+type taskView struct {
+	models.Task
+	Progress *float64 `json:"progress"` // Fraction of subtasks done; null without subtasks
+}
+
+// newTaskView returns the API representation of a task
+func newTaskView(task *models.Task) taskView {
+	return taskView{Task: *task, Progress: task.SubTaskProgress()}
+}
+
+// taskViews returns the API representation of several tasks
+func taskViews(tasks []models.Task) []taskView {
+	views := make([]taskView, len(tasks))
+	for i := range tasks {
+		views[i] = newTaskView(&tasks[i])
+	}
+	return views
+}
+
+// autoCompleteParent moves a task to done once all of its subtasks are, if
+// its list has auto_complete set. Tasks without subtasks and recurring
+// tasks, which regenerate when completed, are left alone.
+func autoCompleteParent(store storage.TaskStore, task *models.Task) {
+	if task.State == models.TaskStateDone || task.Recurrence != "" {
+		return
+	}
+	if progress := task.SubTaskProgress(); progress == nil || *progress < 1 {
+		return
+	}
+
+	list, err := store.GetList(task.ListID)
+	if err != nil || !list.AutoComplete || !list.AllowsState(models.TaskStateDone) {
+		return
+	}
+	task.SetState(models.TaskStateDone)
+	task.BlockedReason = ""
+}
+
+// decodeTask strictly decodes a task from the request body into task. The
+// computed fields of taskView are accepted and ignored, so a task read from
+// the API can be sent back as is.
+func decodeTask(r *http.Request, task *models.Task) error {
+	view := taskView{Task: *task}
+	if err := decodeStrict(r, &view); err != nil {
+		return err
+	}
+	*task = view.Task
+	return nil
+}
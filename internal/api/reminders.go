@@ -0,0 +1,126 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Due-date reminders
+
+// reminderWebhookTimeout bounds each reminder webhook request
+const reminderWebhookTimeout = 10 * time.Second
+
+// ReminderOptions configures RunReminders
+type ReminderOptions struct {
+	Window     time.Duration // Remind about tasks due within this long from now, including overdue ones
+	Interval   time.Duration // How often to scan for due tasks
+	WebhookURL string        // Reminders are POSTed here as JSON; empty to only log them
+}
+
+// reminderPayload is the JSON body POSTed to the reminder webhook
+type reminderPayload struct {
+	Event string   `json:"event"`
+	Task  taskView `json:"task"`
+}
+
+// RunReminders scans the store every opts.Interval for tasks that are not
+// done and are due within opts.Window, and sends one reminder per task and
+// due date. A task's ReminderSent marks it as reminded; changing its due date
+// clears the mark. A failed reminder is retried on the next scan. Returns
+// once ctx is done.
+func RunReminders(ctx context.Context, store storage.TaskStore, opts ReminderOptions) {
+	client := &http.Client{Timeout: reminderWebhookTimeout}
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		sendReminders(ctx, store, client, opts)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendReminders sends the reminders that are due, stopping early if ctx is
+// done
+func sendReminders(ctx context.Context, store storage.TaskStore, client *http.Client, opts ReminderOptions) {
+	tasks, err := store.GetAllTasks()
+	if err != nil {
+		log.Printf("Reminders: failed to retrieve tasks: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range tasks {
+		if ctx.Err() != nil {
+			return
+		}
+		task := &tasks[i]
+		if !needsReminder(task, now.Add(opts.Window)) {
+			continue
+		}
+
+		if err := sendReminder(ctx, client, opts.WebhookURL, task); err != nil {
+			log.Printf("Reminders: failed to send reminder for task %s/%s: %v", task.ListID, task.ID, err)
+			continue
+		}
+
+		// Marking the reminder isn't an edit, so it neither bumps the
+		// task's version nor appears in the undo journal. A task whose due
+		// date changed since it was read gets a reminder for the new date
+		// on a later scan.
+		if err := store.MarkReminderSent(task.ListID, task.ID, *task.DueDate, time.Now()); err != nil {
+			log.Printf("Reminders: failed to record reminder for task %s/%s: %v", task.ListID, task.ID, err)
+		}
+	}
+}
+
+// needsReminder reports whether task is due by the deadline and hasn't been
+// reminded about its current due date
+func needsReminder(task *models.Task, deadline time.Time) bool {
+	return task.DueDate != nil &&
+		task.ReminderSent == nil &&
+		task.State != models.TaskStateDone &&
+		!task.Archived &&
+		!task.DueDate.After(deadline)
+}
+
+// sendReminder POSTs a reminder for task to webhookURL, or logs it when no
+// webhook is configured
+func sendReminder(ctx context.Context, client *http.Client, webhookURL string, task *models.Task) error {
+	if webhookURL == "" {
+		log.Printf("Reminder: task %s/%s %q is due %s", task.ListID, task.ID, task.Title, task.DueDate.Format("2006-01-02"))
+		return nil
+	}
+
+	body, err := json.Marshal(reminderPayload{Event: "task.due", Task: newTaskView(task)})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
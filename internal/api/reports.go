@@ -0,0 +1,331 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Report Handlers
+
+// unassignedKey groups tasks without an assignee in reports
+const unassignedKey = "unassigned"
+
+// defaultUrgentDays is how soon a task must be due to count as urgent in the
+// priority matrix
+const defaultUrgentDays = 3
+
+// assigneeReport summarizes one assignee's workload
+type assigneeReport struct {
+	Assignee   string `json:"assignee"`
+	Todo       int    `json:"todo"`
+	InProgress int    `json:"in_progress"`
+	Blocked    int    `json:"blocked"`
+	Done       int    `json:"done"`
+	Overdue    int    `json:"overdue"`
+	Total      int    `json:"total"`
+}
+
+// isOverdue reports whether a task is past its due date and not yet done.
+// Due dates are days, stored as UTC midnight, so a task due today is not
+// overdue until tomorrow.
+func isOverdue(task *models.Task, now time.Time) bool {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return task.DueDate != nil && task.State != models.TaskStateDone && task.DueDate.Before(today)
+}
+
+// loadReportTasks returns the tasks a report covers: a single list when the
+// listID query parameter is set, otherwise every list
+func loadReportTasks(store storage.TaskStore, r *http.Request) ([]models.Task, error) {
+	if listID := r.URL.Query().Get("listID"); listID != "" {
+		return store.GetTasksForList(listID)
+	}
+	return store.GetAllTasks()
+}
+
+// HandleAssigneeReport returns per-assignee task counts
+func HandleAssigneeReport(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tasks, err := loadReportTasks(store, r)
+		if err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		now := time.Now()
+		reports := make(map[string]*assigneeReport)
+		for i := range tasks {
+			task := &tasks[i]
+
+			assignee := strings.TrimSpace(task.Assignee)
+			if assignee == "" {
+				assignee = unassignedKey
+			}
+
+			report, ok := reports[assignee]
+			if !ok {
+				report = &assigneeReport{Assignee: assignee}
+				reports[assignee] = report
+			}
+
+			switch task.State {
+			case models.TaskStateTodo:
+				report.Todo++
+			case models.TaskStateInProgress:
+				report.InProgress++
+			case models.TaskStateBlocked:
+				report.Blocked++
+			case models.TaskStateDone:
+				report.Done++
+			}
+			if isOverdue(task, now) {
+				report.Overdue++
+			}
+			report.Total++
+		}
+
+		// Sort by name with the unassigned group last
+		rows := make([]assigneeReport, 0, len(reports))
+		for _, report := range reports {
+			rows = append(rows, *report)
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			if (rows[i].Assignee == unassignedKey) != (rows[j].Assignee == unassignedKey) {
+				return rows[j].Assignee == unassignedKey
+			}
+			return rows[i].Assignee < rows[j].Assignee
+		})
+
+		writeJSON(w, http.StatusOK, rows)
+	}
+}
+
+// priorityMatrix buckets open tasks into Eisenhower quadrants. Tasks are
+// urgent when overdue or due within the urgency window, and important when
+// their priority is high or urgent.
+type priorityMatrix struct {
+	UrgentDays int           `json:"urgent_days"`
+	DoFirst    []models.Task `json:"do_first"`  // urgent and important
+	Schedule   []models.Task `json:"schedule"`  // important, not urgent
+	Delegate   []models.Task `json:"delegate"`  // urgent, not important
+	Eliminate  []models.Task `json:"eliminate"` // neither urgent nor important
+}
+
+// isImportant reports whether a task's priority makes it important
+func isImportant(task *models.Task) bool {
+	return task.Priority == models.TaskPriorityHigh || task.Priority == models.TaskPriorityUrgent
+}
+
+// isUrgent reports whether a task is overdue or due before the deadline
+func isUrgent(task *models.Task, deadline time.Time) bool {
+	return task.DueDate != nil && task.DueDate.Before(deadline)
+}
+
+// HandleMatrixReport returns open tasks bucketed into the four quadrants of
+// an Eisenhower matrix. ?days= sets the urgency window (default 3 days).
+func HandleMatrixReport(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		days := defaultUrgentDays
+		if value := r.URL.Query().Get("days"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				writeErrorJSON(w, http.StatusBadRequest, "days must be a non-negative integer")
+				return
+			}
+			days = parsed
+		}
+
+		tasks, err := loadReportTasks(store, r)
+		if err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		deadline := time.Now().AddDate(0, 0, days)
+		matrix := priorityMatrix{
+			UrgentDays: days,
+			DoFirst:    []models.Task{},
+			Schedule:   []models.Task{},
+			Delegate:   []models.Task{},
+			Eliminate:  []models.Task{},
+		}
+		for i := range tasks {
+			task := &tasks[i]
+			if task.State == models.TaskStateDone {
+				continue
+			}
+
+			urgent, important := isUrgent(task, deadline), isImportant(task)
+			switch {
+			case urgent && important:
+				matrix.DoFirst = append(matrix.DoFirst, *task)
+			case important:
+				matrix.Schedule = append(matrix.Schedule, *task)
+			case urgent:
+				matrix.Delegate = append(matrix.Delegate, *task)
+			default:
+				matrix.Eliminate = append(matrix.Eliminate, *task)
+			}
+		}
+
+		writeJSON(w, http.StatusOK, matrix)
+	}
+}
+
+// taskTimeReport is one task's row in a list's time report
+type taskTimeReport struct {
+	ID                 string           `json:"id"`
+	Title              string           `json:"title"`
+	State              models.TaskState `json:"state"`
+	TimeInStateSeconds int64            `json:"time_in_state_seconds"`
+	StateSeconds       map[string]int64 `json:"state_seconds"` // Total per state, including the current one
+	EstimateMinutes    int              `json:"estimate_minutes"`
+	SpentMinutes       int              `json:"spent_minutes"`
+}
+
+// listTimeReport summarizes how long a list's tasks spend in each state
+type listTimeReport struct {
+	ListID                   string           `json:"list_id"`
+	Tasks                    []taskTimeReport `json:"tasks"`
+	DoneCount                int              `json:"done_count"`
+	AverageTimeToDoneSeconds int64            `json:"average_time_to_done_seconds"` // From creation until entering done
+	AverageStateSeconds      map[string]int64 `json:"average_state_seconds"`        // Over the tasks that have been in each state
+	EstimateMinutes          int              `json:"estimate_minutes"`             // Sum over the list's tasks
+	SpentMinutes             int              `json:"spent_minutes"`                // Sum over the list's tasks
+}
+
+// HandleListTimeReport reports how long each task in a list has been in its
+// current state and in total in each state, with list-wide averages
+func HandleListTimeReport(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		if _, err := store.GetList(listID); err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		tasks, err := store.GetTasksForList(listID)
+		if err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		now := time.Now()
+		report := listTimeReport{
+			ListID:              listID,
+			Tasks:               make([]taskTimeReport, 0, len(tasks)),
+			AverageStateSeconds: map[string]int64{},
+		}
+		var timeToDone time.Duration
+		stateTotals := make(map[string]time.Duration)
+		stateCounts := make(map[string]int)
+
+		for i := range tasks {
+			task := &tasks[i]
+
+			row := taskTimeReport{
+				ID:                 task.ID,
+				Title:              task.Title,
+				State:              task.State,
+				TimeInStateSeconds: int64(now.Sub(task.StateTime) / time.Second),
+				StateSeconds:       map[string]int64{},
+				EstimateMinutes:    task.EstimateMinutes,
+				SpentMinutes:       task.SpentMinutes,
+			}
+			for state, d := range task.TimeInStates(now) {
+				row.StateSeconds[string(state)] = int64(d / time.Second)
+				stateTotals[string(state)] += d
+				stateCounts[string(state)]++
+			}
+			report.Tasks = append(report.Tasks, row)
+			report.EstimateMinutes += task.EstimateMinutes
+			report.SpentMinutes += task.SpentMinutes
+
+			if task.State == models.TaskStateDone && !task.StateTime.IsZero() {
+				timeToDone += task.StateTime.Sub(task.CreatedAt)
+				report.DoneCount++
+			}
+		}
+
+		if report.DoneCount > 0 {
+			report.AverageTimeToDoneSeconds = int64(timeToDone / time.Duration(report.DoneCount) / time.Second)
+		}
+		for state, total := range stateTotals {
+			report.AverageStateSeconds[state] = int64(total / time.Duration(stateCounts[state]) / time.Second)
+		}
+
+		writeJSON(w, http.StatusOK, report)
+	}
+}
+
+// listStats is the task count and effort of one list in the stats summary
+type listStats struct {
+	ListID          string `json:"list_id"`
+	Name            string `json:"name"`
+	Tasks           int    `json:"tasks"`
+	EstimateMinutes int    `json:"estimate_minutes"`
+	SpentMinutes    int    `json:"spent_minutes"`
+}
+
+// storeStats summarizes the whole store for dashboards
+type storeStats struct {
+	Lists   int            `json:"lists"`
+	Tasks   int            `json:"tasks"`
+	ByState map[string]int `json:"by_state"`
+	Overdue int            `json:"overdue"`
+	PerList []listStats    `json:"per_list"`
+}
+
+// HandleGetStats returns totals across every list. The lists and tasks are
+// each loaded once and counted in a single pass.
+func HandleGetStats(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lists, err := store.GetAllLists()
+		if err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve lists")
+			return
+		}
+		tasks, err := store.GetAllTasks()
+		if err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve tasks")
+			return
+		}
+
+		now := time.Now()
+		stats := storeStats{
+			Lists:   len(lists),
+			Tasks:   len(tasks),
+			ByState: map[string]int{},
+			PerList: make([]listStats, 0, len(lists)),
+		}
+		perList := make(map[string]*listStats, len(lists))
+		for _, list := range lists {
+			perList[list.ID] = &listStats{ListID: list.ID, Name: list.Name}
+		}
+		for i := range tasks {
+			task := &tasks[i]
+			stats.ByState[string(task.State)]++
+			if isOverdue(task, now) {
+				stats.Overdue++
+			}
+			if list, ok := perList[task.ListID]; ok {
+				list.Tasks++
+				list.EstimateMinutes += task.EstimateMinutes
+				list.SpentMinutes += task.SpentMinutes
+			}
+		}
+
+		models.SortLists(lists)
+		for _, list := range lists {
+			stats.PerList = append(stats.PerList, *perList[list.ID])
+		}
+
+		writeJSON(w, http.StatusOK, stats)
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// failingStore fails every task read with err
+type failingStore struct {
+	storage.TaskStore
+	err error
+}
+
+func (s failingStore) GetAllTasks() ([]models.Task, error) { return nil, s.err }
+
+func (s failingStore) GetTasksForList(string) ([]models.Task, error) { return nil, s.err }
+
+// checkReportErrors checks that a report handler maps a missing list to 404
+// and any other store failure to 500
+func checkReportErrors(t *testing.T, newHandler func(storage.TaskStore) http.HandlerFunc) {
+	t.Helper()
+	for _, tt := range []struct {
+		err  error
+		want int
+	}{
+		{storage.ErrListNotFound, http.StatusNotFound},
+		{storage.ErrCorruptData, http.StatusInternalServerError},
+		{errors.New("disk failed"), http.StatusInternalServerError},
+	} {
+		for _, target := range []string{"/", "/?listID=list"} {
+			rec := httptest.NewRecorder()
+			newHandler(failingStore{err: tt.err}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+			if rec.Code != tt.want {
+				t.Errorf("GET %s with store error %q: status %d, want %d", target, tt.err, rec.Code, tt.want)
+			}
+		}
+	}
+}
+
+func TestAssigneeReportStoreErrors(t *testing.T) {
+	checkReportErrors(t, HandleAssigneeReport)
+}
+
+func TestIsOverdueFromTheDayAfterTheDueDate(t *testing.T) {
+	due := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	task := &models.Task{State: models.TaskStateTodo, DueDate: &due}
+
+	for _, tt := range []struct {
+		now  time.Time
+		want bool
+	}{
+		{time.Date(2024, 3, 9, 23, 0, 0, 0, time.UTC), false},
+		{time.Date(2024, 3, 10, 0, 0, 1, 0, time.UTC), false},
+		{time.Date(2024, 3, 10, 23, 59, 0, 0, time.UTC), false},
+		{time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), true},
+	} {
+		if got := isOverdue(task, tt.now); got != tt.want {
+			t.Errorf("isOverdue at %v = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+
+	task.State = models.TaskStateDone
+	if isOverdue(task, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error("done task reported overdue")
+	}
+}
+
+func TestMatrixReportStoreErrors(t *testing.T) {
+	checkReportErrors(t, HandleMatrixReport)
+}
@@ -1,75 +1,462 @@
 package api
 
 import (
+	"bytes"
+	"crypto/subtle"
 	"embed"
+	"encoding/json"
+	"html/template"
 	"io/fs"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jbutlerdev/tasks/internal/storage"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// HTMXMiddleware adds support for HTMX headers and better error handling
+// ValidateIDParams rejects requests whose list, task or attachment ID in the
+// URL is not a valid ID, so no handler builds a path from it. It must be
+// installed on a router below the route declaring the parameters.
+func ValidateIDParams(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			for i, key := range rctx.URLParams.Keys {
+				if !strings.HasSuffix(key, "ID") {
+					continue
+				}
+				if err := storage.ValidateID(rctx.URLParams.Values[i]); err != nil {
+					writeErrorJSON(w, http.StatusBadRequest, "Invalid "+key+": IDs may only contain letters, digits, dashes and underscores")
+					return
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// HTMXMiddleware adds support for HTMX headers and better error handling.
+// Error responses to HTMX requests are replaced with an error fragment
+// retargeted at the page's error banner, since HTMX can't swap JSON.
 func HTMXMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Log incoming requests
-		log.Printf("%s %s", r.Method, r.URL.Path)
-		
-		// Store whether this is an HTMX request for access in error handlers
-		isHtmx := r.Header.Get("HX-Request") == "true"
-		if isHtmx {
-			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if r.Header.Get("HX-Request") != "true" {
+			next.ServeHTTP(w, r)
+			return
 		}
-		
-		// Create a custom response writer that can detect errors
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		hw := &htmxResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(hw, r)
+
+		if hw.status >= http.StatusBadRequest {
+			writeHTMXError(w, hw.status, errorMessage(hw.status, hw.errorBody.Bytes()))
+		}
+	})
+}
+
+// htmxResponseWriter records the response status. The body of an error
+// response is held back so HTMXMiddleware can replace it.
+type htmxResponseWriter struct {
+	http.ResponseWriter
+	status    int
+	errorBody bytes.Buffer
+}
+
+func (w *htmxResponseWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	if status < http.StatusBadRequest {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *htmxResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.status >= http.StatusBadRequest {
+		return w.errorBody.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the wrapped writer, so http.ResponseController can reach
+// the connection, e.g. to clear deadlines for streamed responses
+func (w *htmxResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush passes flushes through for streamed responses
+func (w *htmxResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok && w.status < http.StatusBadRequest {
+		flusher.Flush()
+	}
+}
+
+// errorMessage extracts the message from an error response body: the "error"
+// field of a JSON error, the text of a plain one, or the status text
+func errorMessage(status int, body []byte) string {
+	var jsonErr struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &jsonErr) == nil && jsonErr.Error != "" {
+		return jsonErr.Error
+	}
+	if text := strings.TrimSpace(string(body)); text != "" && !strings.HasPrefix(text, "<") {
+		return text
+	}
+	return http.StatusText(status)
+}
+
+// writeHTMXError writes an error fragment that HTMX swaps into the page's
+// error banner instead of the request's usual target
+func writeHTMXError(w http.ResponseWriter, status int, message string) {
+	var buf bytes.Buffer
+	if err := uiTemplates.ExecuteTemplate(&buf, "error-banner", message); err != nil {
+		buf.Reset()
+		buf.WriteString(template.HTMLEscapeString(message))
+	}
+
+	w.Header().Del("Content-Length")
+	w.Header().Set("HX-Retarget", "#error-banner")
+	w.Header().Set("HX-Reswap", "innerHTML")
+	writeHTMX(w, status, buf.String())
+}
+
+// MetricsMiddleware counts requests by matched route pattern, so paths with
+// IDs in them don't each get their own series
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		httpRequestsTotal.WithLabelValues(route, r.Method, strconv.Itoa(status)).Inc()
+	})
+}
+
+// Basic auth credentials, configured with SetBasicAuth. Auth is disabled
+// while both are empty.
+var (
+	authUser string
+	authPass string
+)
+
+// SetBasicAuth requires HTTP basic auth with the given credentials on the API
+// and UI routes. Empty credentials disable auth.
+func SetBasicAuth(user, pass string) {
+	authUser = user
+	authPass = pass
+}
+
+// BasicAuthMiddleware rejects requests without the configured basic auth
+// credentials with a 401 and a WWW-Authenticate challenge
+func BasicAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if authUser == "" && authPass == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !validBasicAuth(r) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="tasks", charset="UTF-8"`)
+			writeErrorJSON(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validBasicAuth reports whether the request carries the configured basic
+// auth credentials
+func validBasicAuth(r *http.Request) bool {
+	user, pass, ok := r.BasicAuth()
+	userMatch := subtle.ConstantTimeCompare([]byte(user), []byte(authUser)) == 1
+	passMatch := subtle.ConstantTimeCompare([]byte(pass), []byte(authPass)) == 1
+	return ok && userMatch && passMatch
+}
+
+// API tokens accepted as bearer tokens, configured with SetAPITokens
+var apiTokens [][]byte
+
+// SetAPITokens requires an "Authorization: Bearer <token>" header matching one
+// of the tokens on the API routes. Blank tokens are ignored; an empty set
+// disables token auth.
+func SetAPITokens(tokens []string) {
+	apiTokens = nil
+	for _, token := range tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			apiTokens = append(apiTokens, []byte(token))
+		}
+	}
+}
+
+// validBearerToken reports whether the request carries one of the configured
+// API tokens. Every token is compared in constant time so the response time
+// does not reveal how much of a token matched.
+func validBearerToken(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	match := 0
+	for _, candidate := range apiTokens {
+		match |= subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), candidate)
+	}
+	return match == 1
+}
+
+// APIAuthMiddleware protects the API routes. A request is let through if it
+// carries a valid bearer token or, when basic auth is configured, valid basic
+// auth credentials. With neither configured every request is allowed.
+func APIAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		basicEnabled := authUser != "" || authPass != ""
+		if len(apiTokens) == 0 && !basicEnabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if (len(apiTokens) > 0 && validBearerToken(r)) || (basicEnabled && validBasicAuth(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(apiTokens) > 0 {
+			w.Header().Add("WWW-Authenticate", `Bearer realm="tasks"`)
+		}
+		if basicEnabled {
+			w.Header().Add("WWW-Authenticate", `Basic realm="tasks", charset="UTF-8"`)
+		}
+		writeErrorJSON(w, http.StatusUnauthorized, "Unauthorized")
+	})
+}
+
+// CORS settings, configured with SetCORS. CORS headers are only sent while
+// at least one origin is allowed.
+var (
+	corsOrigins []string
+	corsMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	corsHeaders = []string{"Content-Type", "Authorization"}
+)
+
+// SetCORS allows cross-origin requests to the API from the given origins ("*"
+// allows any origin). Empty methods or headers keep the defaults.
+func SetCORS(origins, methods, headers []string) {
+	corsOrigins = nil
+	for _, origin := range origins {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			corsOrigins = append(corsOrigins, strings.TrimSuffix(origin, "/"))
+		}
+	}
+	if len(methods) > 0 {
+		corsMethods = methods
+	}
+	if len(headers) > 0 {
+		corsHeaders = headers
+	}
+}
+
+// corsOriginAllowed reports whether cross-origin requests from origin are allowed
+func corsOriginAllowed(origin string) bool {
+	for _, allowed := range corsOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORSMiddleware adds CORS headers for allowed origins and answers preflight
+// requests. It must run before authentication, since browsers send
+// preflights without credentials.
+func CORSMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if len(corsOrigins) == 0 || origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		if !corsOriginAllowed(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, X-Limit, X-Offset, ETag")
+
+		// Answer preflight requests without passing them on
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(corsMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(corsHeaders, ", "))
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
 
-func NewRouter(store *storage.FileStore, staticFS embed.FS) http.Handler {
+func NewRouter(store storage.TaskStore, staticFS embed.FS) http.Handler {
 	r := chi.NewRouter()
+	router := r
 
 	// Middleware
-	r.Use(middleware.Logger)
+	r.Use(AccessLogMiddleware)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RealIP)
 	r.Use(HTMXMiddleware)
+	r.Use(MetricsMiddleware)
+
+	// Prometheus metrics, behind the same auth as the API, so they are never
+	// left open while API tokens are configured
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		httpRequestsTotal,
+		newStoreCollector(store),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	r.With(APIAuthMiddleware).Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	// Health checks, outside the authenticated groups for load balancer probes
+	r.Get("/healthz", HandleHealthz())
+	r.Get("/readyz", HandleReadyz(store))
 
 	// API routes
 	r.Route("/api", func(r chi.Router) {
+		r.Use(CORSMiddleware)
+		r.Use(APIAuthMiddleware)
+
 		r.Route("/lists", func(r chi.Router) {
 			r.Get("/", HandleGetAllLists(store))
 			r.Post("/", HandleCreateList(store))
+			r.Post("/archive-batch", HandleBulkArchiveLists(store))
+			r.Post("/reorder", HandleReorderLists(store))
 			r.Route("/{listID}", func(r chi.Router) {
+				r.Use(ValidateIDParams)
 				r.Get("/", HandleGetList(store))
 				r.Put("/", HandleUpdateList(store))
 				r.Delete("/", HandleDeleteList(store))
+				r.Post("/duplicate", HandleDuplicateList(store))
+				r.Post("/rename-id", HandleRenameListID(store))
 				r.Get("/tasks", HandleGetTasksForList(store))
 				r.Post("/tasks", HandleCreateTask(store))
+				r.Post("/tasks/batch", HandleBatchCreateTasks(store))
+				r.Get("/tasks/{taskID}/siblings", HandleGetTaskSiblings(store))
+				r.Get("/duplicates", HandleGetDuplicateTasks(store))
+				r.Get("/report", HandleListTimeReport(store))
+				r.Get("/export", HandleExportListMarkdown(store))
+				r.Post("/archive-done", HandleArchiveDoneTasks(store))
 			})
 		})
 
 		r.Route("/tasks", func(r chi.Router) {
 			r.Get("/", HandleGetAllTasks(store))
+			r.Get("/filter", HandleFilterTasks(store))
+			r.Post("/bulk", HandleBulkTasks(store))
+			r.Post("/bulk-due", HandleBulkSetDue(store))
+			r.Post("/move-by-filter", HandleMoveTasksByFilter(store))
 			r.Route("/{listID}/{taskID}", func(r chi.Router) {
+				r.Use(ValidateIDParams)
 				r.Get("/", HandleGetTask(store))
 				r.Put("/", HandleUpdateTask(store))
+				r.Patch("/", HandlePatchTask(store))
 				r.Delete("/", HandleDeleteTask(store))
+				r.Post("/duplicate", HandleDuplicateTask(store))
+				r.Post("/move", HandleMoveTask(store))
+				r.Post("/position", HandlePositionTask(store))
+				r.Get("/streak", HandleGetTaskStreak(store))
+				r.Get("/blockers", HandleGetTaskBlockers(store))
+				r.Get("/history", HandleGetTaskHistory(store))
+				r.Get("/attachments", HandleGetAttachments(store))
+				r.Post("/attachments", HandleUploadAttachment(store))
+				r.Get("/attachments/{attachmentID}", HandleDownloadAttachment(store))
+				r.Delete("/attachments/{attachmentID}", HandleDeleteAttachment(store))
+				r.Get("/comments", HandleGetComments(store))
+				r.Post("/comments", HandleCreateComment(store))
+				r.Delete("/comments/{commentID}", HandleDeleteComment(store))
+				r.Post("/notes", HandleCreateNote(store))
+				r.Put("/notes/{noteID}", HandleUpdateNote(store))
+				r.Delete("/notes/{noteID}", HandleDeleteNote(store))
+				r.Post("/subtasks", HandleAddSubTask(store))
+				r.Put("/subtasks/{subTaskID}", HandleUpdateSubTask(store))
+				r.Delete("/subtasks/{subTaskID}", HandleDeleteSubTask(store))
 			})
 		})
 
+		r.Route("/filters", func(r chi.Router) {
+			r.Get("/", HandleGetSavedFilters(store))
+			r.Post("/", HandleSaveFilter(store))
+			r.Delete("/{name}", HandleDeleteSavedFilter(store))
+			r.Get("/{name}/tasks", HandleGetSavedFilterTasks(store))
+		})
+
+		// Search
+		r.Get("/search", HandleSearchTasks(store))
+
+		// Reports
+		r.Get("/reports/by-assignee", HandleAssigneeReport(store))
+		r.Get("/reports/matrix", HandleMatrixReport(store))
+		r.Get("/stats", HandleGetStats(store))
+
+		// Undo endpoints
+		r.Get("/undo", HandleGetUndoHistory(store))
+		r.Post("/undo", HandleUndo(store))
+
+		// Trash endpoints
+		r.Get("/trash", HandleGetTrash(store))
+		r.With(ValidateIDParams).Post("/trash/{taskID}/restore", HandleRestoreTask(store))
+
 		// Export endpoint
-		r.Get("/export", HandleExportMarkdown(store))
+		r.Get("/export", HandleExport(store))
+		r.Get("/export/csv", HandleExportAs(store, "csv"))
+		r.Get("/export/ics", HandleExportAs(store, "ics"))
+		r.Get("/export/json", HandleExportFullJSON(store))
+		r.Post("/import/markdown", HandleImportMarkdown(store))
+		r.Post("/import/json", HandleImportJSON(store))
+
+		// Backup endpoints
+		r.Get("/backup", HandleBackup(store))
+		r.Post("/restore", HandleRestore(store))
 		
 		// OpenAPI specification endpoint
-		r.Get("/openapi", HandleOpenAPISpec(store))
+		r.Get("/openapi", HandleOpenAPISpec(store, router))
+		r.Get("/docs", HandleAPIDocs())
+
+		// Registered routes, for API discovery
+		r.Get("/routes", HandleListRoutes(router))
+
+		// Settings endpoint
+		r.Get("/settings", HandleGetSettings())
 	})
 
 	// Web UI routes
 	r.Route("/", func(r chi.Router) {
+		r.Use(BasicAuthMiddleware)
+
 		// Create a custom file server to set proper content types
 		staticSubFS, err := fs.Sub(staticFS, "web/static")
 		if err != nil {
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// routeInfo describes a single registered route
+type routeInfo struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// collectRoutes walks the router and returns its method+path pairs, sorted by
+// path then method
+func collectRoutes(routes chi.Routes) ([]routeInfo, error) {
+	var infos []routeInfo
+	seen := make(map[routeInfo]bool)
+
+	err := chi.Walk(routes, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		// Mounted sub-routers report their index routes with a trailing slash
+		route = strings.ReplaceAll(route, "/*/", "/")
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+
+		info := routeInfo{Method: method, Path: route}
+		if !seen[info] {
+			seen[info] = true
+			infos = append(infos, info)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Path != infos[j].Path {
+			return infos[i].Path < infos[j].Path
+		}
+		return infos[i].Method < infos[j].Method
+	})
+	return infos, nil
+}
+
+// HandleListRoutes returns every method+path pair registered on the router
+func HandleListRoutes(routes chi.Routes) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		infos, err := collectRoutes(routes)
+		if err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to list routes")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, infos)
+	}
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// searchResult is a task matching a search, annotated with its list name
+type searchResult struct {
+	models.Task
+	ListName string `json:"list_name"`
+}
+
+// HandleSearchTasks returns tasks whose title, description or notes contain
+// ?q= (case-insensitive), optionally restricted by ?list= and ?state=. Lists
+// are scanned one at a time and matches are streamed to the client as they
+// are found, so only a single list's tasks are held in memory.
+func HandleSearchTasks(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Search query is required")
+			return
+		}
+
+		predicates := []taskPredicate{containsText(query)}
+		if state := r.URL.Query().Get("state"); state != "" {
+			predicates = append(predicates, stateIn(models.TaskState(state)))
+		}
+		if r.URL.Query().Get("include_archived") != "true" {
+			predicates = append(predicates, func(task *models.Task) bool { return !task.Archived })
+		}
+		predicate := matchAll(predicates...)
+
+		var lists []models.TaskList
+		if listID := r.URL.Query().Get("list"); listID != "" {
+			list, err := store.GetList(listID)
+			if err != nil {
+				writeStoreError(w, err, "List not found")
+				return
+			}
+			lists = []models.TaskList{*list}
+		} else {
+			var err error
+			lists, err = store.GetAllLists()
+			if err != nil {
+				writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve lists")
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		// Write the array by hand so results can be flushed per list
+		enc := json.NewEncoder(w)
+		flusher, _ := w.(http.Flusher)
+		first := true
+		w.Write([]byte("["))
+		for _, list := range lists {
+			tasks, err := store.GetTasksForList(list.ID)
+			if err != nil {
+				log.Printf("Warning: skipping list %s in search: %v", list.ID, err)
+				continue
+			}
+
+			for i := range tasks {
+				if !predicate(&tasks[i]) {
+					continue
+				}
+				if !first {
+					w.Write([]byte(","))
+				}
+				first = false
+				if err := enc.Encode(searchResult{Task: tasks[i], ListName: list.Name}); err != nil {
+					log.Printf("Warning: search response interrupted: %v", err)
+					return
+				}
+			}
+
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		w.Write([]byte("]\n"))
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// siblingsResponse identifies the tasks either side of a task within a state
+type siblingsResponse struct {
+	TaskID string           `json:"task_id"`
+	State  models.TaskState `json:"state"`
+	Prev   *string          `json:"prev"`
+	Next   *string          `json:"next"`
+}
+
+// HandleGetTaskSiblings returns the previous and next task IDs within the same
+// state (or the state given by ?state=), using position ordering. Prev and
+// next are null at the ends of the column.
+func HandleGetTaskSiblings(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		taskID := chi.URLParam(r, "taskID")
+		if listID == "" || taskID == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Missing list ID or task ID")
+			return
+		}
+
+		tasks, err := store.GetTasksForList(listID)
+		if err != nil {
+			writeStoreError(w, err, "List not found")
+			return
+		}
+
+		state := models.TaskState(r.URL.Query().Get("state"))
+		if state == "" {
+			for _, task := range tasks {
+				if task.ID == taskID {
+					state = task.State
+					break
+				}
+			}
+		}
+
+		column := filterTasks(tasks, stateIn(state))
+		models.SortTasksByPosition(column)
+
+		for i, task := range column {
+			if task.ID != taskID {
+				continue
+			}
+
+			resp := siblingsResponse{TaskID: taskID, State: state}
+			if i > 0 {
+				resp.Prev = &column[i-1].ID
+			}
+			if i < len(column)-1 {
+				resp.Next = &column[i+1].ID
+			}
+			writeJSON(w, http.StatusOK, resp)
+			return
+		}
+
+		writeErrorJSON(w, http.StatusNotFound, "Task not found in state")
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Per-list workflow states
+
+// validateListStates trims a list's custom states, rejecting empty or
+// duplicate names
+func validateListStates(list *models.TaskList) error {
+	if len(list.States) == 0 {
+		list.States = nil
+		return nil
+	}
+
+	seen := make(map[string]bool, len(list.States))
+	for i, state := range list.States {
+		state = strings.TrimSpace(state)
+		if state == "" {
+			return fmt.Errorf("state names cannot be empty")
+		}
+		if seen[state] {
+			return fmt.Errorf("duplicate state: %s", state)
+		}
+		seen[state] = true
+		list.States[i] = state
+	}
+	return nil
+}
+
+// defaultTaskState returns the first workflow state of the list, falling back
+// to todo when the list cannot be read
+func defaultTaskState(store storage.TaskStore, listID string) models.TaskState {
+	list, err := store.GetList(listID)
+	if err != nil {
+		return models.TaskStateTodo
+	}
+	return list.TaskStates()[0]
+}
+
+// validateTaskState rejects states outside the workflow of the task's list,
+// naming the states that are allowed. When the list cannot be read the task
+// is checked against the default states and the store reports the missing
+// list.
+func validateTaskState(store storage.TaskStore, task *models.Task) error {
+	list, err := store.GetList(task.ListID)
+	if err != nil {
+		if !task.State.IsValid() {
+			return fmt.Errorf("invalid state: %s (expected one of %s)", task.State, joinStates(models.DefaultTaskStates))
+		}
+		return nil
+	}
+	return checkStateInList(list, task)
+}
+
+// checkStateInList rejects a task state outside the workflow of list
+func checkStateInList(list *models.TaskList, task *models.Task) error {
+	if !list.AllowsState(task.State) {
+		return fmt.Errorf("invalid state for list: %s (expected one of %s)", task.State, joinStates(list.TaskStates()))
+	}
+	return nil
+}
+
+// joinStates formats states as a comma separated list
+func joinStates(states []models.TaskState) string {
+	names := make([]string, len(states))
+	for i, state := range states {
+		names[i] = string(state)
+	}
+	return strings.Join(names, ", ")
+}
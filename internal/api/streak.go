@@ -0,0 +1,110 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// streakResponse summarizes the completion history of a recurring task
+type streakResponse struct {
+	TaskID        string                `json:"task_id"`
+	Recurrence    models.TaskRecurrence `json:"recurrence"`
+	Completions   int                   `json:"completions"`
+	CurrentStreak int                   `json:"current_streak"`
+	LongestStreak int                   `json:"longest_streak"`
+	LastCompleted *time.Time            `json:"last_completed,omitempty"`
+}
+
+// recurrencePeriod maps a time to the index of the recurrence interval it
+// falls in, so that consecutive intervals have consecutive indexes. Weeks
+// start on Monday.
+func recurrencePeriod(recurrence models.TaskRecurrence, t time.Time) int {
+	t = t.UTC()
+	days := int(t.Unix() / 86400)
+	switch recurrence {
+	case models.TaskRecurrenceWeekly:
+		// 1970-01-01 was a Thursday; shift so weeks start on Monday
+		return (days + 3) / 7
+	case models.TaskRecurrenceMonthly:
+		return t.Year()*12 + int(t.Month()) - 1
+	default:
+		return days
+	}
+}
+
+// computeStreaks returns the current and longest runs of consecutive
+// intervals with at least one completion. The current streak stays alive
+// until the interval after the last completion has passed without one.
+func computeStreaks(recurrence models.TaskRecurrence, completions []time.Time, now time.Time) (current, longest int) {
+	seen := make(map[int]bool)
+	var periods []int
+	for _, completed := range completions {
+		period := recurrencePeriod(recurrence, completed)
+		if !seen[period] {
+			seen[period] = true
+			periods = append(periods, period)
+		}
+	}
+	if len(periods) == 0 {
+		return 0, 0
+	}
+	sort.Ints(periods)
+
+	run := 0
+	for i, period := range periods {
+		if i > 0 && period == periods[i-1]+1 {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	if last := periods[len(periods)-1]; last >= recurrencePeriod(recurrence, now)-1 {
+		current = run
+	}
+	return current, longest
+}
+
+// HandleGetTaskStreak returns the current and longest completion streaks of a
+// recurring task
+func HandleGetTaskStreak(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		listID := chi.URLParam(r, "listID")
+		taskID := chi.URLParam(r, "taskID")
+		if listID == "" || taskID == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Missing list ID or task ID")
+			return
+		}
+
+		task, err := store.GetTask(listID, taskID)
+		if err != nil {
+			writeStoreError(w, err, "Task not found")
+			return
+		}
+
+		if task.Recurrence == "" {
+			writeErrorJSON(w, http.StatusBadRequest, "Task is not recurring")
+			return
+		}
+
+		resp := streakResponse{
+			TaskID:      task.ID,
+			Recurrence:  task.Recurrence,
+			Completions: len(task.CompletionHistory),
+		}
+		resp.CurrentStreak, resp.LongestStreak = computeStreaks(task.Recurrence, task.CompletionHistory, time.Now())
+		for i := range task.CompletionHistory {
+			if resp.LastCompleted == nil || task.CompletionHistory[i].After(*resp.LastCompleted) {
+				resp.LastCompleted = &task.CompletionHistory[i]
+			}
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
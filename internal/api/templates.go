@@ -0,0 +1,60 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Description templates
+
+// parseDescriptionTemplate parses a list's description template. The template
+// is executed with the new task as data, e.g. "Steps for {{.Title}}".
+func parseDescriptionTemplate(text string) (*template.Template, error) {
+	return template.New("description").Option("missingkey=error").Parse(text)
+}
+
+// validateDescriptionTemplate checks that a list's description template parses
+func validateDescriptionTemplate(list *models.TaskList) error {
+	if strings.TrimSpace(list.DescriptionTemplate) == "" {
+		list.DescriptionTemplate = ""
+		return nil
+	}
+	if _, err := parseDescriptionTemplate(list.DescriptionTemplate); err != nil {
+		return fmt.Errorf("invalid description template: %w", err)
+	}
+	return nil
+}
+
+// applyDescriptionTemplate fills in the description of a task created without
+// one from its list's template. Rendering failures are logged and leave the
+// description empty rather than failing the task creation.
+func applyDescriptionTemplate(store storage.TaskStore, task *models.Task) {
+	if task.Description != "" {
+		return
+	}
+
+	list, err := store.GetList(task.ListID)
+	if err != nil || list.DescriptionTemplate == "" {
+		return
+	}
+
+	tmpl, err := parseDescriptionTemplate(list.DescriptionTemplate)
+	if err != nil {
+		log.Printf("Warning: invalid description template for list %s: %v", list.ID, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, task); err != nil {
+		log.Printf("Warning: failed to render description template for list %s: %v", list.ID, err)
+		return
+	}
+
+	task.Description = buf.String()
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Trash Handlers
+
+// HandleGetTrash returns the deleted tasks, most recently deleted first
+func HandleGetTrash(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tasks, err := store.GetTrash()
+		if err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve trash")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, tasks)
+	}
+}
+
+// HandleRestoreTask moves a deleted task out of the trash and back into its list
+func HandleRestoreTask(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		task, err := store.RestoreTask(chi.URLParam(r, "taskID"))
+		if err != nil {
+			if errors.Is(err, storage.ErrNotInTrash) {
+				writeErrorJSON(w, http.StatusNotFound, "Task not found in trash")
+				return
+			}
+			if errors.Is(err, storage.ErrInvalidID) {
+				writeErrorJSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeErrorJSON(w, http.StatusConflict, "Failed to restore task: "+err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, task)
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// HTML templates
+
+//go:embed ui/*.html
+var uiFiles embed.FS
+
+// uiTemplates holds the page and fragment templates. html/template escapes
+// every value by context, so user content can't inject markup or script.
+var uiTemplates = template.Must(template.New("ui").Funcs(template.FuncMap{
+	"stateTitle": stateToTitle,
+	"overdue": func(task models.Task) bool {
+		return isOverdue(&task, time.Now())
+	},
+	"minutes": formatMinutes,
+}).ParseFS(uiFiles, "ui/*.html"))
+
+// formatMinutes renders an effort in minutes compactly, e.g. 45m, 3h or 1h30m
+func formatMinutes(minutes int) string {
+	hours, rest := minutes/60, minutes%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", rest)
+	case rest == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh%dm", hours, rest)
+	}
+}
+
+// pageData is the data passed to the full page templates; each page uses
+// the fields it needs
+type pageData struct {
+	Title     string
+	Nav       []navLink
+	List      *models.TaskList  // The list a page is about, if any
+	Lists     []models.TaskList // Lists for the list filter or lists page
+	Tasks     []models.Task
+	ListNames map[string]string // List names by ID, for labelling tasks
+	States    []models.TaskState
+	Columns   []kanbanColumn
+}
+
+// navLink is one link in the page header
+type navLink struct {
+	Label    string
+	Href     string
+	External bool             // Opens in a new tab
+	List     *models.TaskList // Shows the list's color and icon, for links to a list's views
+}
+
+// pageNav returns the header links for a page. Pages about a list link to
+// its other view; kanban is set on the list's kanban page.
+func pageNav(list *models.TaskList, kanban bool) []navLink {
+	nav := []navLink{
+		{Label: "All Tasks", Href: "/"},
+		{Label: "Task Lists", Href: "/lists"},
+	}
+	switch {
+	case list == nil:
+		nav = append(nav, navLink{Label: "Kanban View", Href: "/all-kanban"})
+	case kanban:
+		nav = append(nav,
+			navLink{Label: "All Kanban", Href: "/all-kanban"},
+			navLink{Label: "List View", Href: "/lists/" + list.ID, List: list},
+		)
+	default:
+		nav = append(nav, navLink{Label: "Kanban View", Href: "/kanban/" + list.ID, List: list})
+	}
+	return append(nav, navLink{Label: "API Docs", Href: "/api/docs"})
+}
+
+// kanbanColumn is the data for one column of a kanban board
+type kanbanColumn struct {
+	State models.TaskState
+	Tasks []models.Task
+}
+
+// kanbanColumns groups tasks into one column per workflow state, each in
+// position order
+func kanbanColumns(states []models.TaskState, tasks []models.Task) []kanbanColumn {
+	tasksByState := make(map[models.TaskState][]models.Task)
+	for _, task := range tasks {
+		tasksByState[task.State] = append(tasksByState[task.State], task)
+	}
+
+	columns := make([]kanbanColumn, 0, len(states))
+	for _, state := range states {
+		column := tasksByState[state]
+		models.SortTasksByPosition(column)
+		columns = append(columns, kanbanColumn{State: state, Tasks: column})
+	}
+	return columns
+}
+
+// listNames maps list IDs to list names
+func listNames(lists []models.TaskList) map[string]string {
+	names := make(map[string]string, len(lists))
+	for _, list := range lists {
+		names[list.ID] = list.Name
+	}
+	return names
+}
+
+// renderTemplate executes a named template and writes it as an HTMX response.
+// The output is buffered so a template error can still produce a 500.
+func renderTemplate(w http.ResponseWriter, status int, name string, data interface{}) {
+	var buf bytes.Buffer
+	if err := uiTemplates.ExecuteTemplate(&buf, name, data); err != nil {
+		http.Error(w, "Error rendering page", http.StatusInternalServerError)
+		return
+	}
+	writeHTMX(w, status, buf.String())
+}
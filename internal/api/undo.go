@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jbutlerdev/tasks/internal/storage"
+)
+
+// Undo Handlers
+
+// HandleGetUndoHistory returns the changes that can be undone, most recent first
+func HandleGetUndoHistory(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		history, err := store.UndoHistory()
+		if err != nil {
+			writeErrorJSON(w, http.StatusInternalServerError, "Failed to retrieve undo history")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, history)
+	}
+}
+
+// HandleUndo reverts the most recent recorded change. A change that can no
+// longer be reverted, such as an update to a task that has been edited
+// since, is reported as a conflict and left in the journal.
+func HandleUndo(store storage.TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entry, err := store.Undo()
+		if err != nil {
+			if errors.Is(err, storage.ErrNothingToUndo) {
+				writeErrorJSON(w, http.StatusNotFound, "Nothing to undo")
+				return
+			}
+			writeErrorJSON(w, http.StatusConflict, "Failed to undo: "+err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, entry)
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// TaskFilter holds task filter criteria. Empty fields are ignored and the
+// remaining criteria are combined with AND semantics.
+type TaskFilter struct {
+	State     string `json:"state,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	Assignee  string `json:"assignee,omitempty"`
+	Priority  string `json:"priority,omitempty"`
+	DueBefore string `json:"due_before,omitempty"`
+	HasDue    string `json:"has_due,omitempty"`
+	Query     string `json:"q,omitempty"`
+}
+
+// SavedFilter is a named set of filter criteria
+type SavedFilter struct {
+	Name      string     `json:"name"`
+	Criteria  TaskFilter `json:"criteria"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
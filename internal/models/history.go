@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HistoryEntry records a change to one field of a task
+type HistoryEntry struct {
+	Field     string    `json:"field"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// RecordChanges appends a history entry for each tracked field that differs
+// from previous. The existing history is always taken from previous, so an
+// update can extend the history but never rewrite it.
+func (t *Task) RecordChanges(previous *Task, now time.Time) {
+	t.History = previous.History
+
+	record := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			t.History = append(t.History, HistoryEntry{
+				Field:     field,
+				OldValue:  oldValue,
+				NewValue:  newValue,
+				ChangedAt: now,
+			})
+		}
+	}
+
+	record("title", previous.Title, t.Title)
+	record("description", previous.Description, t.Description)
+	record("state", string(previous.State), string(t.State))
+	record("list_id", previous.ListID, t.ListID)
+	record("start_date", formatHistoryTime(previous.StartDate), formatHistoryTime(t.StartDate))
+	record("due_date", formatHistoryTime(previous.DueDate), formatHistoryTime(t.DueDate))
+	record("priority", string(previous.Priority), string(t.Priority))
+	record("flagged", fmt.Sprint(previous.Flagged), fmt.Sprint(t.Flagged))
+	record("assignee", previous.Assignee, t.Assignee)
+	record("tags", strings.Join(previous.Tags, ", "), strings.Join(t.Tags, ", "))
+	record("blocked_reason", previous.BlockedReason, t.BlockedReason)
+	record("depends_on", strings.Join(previous.DependsOn, ", "), strings.Join(t.DependsOn, ", "))
+	record("recurrence", string(previous.Recurrence), string(t.Recurrence))
+	record("position", fmt.Sprint(previous.Position), fmt.Sprint(t.Position))
+	record("estimate_minutes", fmt.Sprint(previous.EstimateMinutes), fmt.Sprint(t.EstimateMinutes))
+	record("spent_minutes", fmt.Sprint(previous.SpentMinutes), fmt.Sprint(t.SpentMinutes))
+}
+
+// formatHistoryTime formats an optional time for a history entry
+func formatHistoryTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"maps"
+	"slices"
+	"sort"
 	"time"
 )
 
@@ -13,18 +16,73 @@ const (
 	TaskStateBlocked    TaskState = "blocked"
 )
 
+// DefaultTaskStates are the workflow columns used by lists that do not
+// configure their own
+var DefaultTaskStates = []TaskState{TaskStateTodo, TaskStateInProgress, TaskStateBlocked, TaskStateDone}
+
+// IsValid reports whether s is one of DefaultTaskStates. Lists with custom
+// workflows are checked with TaskList.AllowsState instead.
+func (s TaskState) IsValid() bool {
+	for _, state := range DefaultTaskStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+type TaskPriority string
+
+const (
+	TaskPriorityLow    TaskPriority = "low"
+	TaskPriorityMedium TaskPriority = "medium"
+	TaskPriorityHigh   TaskPriority = "high"
+	TaskPriorityUrgent TaskPriority = "urgent"
+)
+
+type TaskRecurrence string
+
+const (
+	TaskRecurrenceDaily   TaskRecurrence = "daily"
+	TaskRecurrenceWeekly  TaskRecurrence = "weekly"
+	TaskRecurrenceMonthly TaskRecurrence = "monthly"
+)
+
 type Task struct {
-	ID          string     `json:"id"`
-	Title       string     `json:"title"`
-	Description string     `json:"description,omitempty"`
-	ListID      string     `json:"list_id"`
-	State       TaskState  `json:"state"`
-	StateTime   time.Time  `json:"state_time"` // When this state was set
-	DueDate     *time.Time `json:"due_date,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	Notes       []Note     `json:"notes,omitempty"`
-	SubTasks    []Task     `json:"sub_tasks,omitempty"`
+	ID                string           `json:"id"`
+	Title             string           `json:"title"`
+	Description       string           `json:"description,omitempty"`
+	ListID            string           `json:"list_id"`
+	State             TaskState        `json:"state"`
+	StateTime         time.Time        `json:"state_time"`             // When this state was set
+	CompletedAt       *time.Time       `json:"completed_at,omitempty"` // When the task last moved into done; cleared when it leaves done
+	Archived          bool             `json:"archived,omitempty"`     // Hidden from default task queries, see HandleArchiveDoneTasks
+	ArchivedAt        *time.Time       `json:"archived_at,omitempty"`
+	StartDate         *time.Time       `json:"start_date,omitempty"` // Before this the task isn't actionable yet; see Ready
+	DueDate           *time.Time       `json:"due_date,omitempty"`
+	ReminderSent      *time.Time       `json:"reminder_sent,omitempty"` // When a reminder for the current due date was sent; cleared when the due date changes
+	Priority          TaskPriority     `json:"priority,omitempty"`
+	Flagged           bool             `json:"flagged,omitempty"` // Marked by the user for emphasis in the UI
+	Assignee          string           `json:"assignee,omitempty"`
+	Tags              []string         `json:"tags,omitempty"`
+	BlockedReason     string           `json:"blocked_reason,omitempty"`   // Why the task is blocked; only kept while blocked
+	DependsOn         []string         `json:"depends_on,omitempty"`       // IDs of tasks that must be done before this one can start
+	Position          int              `json:"position,omitempty"`         // Manual ordering within a list; ties fall back to creation time
+	EstimateMinutes   int              `json:"estimate_minutes,omitempty"` // Planned effort
+	SpentMinutes      int              `json:"spent_minutes,omitempty"`    // Effort spent so far
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+	Version           int              `json:"version"` // Incremented by the store on every save; updates carrying a stale version are rejected
+	Notes             []Note           `json:"notes,omitempty"`
+	Comments          []Comment        `json:"comments,omitempty"`
+	SubTasks          []Task           `json:"sub_tasks,omitempty"`
+	ParentID          string           `json:"parent_id,omitempty"` // Set when a subtask is hoisted out of its parent
+	Recurrence        TaskRecurrence   `json:"recurrence,omitempty"`
+	CompletionHistory []time.Time      `json:"completion_history,omitempty"` // When each occurrence of a recurring task was completed
+	DeletedAt         *time.Time       `json:"deleted_at,omitempty"`         // Set while the task is in the trash
+	History           []HistoryEntry   `json:"history,omitempty"`            // Field changes, oldest first; appended by the store on update
+	StateSeconds      map[string]int64 `json:"state_seconds,omitempty"`      // Seconds spent in earlier states, excluding the current one
+	Attachments       []Attachment     `json:"attachments,omitempty"`
 }
 
 type Note struct {
@@ -34,12 +92,110 @@ type Note struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-type TaskList struct {
+// Comment is a remark left on a task by a collaborator. Unlike notes,
+// comments are not edited once posted.
+type Comment struct {
+	ID        string    `json:"id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Attachment describes a file uploaded to a task. The content is kept by the
+// store, keyed by the attachment ID.
+type Attachment struct {
 	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Filename    string    `json:"filename"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+type TaskList struct {
+	ID                  string     `json:"id"`
+	Name                string     `json:"name"`
+	Description         string     `json:"description,omitempty"`
+	Color               string     `json:"color,omitempty"`                // Hex color, e.g. #10b981, used to tell lists apart in the UI
+	Icon                string     `json:"icon,omitempty"`                 // Short label or emoji shown before the name in the UI
+	DescriptionTemplate string     `json:"description_template,omitempty"` // text/template applied to new tasks without a description
+	States              []string   `json:"states,omitempty"`               // Custom workflow columns; empty means DefaultTaskStates
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	Order               int        `json:"order,omitempty"`         // Display position set by reordering; unordered lists follow ordered ones
+	AutoComplete        bool       `json:"auto_complete,omitempty"` // Move tasks to done once all their subtasks are done
+	Archived            bool       `json:"archived,omitempty"`
+	ArchivedAt          *time.Time `json:"archived_at,omitempty"`
+	Error               string     `json:"error,omitempty"` // Set on placeholders for lists that could not be read
+}
+
+// TaskStates returns the list's workflow states in column order
+func (l *TaskList) TaskStates() []TaskState {
+	if len(l.States) == 0 {
+		return DefaultTaskStates
+	}
+	states := make([]TaskState, len(l.States))
+	for i, state := range l.States {
+		states[i] = TaskState(state)
+	}
+	return states
+}
+
+// AllowsState reports whether tasks in the list may be in the given state
+func (l *TaskList) AllowsState(state TaskState) bool {
+	if len(l.States) == 0 {
+		return state.IsValid()
+	}
+	for _, s := range l.TaskStates() {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Clone returns a deep copy of the task, so changes to its slices and maps
+// don't affect the original
+func (t *Task) Clone() Task {
+	clone := *t
+	if t.StartDate != nil {
+		start := *t.StartDate
+		clone.StartDate = &start
+	}
+	if t.DueDate != nil {
+		due := *t.DueDate
+		clone.DueDate = &due
+	}
+	if t.CompletedAt != nil {
+		completedAt := *t.CompletedAt
+		clone.CompletedAt = &completedAt
+	}
+	if t.ArchivedAt != nil {
+		archivedAt := *t.ArchivedAt
+		clone.ArchivedAt = &archivedAt
+	}
+	if t.DeletedAt != nil {
+		deletedAt := *t.DeletedAt
+		clone.DeletedAt = &deletedAt
+	}
+	if t.ReminderSent != nil {
+		reminderSent := *t.ReminderSent
+		clone.ReminderSent = &reminderSent
+	}
+	clone.Tags = slices.Clone(t.Tags)
+	clone.DependsOn = slices.Clone(t.DependsOn)
+	clone.Notes = slices.Clone(t.Notes)
+	clone.Comments = slices.Clone(t.Comments)
+	clone.CompletionHistory = slices.Clone(t.CompletionHistory)
+	clone.History = slices.Clone(t.History)
+	clone.StateSeconds = maps.Clone(t.StateSeconds)
+	clone.Attachments = slices.Clone(t.Attachments)
+	if t.SubTasks != nil {
+		clone.SubTasks = make([]Task, len(t.SubTasks))
+		for i := range t.SubTasks {
+			clone.SubTasks[i] = t.SubTasks[i].Clone()
+		}
+	}
+	return clone
 }
 
 // Time helper functions
@@ -49,9 +205,239 @@ func (t *Task) TimeInState() time.Duration {
 	return time.Since(t.StateTime)
 }
 
-// SetState updates the task state and resets the state timer
+// RecordStateTime carries the accumulated state durations over from the
+// previous version of the task, adding the time spent in the previous state
+// when the state changed
+func (t *Task) RecordStateTime(previous *Task, now time.Time) {
+	t.StateSeconds = previous.StateSeconds
+	if previous.State == t.State || previous.StateTime.IsZero() {
+		return
+	}
+
+	seconds := int64(now.Sub(previous.StateTime) / time.Second)
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	durations := make(map[string]int64, len(previous.StateSeconds)+1)
+	for state, s := range previous.StateSeconds {
+		durations[state] = s
+	}
+	durations[string(previous.State)] += seconds
+	t.StateSeconds = durations
+}
+
+// RecordCompletion maintains CompletedAt: it is set when the task moves into
+// done, kept while it stays done and cleared when it leaves. previous is nil
+// for new tasks, which keep a completion time they already carry.
+func (t *Task) RecordCompletion(previous *Task, now time.Time) {
+	switch {
+	case t.State != TaskStateDone:
+		t.CompletedAt = nil
+	case previous == nil:
+		if t.CompletedAt == nil {
+			t.CompletedAt = &now
+		}
+	case previous.State == TaskStateDone:
+		// Unknown for tasks completed before CompletedAt was recorded
+		t.CompletedAt = previous.CompletedAt
+	default:
+		t.CompletedAt = &now
+	}
+}
+
+// RecordArchive maintains ArchivedAt: it is set when the task is archived,
+// kept while it stays archived and cleared when it is unarchived
+func (t *Task) RecordArchive(previous *Task, now time.Time) {
+	switch {
+	case !t.Archived:
+		t.ArchivedAt = nil
+	case previous != nil && previous.Archived:
+		t.ArchivedAt = previous.ArchivedAt
+	case t.ArchivedAt == nil:
+		t.ArchivedAt = &now
+	}
+}
+
+// RecordReminder maintains ReminderSent, which only the reminder scheduler
+// sets: it is kept while the due date stays the same and cleared when the due
+// date changes, so the new date gets its own reminder. New tasks (previous is
+// nil) start without one.
+func (t *Task) RecordReminder(previous *Task) {
+	if previous == nil || !sameTime(previous.DueDate, t.DueDate) {
+		t.ReminderSent = nil
+		return
+	}
+	t.ReminderSent = previous.ReminderSent
+}
+
+// sameTime reports whether two optional times are both unset or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// TimeInStates returns the total time spent in each state, including the
+// time so far in the current state
+func (t *Task) TimeInStates(now time.Time) map[TaskState]time.Duration {
+	durations := make(map[TaskState]time.Duration, len(t.StateSeconds)+1)
+	for state, seconds := range t.StateSeconds {
+		durations[TaskState(state)] = time.Duration(seconds) * time.Second
+	}
+	if !t.StateTime.IsZero() {
+		durations[t.State] += now.Sub(t.StateTime)
+	}
+	return durations
+}
+
+// SortTasksByPosition orders tasks by position, then creation time, then ID
+func SortTasksByPosition(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Position != tasks[j].Position {
+			return tasks[i].Position < tasks[j].Position
+		}
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+}
+
+// SortTasksByCreation orders tasks by creation time, then ID, the order the
+// stores return a list's tasks in
+func SortTasksByCreation(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+}
+
+// Valid reports whether p is empty or one of the supported priorities
+func (p TaskPriority) Valid() bool {
+	switch p {
+	case "", TaskPriorityLow, TaskPriorityMedium, TaskPriorityHigh, TaskPriorityUrgent:
+		return true
+	}
+	return false
+}
+
+// Rank orders priorities from low (0) to urgent (3). Tasks without a priority,
+// such as those saved before priorities existed, rank as medium.
+func (p TaskPriority) Rank() int {
+	switch p {
+	case TaskPriorityLow:
+		return 0
+	case TaskPriorityHigh:
+		return 2
+	case TaskPriorityUrgent:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// SortTasksByPriority orders tasks from most to least urgent, keeping the
+// existing order among tasks of equal priority
+func SortTasksByPriority(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Priority.Rank() > tasks[j].Priority.Rank()
+	})
+}
+
+// SortLists orders lists by their Order, placing lists that have never been
+// reordered after the rest, then by creation time, name and ID
+func SortLists(lists []TaskList) {
+	sort.SliceStable(lists, func(i, j int) bool {
+		if lists[i].Order != lists[j].Order {
+			if lists[i].Order == 0 || lists[j].Order == 0 {
+				return lists[j].Order == 0
+			}
+			return lists[i].Order < lists[j].Order
+		}
+		if !lists[i].CreatedAt.Equal(lists[j].CreatedAt) {
+			return lists[i].CreatedAt.Before(lists[j].CreatedAt)
+		}
+		if lists[i].Name != lists[j].Name {
+			return lists[i].Name < lists[j].Name
+		}
+		return lists[i].ID < lists[j].ID
+	})
+}
+
+// Valid reports whether r is empty or a supported recurrence
+func (r TaskRecurrence) Valid() bool {
+	switch r {
+	case "", TaskRecurrenceDaily, TaskRecurrenceWeekly, TaskRecurrenceMonthly:
+		return true
+	}
+	return false
+}
+
+// Next returns t advanced by one recurrence interval
+func (r TaskRecurrence) Next(t time.Time) time.Time {
+	switch r {
+	case TaskRecurrenceDaily:
+		return t.AddDate(0, 0, 1)
+	case TaskRecurrenceWeekly:
+		return t.AddDate(0, 0, 7)
+	case TaskRecurrenceMonthly:
+		return t.AddDate(0, 1, 0)
+	}
+	return t
+}
+
+// CompleteOccurrence records the completion of a recurring task and
+// regenerates it: the task returns to todo and its start and due dates, if
+// any, move forward by one interval
+func (t *Task) CompleteOccurrence(now time.Time) {
+	t.CompletionHistory = append(t.CompletionHistory, now)
+	if t.StartDate != nil {
+		next := t.Recurrence.Next(*t.StartDate)
+		t.StartDate = &next
+	}
+	if t.DueDate != nil {
+		next := t.Recurrence.Next(*t.DueDate)
+		t.DueDate = &next
+		t.ReminderSent = nil
+	}
+	t.State = TaskStateTodo
+	t.StateTime = now
+	t.UpdatedAt = now
+}
+
+// Ready reports whether the task is actionable at now, that is it has no
+// start date or its start date has arrived
+func (t *Task) Ready(now time.Time) bool {
+	return t.StartDate == nil || !t.StartDate.After(now)
+}
+
+// SubTaskProgress returns the fraction of subtasks that are done, or nil for
+// a task without subtasks
+func (t *Task) SubTaskProgress() *float64 {
+	if len(t.SubTasks) == 0 {
+		return nil
+	}
+	done := 0
+	for _, subTask := range t.SubTasks {
+		if subTask.State == TaskStateDone {
+			done++
+		}
+	}
+	progress := float64(done) / float64(len(t.SubTasks))
+	return &progress
+}
+
+// SetState updates the task state, resets the state timer and maintains
+// CompletedAt
 func (t *Task) SetState(state TaskState) {
+	now := time.Now()
+	previous := *t
 	t.State = state
-	t.StateTime = time.Now()
-	t.UpdatedAt = time.Now()
-}
\ No newline at end of file
+	t.StateTime = now
+	t.UpdatedAt = now
+	t.RecordCompletion(&previous, now)
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// archiveDone archives a done task that isn't archived yet, reporting
+// whether it changed
+func archiveDone(task *models.Task, now time.Time) bool {
+	if task.State != models.TaskStateDone || task.Archived {
+		return false
+	}
+	previous := *task
+	task.Archived = true
+	task.ArchivedAt = &now
+	task.UpdatedAt = now
+	task.Version++
+	task.RecordChanges(&previous, now)
+	return true
+}
+
+// checkUnarchive reports whether current, a task as stored now, is still as
+// archiving snapshot left it, so an archive entry can be undone
+func checkUnarchive(snapshot models.Task, current *models.Task) error {
+	if current.ListID != snapshot.ListID || current.Version != snapshot.Version+1 {
+		return fmt.Errorf("task %s has changed since (version %d, expected %d)", current.ID, current.Version, snapshot.Version+1)
+	}
+	return nil
+}
+
+// ArchiveDoneTasks archives every done task of a list that isn't archived
+// yet and returns the archived tasks. The tasks are archived together, as a
+// single undo entry; if any can't be written none are archived.
+func (fs *FileStore) ArchiveDoneTasks(listID string) ([]models.Task, error) {
+	if err := ValidateID(listID); err != nil {
+		return nil, err
+	}
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if _, err := fs.readList(listID); err != nil {
+		return nil, err
+	}
+	tasks, err := fs.readTasksForList(listID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	archived := make([]models.Task, 0)
+	var before []models.Task
+	for i := range tasks {
+		snapshot := tasks[i].Clone()
+		if archiveDone(&tasks[i], now) {
+			before = append(before, snapshot)
+			archived = append(archived, tasks[i])
+		}
+	}
+	if len(archived) == 0 {
+		return archived, nil
+	}
+
+	if err := fs.writeTasks(archived, before); err != nil {
+		return nil, err
+	}
+	fs.recordUndo(UndoEntry{Kind: UndoArchiveTasks, Tasks: before})
+	return archived, nil
+}
+
+// writeTasks saves tasks over their stored copies, previous, putting back
+// the copies already overwritten if one of the writes fails. Callers must
+// hold the lock.
+func (fs *FileStore) writeTasks(tasks, previous []models.Task) error {
+	taskPath := func(task *models.Task) string {
+		return filepath.Join(fs.baseDir, "lists", task.ListID, "tasks", task.ID+".json")
+	}
+	for i := range tasks {
+		data, err := fs.marshal(tasks[i])
+		if err == nil {
+			err = fs.writeFile(taskPath(&tasks[i]), data, 0644)
+		}
+		if err != nil {
+			for j := i - 1; j >= 0; j-- {
+				if data, merr := fs.marshal(previous[j]); merr == nil {
+					fs.writeFile(taskPath(&previous[j]), data, 0644)
+				}
+			}
+			return fmt.Errorf("failed to write task file: %w", err)
+		}
+	}
+	for i := range tasks {
+		fs.index.put(&tasks[i])
+	}
+	return nil
+}
+
+// unarchiveTasks reverts the tasks an archive entry recorded, refusing if
+// any has changed since
+func (fs *FileStore) unarchiveTasks(entry UndoEntry) error {
+	current := make([]models.Task, len(entry.Tasks))
+	for i, snapshot := range entry.Tasks {
+		task, err := fs.findTask(snapshot.ID)
+		if err != nil {
+			return fmt.Errorf("task no longer exists: %s", snapshot.ID)
+		}
+		if err := checkUnarchive(snapshot, task); err != nil {
+			return err
+		}
+		current[i] = *task
+	}
+
+	now := time.Now()
+	reverted := make([]models.Task, len(entry.Tasks))
+	for i := range entry.Tasks {
+		reverted[i] = entry.Tasks[i].Clone()
+		revertTask(&reverted[i], &current[i], now)
+	}
+	return fs.writeTasks(reverted, current)
+}
+
+// ArchiveDoneTasks archives every done task of a list that isn't archived
+// yet and returns the archived tasks, in one transaction and undo entry
+func (s *SQLiteStore) ArchiveDoneTasks(listID string) ([]models.Task, error) {
+	if err := ValidateID(listID); err != nil {
+		return nil, err
+	}
+	archived := make([]models.Task, 0)
+	err := s.inTx(func(tx *sql.Tx) error {
+		exists, err := listExists(tx, listID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrListNotFound, listID)
+		}
+		tasks, err := queryTasks(tx, s.strict, `WHERE t.list_id = ?`, listID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var before []models.Task
+		for i := range tasks {
+			snapshot := tasks[i].Clone()
+			if !archiveDone(&tasks[i], now) {
+				continue
+			}
+			if err := putTask(tx, &tasks[i]); err != nil {
+				return err
+			}
+			before = append(before, snapshot)
+			archived = append(archived, tasks[i])
+		}
+		if len(before) == 0 {
+			return nil
+		}
+		return recordSQLiteUndo(tx, UndoEntry{Kind: UndoArchiveTasks, Tasks: before})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return archived, nil
+}
+
+// unarchiveTasks reverts the tasks an archive entry recorded, refusing if
+// any has changed since
+func (s *SQLiteStore) unarchiveTasks(tx *sql.Tx, entry UndoEntry) error {
+	now := time.Now()
+	for _, snapshot := range entry.Tasks {
+		current, err := getTask(tx, snapshot.ID)
+		if err != nil {
+			return fmt.Errorf("task no longer exists: %s", snapshot.ID)
+		}
+		if err := checkUnarchive(snapshot, current); err != nil {
+			return err
+		}
+		task := snapshot.Clone()
+		revertTask(&task, current, now)
+		if err := putTask(tx, &task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// createDoneTask creates a done task in listID with the given ID
+func createDoneTask(t *testing.T, store TaskStore, listID, id string) {
+	t.Helper()
+	task := newTestTask(listID, id)
+	task.State = models.TaskStateDone
+	if err := store.CreateTask(task); err != nil {
+		t.Fatalf("CreateTask(%s/%s): %v", listID, id, err)
+	}
+}
+
+func TestArchiveDoneTasksIsOneUndoEntry(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store TaskStore) {
+		createTestList(t, store, "a")
+		createDoneTask(t, store, "a", "done-1")
+		createDoneTask(t, store, "a", "done-2")
+		createTestTask(t, store, "a", "open")
+
+		archived, err := store.ArchiveDoneTasks("a")
+		if err != nil {
+			t.Fatalf("ArchiveDoneTasks: %v", err)
+		}
+		if len(archived) != 2 {
+			t.Fatalf("archived %d tasks, want 2", len(archived))
+		}
+		for _, id := range []string{"done-1", "done-2"} {
+			if task, err := store.GetTask("a", id); err != nil || !task.Archived || task.ArchivedAt == nil {
+				t.Errorf("task %s after archiving: %+v, %v", id, task, err)
+			}
+		}
+		if again, err := store.ArchiveDoneTasks("a"); err != nil || len(again) != 0 {
+			t.Errorf("archiving again: %d tasks, %v; want none", len(again), err)
+		}
+
+		history, err := store.UndoHistory()
+		if err != nil || len(history) == 0 || history[0].Kind != UndoArchiveTasks || len(history[0].Tasks) != 2 {
+			t.Fatalf("undo history after archiving: %+v, %v", history, err)
+		}
+		if _, err := store.Undo(); err != nil {
+			t.Fatalf("Undo: %v", err)
+		}
+		for _, id := range []string{"done-1", "done-2"} {
+			if task, err := store.GetTask("a", id); err != nil || task.Archived {
+				t.Errorf("task %s after undo: %+v, %v; want it unarchived", id, task, err)
+			}
+		}
+	})
+}
+
+func TestUndoArchiveRefusesChangedTasks(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store TaskStore) {
+		createTestList(t, store, "a")
+		createDoneTask(t, store, "a", "done-1")
+		createDoneTask(t, store, "a", "done-2")
+		if _, err := store.ArchiveDoneTasks("a"); err != nil {
+			t.Fatalf("ArchiveDoneTasks: %v", err)
+		}
+
+		// Edit one task without journaling it, as an attachment upload would
+		task, err := store.GetTask("a", "done-2")
+		if err != nil {
+			t.Fatalf("GetTask: %v", err)
+		}
+		if _, err := store.PositionTask("a", task.ID, 0); err != nil {
+			t.Fatalf("PositionTask: %v", err)
+		}
+
+		if _, err := store.Undo(); err == nil {
+			t.Fatal("Undo succeeded after a task changed, want an error")
+		}
+		for _, id := range []string{"done-1", "done-2"} {
+			if task, err := store.GetTask("a", id); err != nil || !task.Archived {
+				t.Errorf("task %s after refused undo: %+v, %v; want it still archived", id, task, err)
+			}
+		}
+	})
+}
+
+func TestFileStoreArchiveDoneTasksIsAllOrNothing(t *testing.T) {
+	fs := newTestFileStore(t)
+	createTestList(t, fs, "a")
+	createDoneTask(t, fs, "a", "done-1")
+	createDoneTask(t, fs, "a", "done-2")
+
+	// Make the write of done-2 fail by putting a non-empty directory where
+	// its file is renamed to; the index still holds the task
+	taskPath := filepath.Join(fs.baseDir, "lists", "a", "tasks", "done-2.json")
+	if err := os.Remove(taskPath); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(taskPath, "blocker"), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	if _, err := fs.ArchiveDoneTasks("a"); err == nil {
+		t.Fatal("ArchiveDoneTasks succeeded with an unwritable task")
+	}
+
+	data, err := os.ReadFile(filepath.Join(fs.baseDir, "lists", "a", "tasks", "done-1.json"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var task models.Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if task.Archived {
+		t.Error("done-1 left archived on disk after a failed archive")
+	}
+	if task, err := fs.GetTask("a", "done-1"); err != nil || task.Archived {
+		t.Errorf("done-1 after a failed archive: %+v, %v", task, err)
+	}
+	if history, _ := fs.UndoHistory(); len(history) > 0 && history[0].Kind == UndoArchiveTasks {
+		t.Error("failed archive was journaled")
+	}
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// ErrAttachmentNotFound is returned when a task has no attachment with the ID
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// attachmentDir returns the directory holding a task's attachment files
+func attachmentDir(baseDir, listID, taskID string) string {
+	return filepath.Join(baseDir, "lists", listID, "tasks", taskID+"-files")
+}
+
+// writeAttachmentFile stores attachment content as dir/id, writing to a temp
+// file first so a failed upload never leaves a partial file behind. It
+// returns the number of bytes written.
+func writeAttachmentFile(dir, id string, content io.Reader) (int64, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create attachments directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+id+"-*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create attachment file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, content)
+	if err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("failed to write attachment: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write attachment: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, id)); err != nil {
+		return 0, fmt.Errorf("failed to write attachment: %w", err)
+	}
+	return size, nil
+}
+
+// openAttachmentFile opens the content of an attachment listed on the task
+func openAttachmentFile(baseDir string, task *models.Task, attachmentID string) (*models.Attachment, *os.File, error) {
+	for i := range task.Attachments {
+		if task.Attachments[i].ID != attachmentID {
+			continue
+		}
+
+		file, err := os.Open(filepath.Join(attachmentDir(baseDir, task.ListID, task.ID), attachmentID))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil, ErrAttachmentNotFound
+			}
+			return nil, nil, fmt.Errorf("failed to open attachment: %w", err)
+		}
+		return &task.Attachments[i], file, nil
+	}
+	return nil, nil, ErrAttachmentNotFound
+}
+
+// removeAttachment drops an attachment from the task's metadata, returning
+// ErrAttachmentNotFound if the task has no such attachment
+func removeAttachment(task *models.Task, attachmentID string) error {
+	for i := range task.Attachments {
+		if task.Attachments[i].ID == attachmentID {
+			task.Attachments = append(task.Attachments[:i], task.Attachments[i+1:]...)
+			if len(task.Attachments) == 0 {
+				task.Attachments = nil
+			}
+			return nil
+		}
+	}
+	return ErrAttachmentNotFound
+}
+
+// moveAttachmentDir moves a task's attachment files along with the task
+func moveAttachmentDir(baseDir, taskID, fromListID, toListID string) error {
+	from := attachmentDir(baseDir, fromListID, taskID)
+	if _, err := os.Stat(from); os.IsNotExist(err) {
+		return nil
+	}
+
+	to := attachmentDir(baseDir, toListID, taskID)
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return fmt.Errorf("failed to create tasks directory: %w", err)
+	}
+	if err := os.Rename(from, to); err != nil {
+		return fmt.Errorf("failed to move attachments: %w", err)
+	}
+	return nil
+}
+
+// readTaskFile reads a task from its JSON file. Must be called with the lock
+// held.
+func (fs *FileStore) readTaskFile(listID, taskID string) (string, *models.Task, error) {
+	if err := validateIDs(listID, taskID); err != nil {
+		return "", nil, err
+	}
+	taskPath := filepath.Join(fs.baseDir, "lists", listID, "tasks", taskID+".json")
+	data, err := os.ReadFile(taskPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %s/%s", ErrTaskNotFound, listID, taskID)
+	}
+
+	var task models.Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		return "", nil, corruptData("task", err)
+	}
+	return taskPath, &task, nil
+}
+
+// saveTaskFile writes a task whose metadata changed outside UpdateTask,
+// bumping its version. Must be called with the write lock held.
+func (fs *FileStore) saveTaskFile(taskPath string, task *models.Task) error {
+	task.UpdatedAt = time.Now()
+	task.Version++
+
+	data, err := fs.marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to serialize task: %w", err)
+	}
+	if err := fs.writeFile(taskPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write task file: %w", err)
+	}
+	fs.index.put(task)
+	return nil
+}
+
+// AddAttachment stores the content of a new attachment and records it on the
+// task. The attachment's Size is set from the bytes written.
+func (fs *FileStore) AddAttachment(listID, taskID string, attachment *models.Attachment, content io.Reader) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	taskPath, task, err := fs.readTaskFile(listID, taskID)
+	if err != nil {
+		return err
+	}
+
+	dir := attachmentDir(fs.baseDir, listID, taskID)
+	release := fs.acquireWriteSlot()
+	size, err := writeAttachmentFile(dir, attachment.ID, content)
+	release()
+	if err != nil {
+		return err
+	}
+	attachment.Size = size
+
+	task.Attachments = append(task.Attachments, *attachment)
+	if err := fs.saveTaskFile(taskPath, task); err != nil {
+		os.Remove(filepath.Join(dir, attachment.ID))
+		return err
+	}
+	return nil
+}
+
+// OpenAttachment returns an attachment's metadata and its content, which the
+// caller must close
+func (fs *FileStore) OpenAttachment(listID, taskID, attachmentID string) (*models.Attachment, *os.File, error) {
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+
+	_, task, err := fs.readTaskFile(listID, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return openAttachmentFile(fs.baseDir, task, attachmentID)
+}
+
+// DeleteAttachment removes an attachment from a task along with its content
+func (fs *FileStore) DeleteAttachment(listID, taskID, attachmentID string) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	taskPath, task, err := fs.readTaskFile(listID, taskID)
+	if err != nil {
+		return err
+	}
+	if err := removeAttachment(task, attachmentID); err != nil {
+		return err
+	}
+	if err := fs.saveTaskFile(taskPath, task); err != nil {
+		return err
+	}
+
+	if err := os.Remove(filepath.Join(attachmentDir(fs.baseDir, listID, taskID), attachmentID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	return nil
+}
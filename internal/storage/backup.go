@@ -0,0 +1,499 @@
+package storage
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// ErrInvalidBackup is returned by Restore for archives that don't hold a
+// backup this store can restore
+var ErrInvalidBackup = errors.New("invalid backup")
+
+// invalidBackup wraps ErrInvalidBackup with the reason
+func invalidBackup(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidBackup, fmt.Sprintf(format, args...))
+}
+
+// skipBackupPath reports whether a path inside the data directory is left out
+// of backups: temporary files and directories, whose names start with a dot
+func skipBackupPath(rel string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// zipDir adds the contents of dir to a zip archive, with paths relative to
+// dir. Paths for which skip returns true are left out.
+func zipDir(zw *zip.Writer, dir string, skip func(rel string) bool) error {
+	return filepath.WalkDir(dir, func(p string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		if skip(rel) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			header.Name += "/"
+			_, err := zw.CreateHeader(header)
+			return err
+		}
+		header.Method = zip.Deflate
+
+		out, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(out, file)
+		return err
+	})
+}
+
+// checkArchivePaths rejects archives with entries that aren't plain files or
+// directories or whose names could point outside the directory they are
+// extracted to
+func checkArchivePaths(zr *zip.Reader) error {
+	if len(zr.File) == 0 {
+		return invalidBackup("archive is empty")
+	}
+	for _, file := range zr.File {
+		name := strings.TrimSuffix(file.Name, "/")
+		if strings.Contains(name, `\`) || !filepath.IsLocal(filepath.FromSlash(name)) {
+			return invalidBackup("unsafe path %q", file.Name)
+		}
+		if mode := file.Mode(); !mode.IsRegular() && !mode.IsDir() {
+			return invalidBackup("%q is not a regular file", file.Name)
+		}
+	}
+	return nil
+}
+
+// maxExtractedSize bounds the total size of the files a restore extracts, so
+// a small archive that decompresses to far more (a zip bomb) can't fill the
+// disk. A variable so tests can lower it.
+var maxExtractedSize int64 = 8 << 30
+
+// extractZip writes the files of an archive checked by checkArchivePaths
+// into dir, failing with ErrInvalidBackup once they add up to more than
+// maxExtractedSize
+func extractZip(zr *zip.Reader, dir string) error {
+	remaining := maxExtractedSize
+	for _, file := range zr.File {
+		target := filepath.Join(dir, filepath.FromSlash(strings.TrimSuffix(file.Name, "/")))
+		if file.Mode().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to extract %s: %w", file.Name, err)
+			}
+			continue
+		}
+		written, err := extractZipFile(file, target, remaining)
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", file.Name, err)
+		}
+		remaining -= written
+	}
+	return nil
+}
+
+// extractZipFile writes one archived file to target, failing if it holds
+// more than limit bytes. It returns the number of bytes written.
+func extractZipFile(file *zip.File, target string, limit int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, err
+	}
+	in, err := file.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, err
+	}
+	written, err := io.Copy(out, io.LimitReader(in, limit+1))
+	if err != nil {
+		out.Close()
+		return written, err
+	}
+	if written > limit {
+		out.Close()
+		return written, invalidBackup("archive expands to more than %d bytes", maxExtractedSize)
+	}
+	return written, out.Close()
+}
+
+// readZipJSON decodes an archived JSON file
+func readZipJSON(file *zip.File, v interface{}) error {
+	in, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return json.NewDecoder(in).Decode(v)
+}
+
+// replaceDirContents swaps the entries of dir named by names for those in
+// src, moving the current ones aside first so they can be put back if the
+// swap fails partway. Entries of dir starting with a dot are left alone.
+func replaceDirContents(dir, src string, names []string) error {
+	aside, err := os.MkdirTemp(dir, ".replaced-")
+	if err != nil {
+		return fmt.Errorf("failed to replace data: %w", err)
+	}
+	defer os.RemoveAll(aside)
+
+	var moved []string
+	restore := func() {
+		for _, name := range names {
+			os.RemoveAll(filepath.Join(dir, name))
+		}
+		for _, name := range moved {
+			os.Rename(filepath.Join(aside, name), filepath.Join(dir, name))
+		}
+	}
+
+	for _, name := range names {
+		if _, err := os.Lstat(filepath.Join(dir, name)); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.Rename(filepath.Join(dir, name), filepath.Join(aside, name)); err != nil {
+			restore()
+			return fmt.Errorf("failed to replace data: %w", err)
+		}
+		moved = append(moved, name)
+	}
+	for _, name := range names {
+		if _, err := os.Lstat(filepath.Join(src, name)); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.Rename(filepath.Join(src, name), filepath.Join(dir, name)); err != nil {
+			restore()
+			return fmt.Errorf("failed to replace data: %w", err)
+		}
+	}
+	return nil
+}
+
+// dirEntryNames returns the names of the entries of dir that don't start
+// with a dot
+func dirEntryNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// Backup writes a zip archive of the data directory. Writes are blocked
+// while it runs, so the archive is a consistent snapshot.
+func (fs *FileStore) Backup(w io.Writer) error {
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+	// Backups only hold the read lock, so several can run at once
+	defer fs.acquireWriteSlot()()
+
+	zw := zip.NewWriter(w)
+	if err := zipDir(zw, fs.baseDir, skipBackupPath); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	return zw.Close()
+}
+
+// validateFileBackup checks that an archive holds a file store data
+// directory: a schema version this release can read, and lists and tasks
+// that parse
+func validateFileBackup(zr *zip.Reader) error {
+	if err := checkArchivePaths(zr); err != nil {
+		return err
+	}
+
+	hasLists := false
+	for _, file := range zr.File {
+		name := strings.TrimSuffix(file.Name, "/")
+		parts := strings.Split(name, "/")
+		switch {
+		case name == "tasks.db":
+			return invalidBackup("archive is a SQLite backup; restore it with -storage sqlite")
+		case name == "schema.json":
+			var marker schemaMarker
+			if err := readZipJSON(file, &marker); err != nil {
+				return invalidBackup("unreadable schema.json: %v", err)
+			}
+			if err := checkSchemaVersion(marker.Version); err != nil {
+				return invalidBackup("%v", err)
+			}
+		case parts[0] != "lists":
+		case len(parts) >= 2 && ValidateID(parts[1]) != nil:
+			return invalidBackup("invalid list directory %q", parts[1])
+		case len(parts) == 3 && parts[2] == "list.json":
+			var list models.TaskList
+			if err := readZipJSON(file, &list); err != nil {
+				return invalidBackup("unreadable %s: %v", file.Name, err)
+			}
+		case len(parts) == 4 && parts[2] == "tasks" && path.Ext(parts[3]) == ".json":
+			var task models.Task
+			if err := readZipJSON(file, &task); err != nil {
+				return invalidBackup("unreadable %s: %v", file.Name, err)
+			}
+		}
+		hasLists = hasLists || parts[0] == "lists"
+	}
+	if !hasLists {
+		return invalidBackup("no lists directory")
+	}
+	return nil
+}
+
+// Restore replaces the data directory with the contents of a backup written
+// by Backup, after checking the archive. Backups from older releases are
+// migrated. The current data is only removed once the archive has been
+// extracted, and is put back if replacing it fails.
+func (fs *FileStore) Restore(zr *zip.Reader) error {
+	if err := validateFileBackup(zr); err != nil {
+		return err
+	}
+
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	tmpDir, err := os.MkdirTemp(fs.baseDir, ".restore-")
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	release := fs.acquireWriteSlot()
+	err = extractZip(zr, tmpDir)
+	release()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, "lists"), 0755); err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	current, err := dirEntryNames(fs.baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+	restored, err := dirEntryNames(tmpDir)
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+	names := append(current, restored...)
+	slices.Sort(names)
+	if err := replaceDirContents(fs.baseDir, tmpDir, slices.Compact(names)); err != nil {
+		return err
+	}
+
+	if err := fs.migrate(); err != nil {
+		return err
+	}
+	return fs.buildIndex()
+}
+
+// sqliteTables are the tables copied by Restore, parents before children
+var sqliteTables = []string{"lists", "tasks", "notes", "saved_filters", "undo_entries", "trash"}
+
+// Backup writes a zip archive holding a snapshot of the database, taken with
+// VACUUM INTO, and the attachment files
+func (s *SQLiteStore) Backup(w io.Writer) error {
+	snapshotDir, err := os.MkdirTemp(s.filesDir, ".backup-")
+	if err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	defer os.RemoveAll(snapshotDir)
+
+	snapshot := filepath.Join(snapshotDir, "tasks.db")
+	if _, err := s.db.Exec(`VACUUM INTO ?`, snapshot); err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+
+	zw := zip.NewWriter(w)
+	if err := zipDir(zw, snapshotDir, func(string) bool { return false }); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	if _, err := os.Stat(filepath.Join(s.filesDir, "lists")); err == nil {
+		attachmentsOnly := func(rel string) bool {
+			return skipBackupPath(rel) || strings.Split(filepath.ToSlash(rel), "/")[0] != "lists"
+		}
+		if err := zipDir(zw, s.filesDir, attachmentsOnly); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+	}
+	return zw.Close()
+}
+
+// Restore replaces the database and attachment files with those of a backup
+// written by Backup. The archived database is checked and its rows copied in
+// over the current ones in a single transaction; backups from older releases
+// are then migrated.
+func (s *SQLiteStore) Restore(zr *zip.Reader) error {
+	if err := checkArchivePaths(zr); err != nil {
+		return err
+	}
+	var dbFile *zip.File
+	for _, file := range zr.File {
+		name := strings.TrimSuffix(file.Name, "/")
+		switch {
+		case name == "tasks.db":
+			dbFile = file
+		case name != "lists" && !strings.HasPrefix(name, "lists/"):
+			return invalidBackup("unexpected file %q", file.Name)
+		}
+	}
+	if dbFile == nil {
+		return invalidBackup("no tasks.db")
+	}
+
+	tmpDir, err := os.MkdirTemp(s.filesDir, ".restore-")
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := extractZip(zr, tmpDir); err != nil {
+		return err
+	}
+	restoredDB := filepath.Join(tmpDir, "tasks.db")
+	if err := checkSQLiteBackup(restoredDB); err != nil {
+		return err
+	}
+
+	if err := s.copyFromDatabase(restoredDB); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, "lists"), 0755); err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+	if err := replaceDirContents(s.filesDir, tmpDir, []string{"lists"}); err != nil {
+		return err
+	}
+	return s.migrate()
+}
+
+// checkSQLiteBackup opens an archived database and checks that it is intact,
+// has the store's tables and a schema version this release can read
+func checkSQLiteBackup(path string) error {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return invalidBackup("unreadable tasks.db: %v", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow(`PRAGMA integrity_check`).Scan(&result); err != nil {
+		return invalidBackup("unreadable tasks.db: %v", err)
+	}
+	if result != "ok" {
+		return invalidBackup("tasks.db failed its integrity check: %s", result)
+	}
+
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return invalidBackup("unreadable tasks.db: %v", err)
+	}
+	if err := checkSchemaVersion(version); err != nil {
+		return invalidBackup("%v", err)
+	}
+
+	for _, table := range sqliteTables {
+		var n int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&n); err != nil || n == 0 {
+			return invalidBackup("tasks.db has no %s table", table)
+		}
+	}
+	return nil
+}
+
+// copyFromDatabase replaces the rows of every table, and the schema
+// version, with those of the database at path
+func (s *SQLiteStore) copyFromDatabase(path string) error {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+	defer conn.Close()
+
+	// ATTACH and DETACH can't run inside a transaction
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS restored`, path); err != nil {
+		return fmt.Errorf("failed to open backup database: %w", err)
+	}
+	defer conn.ExecContext(ctx, `DETACH DATABASE restored`)
+
+	var version int
+	if err := conn.QueryRowContext(ctx, `PRAGMA restored.user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("failed to read backup schema version: %w", err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	for i := len(sqliteTables) - 1; i >= 0; i-- {
+		if _, err := tx.Exec(`DELETE FROM main.` + sqliteTables[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to clear %s: %w", sqliteTables[i], err)
+		}
+	}
+	for _, table := range sqliteTables {
+		if _, err := tx.Exec(`INSERT INTO main.` + table + ` SELECT * FROM restored.` + table); err != nil {
+			tx.Rollback()
+			return invalidBackup("failed to copy %s: %v", table, err)
+		}
+	}
+	// PRAGMA doesn't take bound parameters
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA main.user_version = %d`, version)); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
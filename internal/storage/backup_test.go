@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// zipArchive builds an in-memory zip archive of the named files
+func zipArchive(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip Create(%s): %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("zip Write(%s): %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip Close: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader: %v", err)
+	}
+	return zr
+}
+
+func TestRestoreRejectsArchivesExpandingPastLimit(t *testing.T) {
+	defer func(limit int64) { maxExtractedSize = limit }(maxExtractedSize)
+	maxExtractedSize = 1 << 10
+
+	forEachStore(t, func(t *testing.T, store TaskStore) {
+		createTestList(t, store, "kept")
+
+		var files map[string]string
+		if _, ok := store.(*FileStore); ok {
+			files = map[string]string{
+				"lists/big/list.json": `{"id":"big","name":"Big"}`,
+				"lists/big/padding":   strings.Repeat("x", 4<<10),
+			}
+		} else {
+			var buf bytes.Buffer
+			if err := store.Backup(&buf); err != nil {
+				t.Fatalf("Backup: %v", err)
+			}
+			backup, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatalf("zip.NewReader: %v", err)
+			}
+			// The database file alone is larger than the lowered limit
+			files = map[string]string{}
+			for _, file := range backup.File {
+				in, err := file.Open()
+				if err != nil {
+					t.Fatalf("Open(%s): %v", file.Name, err)
+				}
+				var content bytes.Buffer
+				content.ReadFrom(in)
+				in.Close()
+				files[file.Name] = content.String()
+			}
+		}
+
+		err := store.Restore(zipArchive(t, files))
+		if !errors.Is(err, ErrInvalidBackup) || !strings.Contains(err.Error(), "expands") {
+			t.Fatalf("Restore of an archive over the limit: got %v, want ErrInvalidBackup for its size", err)
+		}
+		if _, err := store.GetList("kept"); err != nil {
+			t.Errorf("existing data lost after rejected restore: %v", err)
+		}
+	})
+}
@@ -1,11 +1,14 @@
 package storage
 
 import (
+	"archive/zip"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"path/filepath"
-	"sync"
 	"time"
 
 	"github.com/jbutlerdev/tasks/internal/models"
@@ -15,23 +18,69 @@ import (
 type TaskStore interface {
 	// List operations
 	GetAllLists() ([]models.TaskList, error)
+	GetAllListsWithErrors() ([]models.TaskList, error)
 	GetList(id string) (*models.TaskList, error)
 	CreateList(list *models.TaskList) error
+	CreateListWithTasks(list *models.TaskList, tasks []models.Task) error
+	CreateLists(lists []NewList) error
 	UpdateList(list *models.TaskList) error
+	ArchiveList(id string) (*models.TaskList, error)
+	ReorderLists(ids []string) error
+	RenameListID(id, newID string) (*models.TaskList, error)
 	DeleteList(id string) error
 	
 	// Task operations
 	GetAllTasks() ([]models.Task, error)
+	GetTasksPage(offset, limit int) ([]models.Task, int, error)
 	GetTasksByList(listID string) ([]models.Task, error)
+	GetTasksForList(listID string) ([]models.Task, error)
 	GetTask(listID, taskID string) (*models.Task, error)
+	FindTask(taskID string) (*models.Task, error)
 	CreateTask(task *models.Task) error
 	UpdateTask(task *models.Task) error
+	MoveTask(originalListID, taskID, newListID string, opts MoveOptions) (*models.Task, error)
+	PositionTask(listID, taskID string, index int) (*models.Task, error)
+	MarkReminderSent(listID, taskID string, due, sent time.Time) error
 	DeleteTask(listID, taskID string) error
+	ArchiveDoneTasks(listID string) ([]models.Task, error)
+
+	// Saved filter operations
+	GetSavedFilters() ([]models.SavedFilter, error)
+	GetSavedFilter(name string) (*models.SavedFilter, error)
+	SaveFilter(filter *models.SavedFilter) error
+	DeleteSavedFilter(name string) error
+
+	// Undo operations
+	UndoHistory() ([]UndoEntry, error)
+	Undo() (*UndoEntry, error)
+
+	// Trash operations
+	GetTrash() ([]models.Task, error)
+	RestoreTask(taskID string) (*models.Task, error)
+
+	// Attachment operations
+	AddAttachment(listID, taskID string, attachment *models.Attachment, content io.Reader) error
+	OpenAttachment(listID, taskID, attachmentID string) (*models.Attachment, *os.File, error)
+	DeleteAttachment(listID, taskID, attachmentID string) error
+
+	// Backup operations
+	Backup(w io.Writer) error
+	Restore(archive *zip.Reader) error
+
+	// Health
+	Ready() error
 }
 
 type FileStore struct {
-	baseDir string
-	mutex   *sync.RWMutex
+	baseDir    string
+	mutex      *storeLock
+	writeSem   chan struct{}    // Bounds concurrent file writes; nil means unbounded
+	compact    bool             // Write compact rather than indented JSON
+	hardDelete bool             // Remove deleted tasks instead of moving them to the trash
+	strict     bool             // Fail reads on unreadable files instead of skipping them
+	index      taskIndex        // Every task, kept in memory for reads; see index.go
+	unreadable map[string]error // First unreadable task file of each list, as of the last index build
+	limits     TaskLimits       // See SetTaskLimits
 }
 
 // NewFileStore creates a new file-based storage system
@@ -47,10 +96,121 @@ func NewFileStore(baseDir string) (*FileStore, error) {
 		return nil, fmt.Errorf("failed to create lists directory: %w", err)
 	}
 
-	return &FileStore{
+	fs := &FileStore{
 		baseDir: baseDir,
-		mutex:   &sync.RWMutex{},
-	}, nil
+		mutex:   &storeLock{},
+	}
+	if err := fs.migrate(); err != nil {
+		return nil, err
+	}
+	if err := fs.buildIndex(); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// SetWriteConcurrency bounds the number of file writes that may be in flight
+// at once. A limit of zero or less removes the bound. Reads are never limited.
+func (fs *FileStore) SetWriteConcurrency(limit int) {
+	if limit <= 0 {
+		fs.writeSem = nil
+		return
+	}
+	fs.writeSem = make(chan struct{}, limit)
+}
+
+// acquireWriteSlot waits for a slot in the write semaphore and returns the
+// function that releases it. Most writes already run under the write lock,
+// but backups, restores and attachment uploads keep files open for long
+// stretches, so the semaphore bounds the files open across all of them.
+func (fs *FileStore) acquireWriteSlot() (release func()) {
+	if fs.writeSem == nil {
+		return func() {}
+	}
+	fs.writeSem <- struct{}{}
+	return func() { <-fs.writeSem }
+}
+
+// SetCompactJSON selects compact JSON for files written from now on instead
+// of the default indented form. Files in either form are always readable.
+func (fs *FileStore) SetCompactJSON(compact bool) {
+	fs.compact = compact
+}
+
+// SetStrict makes reads of many lists or tasks fail when a list or task file
+// can't be read or parsed, instead of logging a warning and skipping it
+func (fs *FileStore) SetStrict(strict bool) {
+	fs.strict = strict
+}
+
+// Ready reports whether the lists directory exists and is writable
+func (fs *FileStore) Ready() error {
+	listsDir := filepath.Join(fs.baseDir, "lists")
+	info, err := os.Stat(listsDir)
+	if err != nil {
+		return fmt.Errorf("lists directory unavailable: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("lists path is not a directory: %s", listsDir)
+	}
+
+	probe, err := os.CreateTemp(listsDir, ".ready-*")
+	if err != nil {
+		return fmt.Errorf("lists directory not writable: %w", err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// marshal serializes v in the configured JSON format
+func (fs *FileStore) marshal(v interface{}) ([]byte, error) {
+	if fs.compact {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// createTemp creates the temporary files writeFile writes through; tests
+// replace it to make writes fail partway
+var createTemp = os.CreateTemp
+
+// writeFile writes a file while holding a slot in the write semaphore. The
+// data is written to a temporary file in the same directory and renamed into
+// place, so a crash mid-write leaves the previous contents intact rather than
+// a truncated file. Temporary files end in .tmp and are ignored by readers.
+func (fs *FileStore) writeFile(path string, data []byte, perm os.FileMode) error {
+	defer fs.acquireWriteSlot()()
+
+	tmp, err := createTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
 }
 
 // Task List Methods
@@ -60,6 +220,22 @@ func (fs *FileStore) GetAllLists() ([]models.TaskList, error) {
 	fs.mutex.RLock()
 	defer fs.mutex.RUnlock()
 
+	return fs.readAllLists(false)
+}
+
+// GetAllListsWithErrors returns all task lists, including a placeholder entry
+// for each list directory whose list.json cannot be read or parsed
+func (fs *FileStore) GetAllListsWithErrors() ([]models.TaskList, error) {
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+
+	return fs.readAllLists(true)
+}
+
+// readAllLists reads every list directory. Unreadable lists fail the read in
+// strict mode; otherwise they are logged and either skipped or, when
+// includeErrors is set, returned as placeholders.
+func (fs *FileStore) readAllLists(includeErrors bool) ([]models.TaskList, error) {
 	listsDir := filepath.Join(fs.baseDir, "lists")
 	files, err := os.ReadDir(listsDir)
 	if err != nil {
@@ -74,21 +250,32 @@ func (fs *FileStore) GetAllLists() ([]models.TaskList, error) {
 			
 			// Read list file
 			data, err := os.ReadFile(listPath)
-			if err != nil {
-				// Skip if list file cannot be read
-				continue
+			if err == nil {
+				var list models.TaskList
+				if err = json.Unmarshal(data, &list); err == nil {
+					lists = append(lists, list)
+					continue
+				}
+				err = corruptData("list "+listPath, err)
 			}
 
-			var list models.TaskList
-			if err := json.Unmarshal(data, &list); err != nil {
-				// Skip if list file cannot be parsed
-				continue
+			if fs.strict {
+				return nil, err
+			}
+			log.Printf("Warning: skipping unreadable list %s: %v", listPath, err)
+			if includeErrors {
+				lists = append(lists, models.TaskList{
+					ID:    file.Name(),
+					Name:  "(unreadable)",
+					Error: err.Error(),
+				})
 			}
-
-			lists = append(lists, list)
 		}
 	}
 
+	// Directory order varies across filesystems, so impose a stable order
+	models.SortLists(lists)
+
 	return lists, nil
 }
 
@@ -97,18 +284,26 @@ func (fs *FileStore) GetList(id string) (*models.TaskList, error) {
 	fs.mutex.RLock()
 	defer fs.mutex.RUnlock()
 
+	return fs.readList(id)
+}
+
+// readList reads a single task list. Callers must hold the lock.
+func (fs *FileStore) readList(id string) (*models.TaskList, error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
 	listPath := filepath.Join(fs.baseDir, "lists", id, "list.json")
 	data, err := os.ReadFile(listPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("list not found: %s", id)
+			return nil, fmt.Errorf("%w: %s", ErrListNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to read list: %w", err)
 	}
 
 	var list models.TaskList
 	if err := json.Unmarshal(data, &list); err != nil {
-		return nil, fmt.Errorf("failed to parse list: %w", err)
+		return nil, corruptData("list", err)
 	}
 
 	return &list, nil
@@ -116,28 +311,35 @@ func (fs *FileStore) GetList(id string) (*models.TaskList, error) {
 
 // CreateList creates a new task list
 func (fs *FileStore) CreateList(list *models.TaskList) error {
+	if err := ValidateID(list.ID); err != nil {
+		return err
+	}
 	fs.mutex.Lock()
 	defer fs.mutex.Unlock()
 
+	listDir := filepath.Join(fs.baseDir, "lists", list.ID)
+	if _, err := os.Stat(listDir); err == nil {
+		return fmt.Errorf("%w: %s", ErrListExists, list.ID)
+	}
+
 	// Set timestamps
 	now := time.Now()
 	list.CreatedAt = now
 	list.UpdatedAt = now
 
 	// Create list directory
-	listDir := filepath.Join(fs.baseDir, "lists", list.ID)
 	if err := os.MkdirAll(listDir, 0755); err != nil {
 		return fmt.Errorf("failed to create list directory: %w", err)
 	}
 
 	// Write list file
 	listPath := filepath.Join(listDir, "list.json")
-	data, err := json.MarshalIndent(list, "", "  ")
+	data, err := fs.marshal(list)
 	if err != nil {
 		return fmt.Errorf("failed to serialize list: %w", err)
 	}
 
-	if err := os.WriteFile(listPath, data, 0644); err != nil {
+	if err := fs.writeFile(listPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write list file: %w", err)
 	}
 
@@ -146,19 +348,23 @@ func (fs *FileStore) CreateList(list *models.TaskList) error {
 	if err := os.MkdirAll(tasksDir, 0755); err != nil {
 		return fmt.Errorf("failed to create tasks directory: %w", err)
 	}
+	fs.index.setList(list.ID, nil)
 
 	return nil
 }
 
 // UpdateList updates an existing task list
 func (fs *FileStore) UpdateList(list *models.TaskList) error {
+	if err := ValidateID(list.ID); err != nil {
+		return err
+	}
 	fs.mutex.Lock()
 	defer fs.mutex.Unlock()
 
 	// Check if list exists
 	listDir := filepath.Join(fs.baseDir, "lists", list.ID)
 	if _, err := os.Stat(listDir); os.IsNotExist(err) {
-		return fmt.Errorf("list not found: %s", list.ID)
+		return fmt.Errorf("%w: %s", ErrListNotFound, list.ID)
 	}
 
 	// Update timestamp
@@ -166,31 +372,96 @@ func (fs *FileStore) UpdateList(list *models.TaskList) error {
 
 	// Write list file
 	listPath := filepath.Join(listDir, "list.json")
-	data, err := json.MarshalIndent(list, "", "  ")
+	data, err := fs.marshal(list)
 	if err != nil {
 		return fmt.Errorf("failed to serialize list: %w", err)
 	}
 
-	if err := os.WriteFile(listPath, data, 0644); err != nil {
+	if err := fs.writeFile(listPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write list file: %w", err)
 	}
 
 	return nil
 }
 
+// ArchiveList marks a task list as archived. Archiving an already archived
+// list is a no-op.
+func (fs *FileStore) ArchiveList(id string) (*models.TaskList, error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	listPath := filepath.Join(fs.baseDir, "lists", id, "list.json")
+	data, err := os.ReadFile(listPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrListNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to read list: %w", err)
+	}
+
+	var list models.TaskList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, corruptData("list", err)
+	}
+
+	if list.Archived {
+		return &list, nil
+	}
+
+	now := time.Now()
+	list.Archived = true
+	list.ArchivedAt = &now
+	list.UpdatedAt = now
+
+	data, err = fs.marshal(list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize list: %w", err)
+	}
+
+	if err := fs.writeFile(listPath, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write list file: %w", err)
+	}
+
+	return &list, nil
+}
+
 // DeleteList deletes a task list and all its tasks
 func (fs *FileStore) DeleteList(id string) error {
+	if err := ValidateID(id); err != nil {
+		return err
+	}
 	fs.mutex.Lock()
 	defer fs.mutex.Unlock()
 
 	listDir := filepath.Join(fs.baseDir, "lists", id)
 	if _, err := os.Stat(listDir); os.IsNotExist(err) {
-		return fmt.Errorf("list not found: %s", id)
+		return fmt.Errorf("%w: %s", ErrListNotFound, id)
+	}
+
+	// Snapshot the list and its tasks so the deletion can be undone
+	entry := UndoEntry{Kind: UndoDeleteList}
+	if data, err := os.ReadFile(filepath.Join(listDir, "list.json")); err == nil {
+		var list models.TaskList
+		if err := json.Unmarshal(data, &list); err == nil {
+			entry.List = &list
+		}
+	}
+	if tasks, err := fs.readTasksForList(id); err == nil {
+		entry.Tasks = tasks
 	}
 
 	if err := os.RemoveAll(listDir); err != nil {
 		return fmt.Errorf("failed to delete list: %w", err)
 	}
+	delete(fs.index, id)
+	delete(fs.unreadable, id)
+
+	if entry.List != nil {
+		fs.recordUndo(entry)
+	}
 
 	return nil
 }
@@ -205,15 +476,18 @@ func (fs *FileStore) GetAllTasks() ([]models.Task, error) {
 	var allTasks []models.Task
 
 	// Get all lists
-	lists, err := fs.GetAllLists()
+	lists, err := fs.readAllLists(false)
 	if err != nil {
 		return nil, err
 	}
 
 	// For each list, get all tasks
 	for _, list := range lists {
-		tasks, err := fs.GetTasksForList(list.ID)
+		tasks, err := fs.readTasksForList(list.ID)
 		if err != nil {
+			if fs.strict {
+				return nil, err
+			}
 			// Skip if tasks cannot be read
 			continue
 		}
@@ -223,58 +497,84 @@ func (fs *FileStore) GetAllTasks() ([]models.Task, error) {
 	return allTasks, nil
 }
 
-// GetTasksByList returns all tasks for a specific list
-func (fs *FileStore) GetTasksByList(listID string) ([]models.Task, error) {
+// GetTasksPage returns one page of the tasks GetAllTasks would return, in the
+// same order, along with the total number of tasks
+func (fs *FileStore) GetTasksPage(offset, limit int) ([]models.Task, int, error) {
 	fs.mutex.RLock()
 	defer fs.mutex.RUnlock()
 
-	return fs.GetTasksForList(listID)
-}
-
-// GetTasksForList gets tasks for a list
-func (fs *FileStore) GetTasksForList(listID string) ([]models.Task, error) {
-	tasksDir := filepath.Join(fs.baseDir, "lists", listID, "tasks")
-	
-	// Check if tasks directory exists
-	if _, err := os.Stat(tasksDir); os.IsNotExist(err) {
-		return nil, fmt.Errorf("list not found: %s", listID)
-	}
-
-	files, err := os.ReadDir(tasksDir)
+	lists, err := fs.readAllLists(false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read tasks directory: %w", err)
+		return nil, 0, err
 	}
 
 	var tasks []models.Task
-	for _, file := range files {
-		if !file.IsDir() && filepath.Ext(file.Name()) == ".json" {
-			taskPath := filepath.Join(tasksDir, file.Name())
-			
-			// Read task file
-			data, err := os.ReadFile(taskPath)
-			if err != nil {
-				// Skip if task file cannot be read
-				continue
+	for _, list := range lists {
+		listTasks, err := fs.readTasksForList(list.ID)
+		if err != nil {
+			if fs.strict {
+				return nil, 0, err
 			}
+			continue
+		}
+		tasks = append(tasks, listTasks...)
+	}
 
-			var task models.Task
-			if err := json.Unmarshal(data, &task); err != nil {
-				// Skip if task file cannot be parsed
-				continue
-			}
+	total := len(tasks)
+	if offset >= total {
+		return []models.Task{}, total, nil
+	}
+	return tasks[offset:min(offset+limit, total)], total, nil
+}
 
-			tasks = append(tasks, task)
-		}
+// GetTasksByList returns all tasks for a specific list
+func (fs *FileStore) GetTasksByList(listID string) ([]models.Task, error) {
+	return fs.GetTasksForList(listID)
+}
+
+// GetTasksForList gets tasks for a list. Only a missing list is
+// ErrListNotFound; a list with no tasks directory yet has no tasks.
+func (fs *FileStore) GetTasksForList(listID string) ([]models.Task, error) {
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+
+	return fs.readTasksForList(listID)
+}
+
+// readTasksForList returns the tasks of a list from the index, falling back
+// to the task files for lists the index doesn't know about yet. In strict
+// mode a list with an unreadable task file is an error; such lists are read
+// from disk again each time, so fixing or deleting the file clears the error
+// without waiting for the index to be rebuilt. Callers must hold the lock;
+// public methods take it exactly once and use the unexported read helpers,
+// since re-acquiring a read lock can deadlock behind a queued writer.
+func (fs *FileStore) readTasksForList(listID string) ([]models.Task, error) {
+	if err := ValidateID(listID); err != nil {
+		return nil, err
+	}
+	if tasks, ok := fs.index.listTasks(listID); ok && (!fs.strict || fs.unreadable[listID] == nil) {
+		return tasks, nil
 	}
 
-	return tasks, nil
+	tasks, unreadable, err := fs.readTasksFromDisk(listID)
+	if err == nil && unreadable != nil && fs.strict {
+		return nil, unreadable
+	}
+	return tasks, err
 }
 
 // GetTask returns a single task by ID
 func (fs *FileStore) GetTask(listID, taskID string) (*models.Task, error) {
+	if err := validateIDs(listID, taskID); err != nil {
+		return nil, err
+	}
 	fs.mutex.RLock()
 	defer fs.mutex.RUnlock()
 
+	if task, ok := fs.index.task(listID, taskID); ok {
+		return task, nil
+	}
+
 	// Check if the specific list's task exists first
 	taskPath := filepath.Join(fs.baseDir, "lists", listID, "tasks", taskID+".json")
 	_, err := os.Stat(taskPath)
@@ -287,7 +587,7 @@ func (fs *FileStore) GetTask(listID, taskID string) (*models.Task, error) {
 
 		var task models.Task
 		if err := json.Unmarshal(data, &task); err != nil {
-			return nil, fmt.Errorf("failed to parse task: %w", err)
+			return nil, corruptData("task", err)
 		}
 
 		return &task, nil
@@ -296,7 +596,7 @@ func (fs *FileStore) GetTask(listID, taskID string) (*models.Task, error) {
 	// If we couldn't find it in the specific list, search all lists
 	if listID != "" {
 		// Get all lists
-		lists, err := fs.GetAllLists()
+		lists, err := fs.readAllLists(false)
 		if err != nil {
 			return nil, err
 		}
@@ -314,7 +614,7 @@ func (fs *FileStore) GetTask(listID, taskID string) (*models.Task, error) {
 
 				var task models.Task
 				if err := json.Unmarshal(data, &task); err != nil {
-					return nil, fmt.Errorf("failed to parse task: %w", err)
+					return nil, corruptData("task", err)
 				}
 
 				return &task, nil
@@ -322,18 +622,27 @@ func (fs *FileStore) GetTask(listID, taskID string) (*models.Task, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("task not found: %s", taskID)
+	return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
 }
 
 // CreateTask creates a new task
 func (fs *FileStore) CreateTask(task *models.Task) error {
+	if err := validateIDs(task.ListID, task.ID); err != nil {
+		return err
+	}
 	fs.mutex.Lock()
 	defer fs.mutex.Unlock()
 
 	// Check if list exists
 	listDir := filepath.Join(fs.baseDir, "lists", task.ListID)
 	if _, err := os.Stat(listDir); os.IsNotExist(err) {
-		return fmt.Errorf("list not found: %s", task.ListID)
+		return fmt.Errorf("%w: %s", ErrListNotFound, task.ListID)
+	}
+	if err := fs.checkTaskLimits(task.ListID, 1); err != nil {
+		return err
+	}
+	if _, err := fs.findTask(task.ID); err == nil {
+		return fmt.Errorf("%w: %s", ErrTaskExists, task.ID)
 	}
 
 	// Create tasks directory if it doesn't exist
@@ -346,33 +655,41 @@ func (fs *FileStore) CreateTask(task *models.Task) error {
 	now := time.Now()
 	task.CreatedAt = now
 	task.UpdatedAt = now
+	task.Version = 1
 	if task.StateTime.IsZero() {
 		task.StateTime = now
 	}
+	task.RecordCompletion(nil, now)
 
 	// Write task file
 	taskPath := filepath.Join(tasksDir, task.ID+".json")
-	data, err := json.MarshalIndent(task, "", "  ")
+	data, err := fs.marshal(task)
 	if err != nil {
 		return fmt.Errorf("failed to serialize task: %w", err)
 	}
 
-	if err := os.WriteFile(taskPath, data, 0644); err != nil {
+	if err := fs.writeFile(taskPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write task file: %w", err)
 	}
+	fs.index.put(task)
+	fs.recheckUnreadable(task.ListID)
+	fs.recordUndo(UndoEntry{Kind: UndoCreateTask, Tasks: []models.Task{task.Clone()}, Version: task.Version})
 
 	return nil
 }
 
 // UpdateTask updates an existing task
 func (fs *FileStore) UpdateTask(task *models.Task) error {
+	if err := validateIDs(task.ListID, task.ID); err != nil {
+		return err
+	}
 	fs.mutex.Lock()
 	defer fs.mutex.Unlock()
 
 	// Ensure list directory exists
 	listDir := filepath.Join(fs.baseDir, "lists", task.ListID)
 	if _, err := os.Stat(listDir); os.IsNotExist(err) {
-		return fmt.Errorf("list directory not found: %s", task.ListID)
+		return fmt.Errorf("%w: %s", ErrListNotFound, task.ListID)
 	}
 
 	// Ensure tasks directory exists
@@ -385,25 +702,119 @@ func (fs *FileStore) UpdateTask(task *models.Task) error {
 	taskPath := filepath.Join(tasksDir, task.ID+".json")
 
 	// Update timestamp
-	task.UpdatedAt = time.Now()
+	now := time.Now()
+	task.UpdatedAt = now
+
+	// Reject stale writes and record what changed since the stored copy
+	var previous *models.Task
+	if data, err := os.ReadFile(taskPath); err == nil {
+		var stored models.Task
+		if err := json.Unmarshal(data, &stored); err == nil {
+			if task.Version != 0 && task.Version != stored.Version {
+				return fmt.Errorf("%w: version %d is stale, current is %d", ErrVersionConflict, task.Version, stored.Version)
+			}
+			previous = &stored
+			task.RecordChanges(previous, now)
+			task.RecordStateTime(previous, now)
+			task.RecordCompletion(previous, now)
+			task.RecordReminder(previous)
+			task.Attachments = previous.Attachments // Managed by AddAttachment and DeleteAttachment
+			task.Version = previous.Version
+		}
+	}
+	task.Version++
 
 	// Write task file
-	data, err := json.MarshalIndent(task, "", "  ")
+	data, err := fs.marshal(task)
 	if err != nil {
 		return fmt.Errorf("failed to serialize task: %w", err)
 	}
 
-	if err := os.WriteFile(taskPath, data, 0644); err != nil {
+	if err := fs.writeFile(taskPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write task file: %w", err)
 	}
+	fs.index.put(task)
+	fs.recheckUnreadable(task.ListID)
+	fs.recordUndo(updateUndoEntry(previous, task))
+
+	return nil
+}
+
+// ErrListNotFound and ErrTaskNotFound are returned, wrapped with the ID, for
+// lists and tasks that don't exist
+var (
+	ErrListNotFound = errors.New("list not found")
+	ErrTaskNotFound = errors.New("task not found")
+)
+
+// ErrCorruptData is returned, wrapped with the parse error, when a stored
+// list or task exists but can't be read
+var ErrCorruptData = errors.New("corrupt data")
+
+// corruptData reports a stored item that failed to parse
+func corruptData(what string, err error) error {
+	return fmt.Errorf("%w: failed to parse %s: %w", ErrCorruptData, what, err)
+}
+
+// ErrStateNotAllowed is returned by MoveTask when the destination list's
+// workflow does not include the task's state and ResetState is not set
+var ErrStateNotAllowed = errors.New("state not allowed in destination list")
+
+// ErrVersionConflict is returned by UpdateTask when the task carries a
+// version other than the stored one, meaning someone else saved it first
+var ErrVersionConflict = errors.New("task was modified concurrently")
+
+// ErrTaskExists is returned by CreateTask when a task with the same ID
+// already exists, in any list
+var ErrTaskExists = errors.New("task already exists")
+
+// MoveOptions controls how MoveTask places a task in its destination list
+type MoveOptions struct {
+	Position   *int // Position in the destination list; nil keeps the current position
+	ResetState bool // Reset a state the destination does not allow to its first state instead of failing
+}
+
+// applyMove updates a task for its destination list, checking its state
+// against the destination's workflow
+func applyMove(task *models.Task, dest *models.TaskList, opts MoveOptions) error {
+	now := time.Now()
+	previous := *task
+
+	if !dest.AllowsState(task.State) {
+		if !opts.ResetState {
+			return fmt.Errorf("%w: %s", ErrStateNotAllowed, task.State)
+		}
+		task.State = dest.TaskStates()[0]
+		task.StateTime = now
+		if task.State != models.TaskStateBlocked {
+			task.BlockedReason = ""
+		}
+	}
+
+	if opts.Position != nil {
+		task.Position = *opts.Position
+	}
 
+	task.ListID = dest.ID
+	task.UpdatedAt = now
+	task.Version++
+	task.RecordChanges(&previous, now)
+	task.RecordStateTime(&previous, now)
+	task.RecordCompletion(&previous, now)
 	return nil
 }
 
 // MoveTask moves a task from one list to another
-func (fs *FileStore) MoveTask(originalListID, taskID, newListID string) (*models.Task, error) {
+func (fs *FileStore) MoveTask(originalListID, taskID, newListID string, opts MoveOptions) (*models.Task, error) {
+	if err := validateIDs(originalListID, taskID, newListID); err != nil {
+		return nil, err
+	}
 	fs.mutex.Lock()
 	defer fs.mutex.Unlock()
+
+	if originalListID == newListID {
+		return nil, fmt.Errorf("task is already in list: %s", newListID)
+	}
 	
 	// Ensure the original list's tasks directory exists
 	originalTasksDir := filepath.Join(fs.baseDir, "lists", originalListID, "tasks")
@@ -416,25 +827,30 @@ func (fs *FileStore) MoveTask(originalListID, taskID, newListID string) (*models
 	data, err := os.ReadFile(originalTaskPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("task not found: %s/%s", originalListID, taskID)
+			return nil, fmt.Errorf("%w: %s/%s", ErrTaskNotFound, originalListID, taskID)
 		}
 		return nil, fmt.Errorf("failed to read task: %w", err)
 	}
 	
 	var task models.Task
 	if err := json.Unmarshal(data, &task); err != nil {
-		return nil, fmt.Errorf("failed to parse task: %w", err)
+		return nil, corruptData("task", err)
 	}
-	
-	// Update the list ID
-	task.ListID = newListID
-	task.UpdatedAt = time.Now()
-	
+
 	// Check if the destination list exists
-	newListDir := filepath.Join(fs.baseDir, "lists", newListID)
-	if _, err := os.Stat(newListDir); os.IsNotExist(err) {
-		return nil, fmt.Errorf("destination list not found: %s", newListID)
+	newList, err := fs.readList(newListID)
+	if err != nil {
+		return nil, fmt.Errorf("destination %w: %s", ErrListNotFound, newListID)
+	}
+	if err := fs.checkMoveLimits(newListID); err != nil {
+		return nil, err
+	}
+
+	before := task.Clone()
+	if err := applyMove(&task, newList, opts); err != nil {
+		return nil, err
 	}
+	newListDir := filepath.Join(fs.baseDir, "lists", newListID)
 	
 	// Ensure the new list's tasks directory exists
 	newTasksDir := filepath.Join(newListDir, "tasks")
@@ -442,28 +858,44 @@ func (fs *FileStore) MoveTask(originalListID, taskID, newListID string) (*models
 		return nil, fmt.Errorf("failed to create destination tasks directory: %w", err)
 	}
 	
-	// Write the task to the new list
+	// Write the task to the new list, refusing to overwrite a task with the
+	// same ID
 	newTaskPath := filepath.Join(newTasksDir, taskID+".json")
+	if _, err := os.Stat(newTaskPath); err == nil {
+		return nil, fmt.Errorf("task already exists in destination list: %s/%s", newListID, taskID)
+	}
 	
-	data, err = json.MarshalIndent(task, "", "  ")
+	data, err = fs.marshal(task)
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize task: %w", err)
 	}
 	
-	if err := os.WriteFile(newTaskPath, data, 0644); err != nil {
+	if err := fs.writeFile(newTaskPath, data, 0644); err != nil {
 		return nil, fmt.Errorf("failed to write task file: %w", err)
 	}
+	fs.index.put(&task)
 	
 	// Delete the task from the original list
 	if err := os.Remove(originalTaskPath); err != nil {
 		return nil, fmt.Errorf("failed to delete original task: %w", err)
 	}
+	fs.index.remove(originalListID, taskID)
+	fs.recheckUnreadable(originalListID)
+	fs.recheckUnreadable(newListID)
+
+	if err := moveAttachmentDir(fs.baseDir, taskID, originalListID, newListID); err != nil {
+		return nil, err
+	}
+	fs.recordUndo(UndoEntry{Kind: UndoMoveTask, Tasks: []models.Task{before}, Version: task.Version})
 	
 	return &task, nil
 }
 
 // DeleteTask deletes a task
 func (fs *FileStore) DeleteTask(listID, taskID string) error {
+	if err := validateIDs(listID, taskID); err != nil {
+		return err
+	}
 	fs.mutex.Lock()
 	defer fs.mutex.Unlock()
 
@@ -472,14 +904,11 @@ func (fs *FileStore) DeleteTask(listID, taskID string) error {
 	_, err := os.Stat(taskPath)
 	if err == nil {
 		// Found the task, delete it
-		if err := os.Remove(taskPath); err != nil {
-			return fmt.Errorf("failed to delete task: %w", err)
-		}
-		return nil
+		return fs.removeTaskFile(taskPath)
 	}
 
 	// If not found in the specific list, search all lists
-	lists, err := fs.GetAllLists()
+	lists, err := fs.readAllLists(false)
 	if err != nil {
 		return err
 	}
@@ -490,12 +919,85 @@ func (fs *FileStore) DeleteTask(listID, taskID string) error {
 		_, err := os.Stat(taskPath)
 		if err == nil {
 			// Found the task, delete it
-			if err := os.Remove(taskPath); err != nil {
-				return fmt.Errorf("failed to delete task: %w", err)
-			}
-			return nil
+			return fs.removeTaskFile(taskPath)
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+}
+
+// removeTaskFile deletes a task file, moving a copy into the trash unless
+// hard deletes are enabled and recording its contents in the undo journal.
+// Unreadable task files are removed outright. Must be called with the write
+// lock held.
+func (fs *FileStore) removeTaskFile(taskPath string) error {
+	var task models.Task
+	data, err := os.ReadFile(taskPath)
+	parsed := err == nil && json.Unmarshal(data, &task) == nil
+
+	if parsed && !fs.hardDelete {
+		if err := fs.trashTask(task); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Remove(taskPath); err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	listID, taskID := taskPathIDs(taskPath)
+	fs.index.remove(listID, taskID)
+	fs.recheckUnreadable(listID)
+
+	if parsed {
+		fs.recordUndo(UndoEntry{Kind: UndoDeleteTask, Tasks: []models.Task{task}})
+	}
+
+	return nil
+}
+
+// FindTask locates a task by ID without knowing which list it belongs to
+func (fs *FileStore) FindTask(taskID string) (*models.Task, error) {
+	if err := ValidateID(taskID); err != nil {
+		return nil, err
+	}
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+
+	return fs.findTask(taskID)
+}
+
+// findTask locates a task by ID in any list. Callers must hold the lock.
+func (fs *FileStore) findTask(taskID string) (*models.Task, error) {
+	for listID := range fs.index {
+		if task, ok := fs.index.task(listID, taskID); ok {
+			return task, nil
+		}
+	}
+
+	listsDir := filepath.Join(fs.baseDir, "lists")
+	entries, err := os.ReadDir(listsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lists directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		taskPath := filepath.Join(listsDir, entry.Name(), "tasks", taskID+".json")
+		data, err := os.ReadFile(taskPath)
+		if err != nil {
+			continue
+		}
+
+		var task models.Task
+		if err := json.Unmarshal(data, &task); err != nil {
+			return nil, corruptData("task", err)
 		}
+
+		return &task, nil
 	}
 
-	return fmt.Errorf("task not found: %s", taskID)
-}
\ No newline at end of file
+	return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+}
@@ -0,0 +1,504 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// newTestFileStore returns a FileStore over an empty temporary directory
+func newTestFileStore(t testing.TB) *FileStore {
+	t.Helper()
+	fs, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	return fs
+}
+
+// newTestSQLiteStore returns a SQLiteStore over a new temporary database
+func newTestSQLiteStore(t testing.TB) *SQLiteStore {
+	t.Helper()
+	s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// forEachStore runs test as a subtest against each store implementation
+func forEachStore(t *testing.T, test func(t *testing.T, store TaskStore)) {
+	t.Run("file", func(t *testing.T) { test(t, newTestFileStore(t)) })
+	t.Run("sqlite", func(t *testing.T) { test(t, newTestSQLiteStore(t)) })
+}
+
+// createTestList creates an empty list with the given ID
+func createTestList(t testing.TB, store TaskStore, id string) {
+	t.Helper()
+	if err := store.CreateList(&models.TaskList{ID: id, Name: id}); err != nil {
+		t.Fatalf("CreateList(%s): %v", id, err)
+	}
+}
+
+// newTestTask returns an unsaved task in listID with the given ID
+func newTestTask(listID, id string) *models.Task {
+	return &models.Task{ID: id, ListID: listID, Title: "Task " + id, State: models.TaskStateTodo}
+}
+
+// createTestTask creates a task in listID with the given ID
+func createTestTask(t testing.TB, store TaskStore, listID, id string) *models.Task {
+	t.Helper()
+	task := newTestTask(listID, id)
+	if err := store.CreateTask(task); err != nil {
+		t.Fatalf("CreateTask(%s/%s): %v", listID, id, err)
+	}
+	return task
+}
+
+// testTaskID returns the ID of the i-th task a test creates
+func testTaskID(i int) string {
+	return fmt.Sprintf("task-%04d", i)
+}
+
+func TestCreateTaskRejectsExistingID(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store TaskStore) {
+		createTestList(t, store, "a")
+		createTestList(t, store, "b")
+		createTestTask(t, store, "a", "shared")
+
+		for _, listID := range []string{"a", "b"} {
+			err := store.CreateTask(newTestTask(listID, "shared"))
+			if !errors.Is(err, ErrTaskExists) {
+				t.Errorf("CreateTask in %s: got %v, want ErrTaskExists", listID, err)
+			}
+		}
+
+		task, err := store.GetTask("a", "shared")
+		if err != nil {
+			t.Fatalf("GetTask: %v", err)
+		}
+		if task.Version != 1 {
+			t.Errorf("existing task was rewritten: version %d", task.Version)
+		}
+	})
+}
+
+func TestCreateListRejectsExistingID(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store TaskStore) {
+		if err := store.CreateList(&models.TaskList{ID: "a", Name: "Original"}); err != nil {
+			t.Fatalf("CreateList: %v", err)
+		}
+		createTestTask(t, store, "a", "task")
+
+		err := store.CreateList(&models.TaskList{ID: "a", Name: "Replacement"})
+		if !errors.Is(err, ErrListExists) {
+			t.Errorf("CreateList with a taken ID: got %v, want ErrListExists", err)
+		}
+
+		list, err := store.GetList("a")
+		if err != nil {
+			t.Fatalf("GetList: %v", err)
+		}
+		if list.Name != "Original" {
+			t.Errorf("existing list was overwritten: name %q", list.Name)
+		}
+		if tasks, err := store.GetTasksForList("a"); err != nil || len(tasks) != 1 {
+			t.Errorf("tasks after rejected create: %d, %v; want 1", len(tasks), err)
+		}
+	})
+}
+
+func TestFindTaskRejectsInvalidID(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store TaskStore) {
+		createTestList(t, store, "a")
+		for _, id := range []string{"", "../a/list", "*", "a/b"} {
+			if _, err := store.FindTask(id); !errors.Is(err, ErrInvalidID) {
+				t.Errorf("FindTask(%q): got %v, want ErrInvalidID", id, err)
+			}
+		}
+	})
+}
+
+func TestRestoreTaskMatchesIDExactly(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store TaskStore) {
+		createTestList(t, store, "a")
+		createTestTask(t, store, "a", "deleted")
+		if err := store.DeleteTask("a", "deleted"); err != nil {
+			t.Fatalf("DeleteTask: %v", err)
+		}
+
+		for _, id := range []string{"*", "d*", "delete?", "[d]eleted"} {
+			if _, err := store.RestoreTask(id); !errors.Is(err, ErrInvalidID) {
+				t.Errorf("RestoreTask(%q): got %v, want ErrInvalidID", id, err)
+			}
+		}
+		if _, err := store.RestoreTask("delete"); !errors.Is(err, ErrNotInTrash) {
+			t.Errorf("RestoreTask(prefix): got %v, want ErrNotInTrash", err)
+		}
+		if _, err := store.RestoreTask("deleted"); err != nil {
+			t.Errorf("RestoreTask: %v", err)
+		}
+	})
+}
+
+func TestStrictReadsRecoverFromFixedTaskFile(t *testing.T) {
+	fs := newTestFileStore(t)
+	fs.SetStrict(true)
+	createTestList(t, fs, "a")
+	createTestTask(t, fs, "a", "good")
+
+	badPath := filepath.Join(fs.baseDir, "lists", "a", "tasks", "bad.json")
+	corrupt := func() {
+		t.Helper()
+		if err := os.WriteFile(badPath, []byte("{not json"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := fs.RebuildIndex(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fs.GetTasksForList("a"); !errors.Is(err, ErrCorruptData) {
+			t.Fatalf("GetTasksForList with corrupt file: got %v, want ErrCorruptData", err)
+		}
+	}
+
+	// Fixed by hand
+	corrupt()
+	if err := os.WriteFile(badPath, []byte(`{"id":"bad","list_id":"a","title":"Fixed"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := fs.GetTasksForList("a")
+	if err != nil || len(tasks) != 2 {
+		t.Fatalf("GetTasksForList after fix: got %d tasks, %v", len(tasks), err)
+	}
+
+	// Deleted through the store
+	corrupt()
+	if err := fs.DeleteTask("a", "bad"); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	tasks, err = fs.GetTasksForList("a")
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("GetTasksForList after delete: got %d tasks, %v", len(tasks), err)
+	}
+	if len(fs.unreadable) != 0 {
+		t.Errorf("unreadable still records %v", fs.unreadable)
+	}
+}
+
+func TestMarkReminderSentKeepsUndo(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store TaskStore) {
+		createTestList(t, store, "a")
+		task := createTestTask(t, store, "a", "due")
+		due := time.Now().Add(time.Hour).Truncate(time.Second)
+		task.DueDate = &due
+		task.Title = "Edited"
+		if err := store.UpdateTask(task); err != nil {
+			t.Fatalf("UpdateTask: %v", err)
+		}
+
+		if err := store.MarkReminderSent("a", "due", due.Add(time.Minute), time.Now()); !errors.Is(err, ErrVersionConflict) {
+			t.Errorf("MarkReminderSent for another due date: got %v, want ErrVersionConflict", err)
+		}
+		if err := store.MarkReminderSent("a", "due", due, time.Now()); err != nil {
+			t.Fatalf("MarkReminderSent: %v", err)
+		}
+
+		marked, err := store.GetTask("a", "due")
+		if err != nil {
+			t.Fatalf("GetTask: %v", err)
+		}
+		if marked.ReminderSent == nil || marked.Version != task.Version {
+			t.Errorf("got reminder %v at version %d, want a reminder at version %d", marked.ReminderSent, marked.Version, task.Version)
+		}
+
+		entry, err := store.Undo()
+		if err != nil {
+			t.Fatalf("Undo: %v", err)
+		}
+		if entry.Kind != UndoUpdateTask {
+			t.Errorf("undid %s, want the update", entry.Kind)
+		}
+		reverted, err := store.GetTask("a", "due")
+		if err != nil {
+			t.Fatalf("GetTask: %v", err)
+		}
+		if reverted.Title != "Task due" {
+			t.Errorf("title after undo: %q", reverted.Title)
+		}
+	})
+}
+
+func TestGetAllListsOrderIsStable(t *testing.T) {
+	fs := newTestFileStore(t)
+	ids := []string{"zeta", "alpha", "mid", "beta", "omega"}
+	for _, id := range ids {
+		createTestList(t, fs, id)
+	}
+
+	// Two lists created at the same moment, as when copied in by hand,
+	// fall back to name order
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, name := range []string{"Tie B", "Tie A"} {
+		id := "tie-" + name[len(name)-1:]
+		data := fmt.Sprintf(`{"id":%q,"name":%q,"created_at":%q}`, id, name, created.Format(time.RFC3339))
+		dir := filepath.Join(fs.baseDir, "lists", id)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "list.json"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := append([]string{"tie-A", "tie-B"}, ids...)
+
+	for i := 0; i < 10; i++ {
+		lists, err := fs.GetAllLists()
+		if err != nil {
+			t.Fatalf("GetAllLists: %v", err)
+		}
+		got := make([]string, len(lists))
+		for j := range lists {
+			got[j] = lists[j].ID
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("call %d: got %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestListAndTaskOrderIsDeterministic(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store TaskStore) {
+		listIDs := []string{"c", "a", "b"}
+		for _, id := range listIDs {
+			createTestList(t, store, id)
+		}
+		var taskIDs []string
+		for i := 9; i >= 0; i-- {
+			id := testTaskID(i)
+			createTestTask(t, store, "a", id)
+			taskIDs = append(taskIDs, id)
+		}
+
+		for i := 0; i < 10; i++ {
+			lists, err := store.GetAllLists()
+			if err != nil {
+				t.Fatalf("GetAllLists: %v", err)
+			}
+			var gotLists []string
+			for _, list := range lists {
+				gotLists = append(gotLists, list.ID)
+			}
+			if fmt.Sprint(gotLists) != fmt.Sprint(listIDs) {
+				t.Fatalf("call %d: lists %v, want %v", i, gotLists, listIDs)
+			}
+
+			tasks, err := store.GetTasksForList("a")
+			if err != nil {
+				t.Fatalf("GetTasksForList: %v", err)
+			}
+			var gotTasks []string
+			for _, task := range tasks {
+				gotTasks = append(gotTasks, task.ID)
+			}
+			if fmt.Sprint(gotTasks) != fmt.Sprint(taskIDs) {
+				t.Fatalf("call %d: tasks %v, want %v", i, gotTasks, taskIDs)
+			}
+		}
+	})
+}
+
+// BenchmarkWriteTask compares serializing and writing a task file in the
+// pretty and compact JSON formats
+func BenchmarkWriteTask(b *testing.B) {
+	for _, mode := range []struct {
+		name    string
+		compact bool
+	}{{"pretty", false}, {"compact", true}} {
+		b.Run(mode.name, func(b *testing.B) {
+			fs := newTestFileStore(b)
+			fs.SetCompactJSON(mode.compact)
+			createTestList(b, fs, "bench")
+			task := createTestTask(b, fs, "bench", "task")
+			task.Description = strings.Repeat("A task description long enough to matter. ", 20)
+			task.Tags = []string{"one", "two", "three"}
+			task.Notes = []models.Note{{ID: "note", Content: "A note"}}
+			taskPath := filepath.Join(fs.baseDir, "lists", "bench", "tasks", "task.json")
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				data, err := fs.marshal(task)
+				if err != nil {
+					b.Fatalf("marshal: %v", err)
+				}
+				if err := fs.writeFile(taskPath, data, 0644); err != nil {
+					b.Fatalf("writeFile: %v", err)
+				}
+				b.SetBytes(int64(len(data)))
+			}
+		})
+	}
+}
+
+func TestFailedWriteKeepsPreviousFile(t *testing.T) {
+	fs := newTestFileStore(t)
+	createTestList(t, fs, "a")
+	task := createTestTask(t, fs, "a", "task")
+	taskPath := filepath.Join(fs.baseDir, "lists", "a", "tasks", "task.json")
+	before, err := os.ReadFile(taskPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The temporary file is closed before anything is written to it, as if
+	// the disk filled up or the process died partway through the write
+	createTemp = func(dir, pattern string) (*os.File, error) {
+		f, err := os.CreateTemp(dir, pattern)
+		if err == nil {
+			f.Close()
+		}
+		return f, err
+	}
+	defer func() { createTemp = os.CreateTemp }()
+
+	task.Title = "Changed"
+	if err := fs.UpdateTask(task); err == nil {
+		t.Fatal("UpdateTask succeeded despite the failed write")
+	}
+
+	after, err := os.ReadFile(taskPath)
+	if err != nil {
+		t.Fatalf("task file is gone: %v", err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("task file changed by the failed write:\n%s", after)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(fs.baseDir, "lists", "a", "tasks", ".*.tmp"))
+	if len(leftovers) > 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+
+	// A temporary file left by a crash is ignored when the store reopens
+	if err := os.WriteFile(filepath.Join(fs.baseDir, "lists", "a", "tasks", ".task.json-1.tmp"), []byte(`{"id":"tas`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := NewFileStore(fs.baseDir)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	reopened.SetStrict(true)
+	tasks, err := reopened.GetTasksForList("a")
+	if err != nil {
+		t.Fatalf("GetTasksForList: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Title != "Task task" {
+		t.Errorf("got %+v after reopening, want the original task", tasks)
+	}
+}
+
+// TestConcurrentAccessToOneList creates, updates and reads tasks in the same
+// list from many goroutines at once. Run with -race; a nested read lock
+// queued behind a writer would deadlock it.
+func TestConcurrentAccessToOneList(t *testing.T) {
+	fs := newTestFileStore(t)
+	createTestList(t, fs, "shared")
+
+	const workers = 8
+	const rounds = 25
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*rounds*4)
+	for w := 0; w < workers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				task := newTestTask("shared", testTaskID(w*rounds+i))
+				if err := fs.CreateTask(task); err != nil {
+					errs <- err
+					continue
+				}
+				task.Title = "Updated"
+				if err := fs.UpdateTask(task); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				if _, err := fs.GetTasksForList("shared"); err != nil {
+					errs <- err
+				}
+				if _, err := fs.GetAllTasks(); err != nil {
+					errs <- err
+				}
+				if _, err := fs.GetTask("shared", testTaskID(i)); err != nil && !errors.Is(err, ErrTaskNotFound) {
+					errs <- err
+				}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("deadlocked")
+	}
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	tasks, err := fs.GetTasksForList("shared")
+	if err != nil {
+		t.Fatalf("GetTasksForList: %v", err)
+	}
+	if len(tasks) != workers*rounds {
+		t.Fatalf("got %d tasks, want %d", len(tasks), workers*rounds)
+	}
+	for _, task := range tasks {
+		if task.Title != "Updated" || task.Version != 2 {
+			t.Errorf("task %s: title %q, version %d", task.ID, task.Title, task.Version)
+		}
+	}
+}
+
+func TestWriteConcurrencyBoundsSlots(t *testing.T) {
+	fs := newTestFileStore(t)
+	fs.SetWriteConcurrency(2)
+
+	first, second := fs.acquireWriteSlot(), fs.acquireWriteSlot()
+	acquired := make(chan struct{})
+	go func() {
+		fs.acquireWriteSlot()()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("third write slot acquired while two were held")
+	case <-time.After(50 * time.Millisecond):
+	}
+	first()
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("write slot not acquired after one was released")
+	}
+	second()
+
+	fs.SetWriteConcurrency(0)
+	fs.acquireWriteSlot()()
+}
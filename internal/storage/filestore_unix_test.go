@@ -0,0 +1,72 @@
+//go:build unix
+
+package storage
+
+import (
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"testing"
+)
+
+// fdLimitChildEnv marks the subprocess TestConcurrentCreatesWithinFDLimit
+// runs itself in, so the lowered file limit never reaches other tests
+const fdLimitChildEnv = "TASKS_TEST_FD_LIMIT_CHILD"
+
+// TestConcurrentCreatesWithinFDLimit fires hundreds of concurrent creates at
+// a process limited to a few dozen open files. The write semaphore keeps the
+// files open at once under the limit, so none of them should fail with
+// EMFILE. The limit is process-wide, so the test runs in a subprocess.
+func TestConcurrentCreatesWithinFDLimit(t *testing.T) {
+	if os.Getenv(fdLimitChildEnv) == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestConcurrentCreatesWithinFDLimit$", "-test.v")
+		cmd.Env = append(os.Environ(), fdLimitChildEnv+"=1")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("subprocess failed: %v\n%s", err, out)
+		}
+		return
+	}
+
+	fs := newTestFileStore(t)
+	fs.SetWriteConcurrency(16)
+	createTestList(t, fs, "burst")
+
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		t.Skipf("Getrlimit: %v", err)
+	}
+	lowered := limit
+	lowered.Cur = 64
+	if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &lowered); err != nil {
+		t.Skipf("Setrlimit: %v", err)
+	}
+	t.Cleanup(func() { syscall.Setrlimit(syscall.RLIMIT_NOFILE, &limit) })
+
+	const creates = 500
+	errs := make(chan error, creates)
+	var wg sync.WaitGroup
+	for i := 0; i < creates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- fs.CreateTask(newTestTask("burst", testTaskID(i)))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("CreateTask: %v", err)
+		}
+	}
+
+	tasks, err := fs.GetTasksForList("burst")
+	if err != nil {
+		t.Fatalf("GetTasksForList: %v", err)
+	}
+	if len(tasks) != creates {
+		t.Fatalf("got %d tasks, want %d", len(tasks), creates)
+	}
+}
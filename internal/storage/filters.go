@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// Saved Filter Methods
+
+// filtersPath returns the path of the saved filters file
+func (fs *FileStore) filtersPath() string {
+	return filepath.Join(fs.baseDir, "filters.json")
+}
+
+// readFilters loads the saved filters keyed by name
+func (fs *FileStore) readFilters() (map[string]models.SavedFilter, error) {
+	filters := make(map[string]models.SavedFilter)
+
+	data, err := os.ReadFile(fs.filtersPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return filters, nil
+		}
+		return nil, fmt.Errorf("failed to read filters: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &filters); err != nil {
+		return nil, fmt.Errorf("failed to parse filters: %w", err)
+	}
+
+	return filters, nil
+}
+
+// writeFilters persists the saved filters
+func (fs *FileStore) writeFilters(filters map[string]models.SavedFilter) error {
+	data, err := fs.marshal(filters)
+	if err != nil {
+		return fmt.Errorf("failed to serialize filters: %w", err)
+	}
+
+	if err := fs.writeFile(fs.filtersPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write filters file: %w", err)
+	}
+
+	return nil
+}
+
+// GetSavedFilters returns all saved filters sorted by name
+func (fs *FileStore) GetSavedFilters() ([]models.SavedFilter, error) {
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+
+	filters, err := fs.readFilters()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.SavedFilter, 0, len(filters))
+	for _, filter := range filters {
+		result = append(result, filter)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// GetSavedFilter returns a saved filter by name
+func (fs *FileStore) GetSavedFilter(name string) (*models.SavedFilter, error) {
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+
+	filters, err := fs.readFilters()
+	if err != nil {
+		return nil, err
+	}
+
+	filter, ok := filters[name]
+	if !ok {
+		return nil, fmt.Errorf("filter not found: %s", name)
+	}
+
+	return &filter, nil
+}
+
+// SaveFilter creates or replaces a saved filter
+func (fs *FileStore) SaveFilter(filter *models.SavedFilter) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	filters, err := fs.readFilters()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if existing, ok := filters[filter.Name]; ok {
+		filter.CreatedAt = existing.CreatedAt
+	} else {
+		filter.CreatedAt = now
+	}
+	filter.UpdatedAt = now
+
+	filters[filter.Name] = *filter
+	return fs.writeFilters(filters)
+}
+
+// DeleteSavedFilter deletes a saved filter by name
+func (fs *FileStore) DeleteSavedFilter(name string) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	filters, err := fs.readFilters()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := filters[name]; !ok {
+		return fmt.Errorf("filter not found: %s", name)
+	}
+
+	delete(filters, name)
+	return fs.writeFilters(filters)
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// ErrInvalidID is returned for list, task and attachment IDs that aren't
+// safe to use as file names
+var ErrInvalidID = errors.New("invalid ID")
+
+// idPattern matches the IDs the stores accept: UUIDs, or any other name of
+// letters, digits, dashes and underscores. Without dots or separators an ID
+// can't refer to a path outside the data directory.
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// ValidateID returns ErrInvalidID unless id is safe to build a path from
+func ValidateID(id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+	return nil
+}
+
+// validateNewListIDs validates the IDs of a list and the tasks created with it
+func validateNewListIDs(list *models.TaskList, tasks []models.Task) error {
+	if err := ValidateID(list.ID); err != nil {
+		return err
+	}
+	for i := range tasks {
+		if err := ValidateID(tasks[i].ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateIDs validates several IDs, returning the first failure
+func validateIDs(ids ...string) error {
+	for _, id := range ids {
+		if err := ValidateID(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
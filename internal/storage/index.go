@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// taskIndex keeps every task of the file store in memory, keyed by list ID
+// and task ID, so reads don't touch the task files. A list is present once
+// its list file or tasks directory exists. Tasks are cloned on the way in and out so
+// callers never share slices with the index. Guarded by the store's mutex.
+type taskIndex map[string]map[string]models.Task
+
+// put stores a copy of a task under its list
+func (idx taskIndex) put(task *models.Task) {
+	tasks, ok := idx[task.ListID]
+	if !ok {
+		tasks = make(map[string]models.Task)
+		idx[task.ListID] = tasks
+	}
+	tasks[task.ID] = task.Clone()
+}
+
+// remove drops a task from the index
+func (idx taskIndex) remove(listID, taskID string) {
+	delete(idx[listID], taskID)
+}
+
+// setList replaces the tasks of a list
+func (idx taskIndex) setList(listID string, tasks []models.Task) {
+	idx[listID] = make(map[string]models.Task, len(tasks))
+	for i := range tasks {
+		idx[listID][tasks[i].ID] = tasks[i].Clone()
+	}
+}
+
+// listTasks returns copies of a list's tasks ordered by creation time, then
+// ID. ok is false when the list isn't indexed.
+func (idx taskIndex) listTasks(listID string) (tasks []models.Task, ok bool) {
+	indexed, ok := idx[listID]
+	if !ok {
+		return nil, false
+	}
+
+	for _, task := range indexed {
+		tasks = append(tasks, task.Clone())
+	}
+	models.SortTasksByCreation(tasks)
+	return tasks, true
+}
+
+// task returns a copy of a single task
+func (idx taskIndex) task(listID, taskID string) (*models.Task, bool) {
+	task, ok := idx[listID][taskID]
+	if !ok {
+		return nil, false
+	}
+	clone := task.Clone()
+	return &clone, true
+}
+
+// taskPathIDs returns the list and task IDs of a task file path
+func taskPathIDs(taskPath string) (listID, taskID string) {
+	listID = filepath.Base(filepath.Dir(filepath.Dir(taskPath)))
+	taskID = strings.TrimSuffix(filepath.Base(taskPath), ".json")
+	return listID, taskID
+}
+
+// readTasksFromDisk reads the task files of a list. Files that can't be read
+// or parsed are logged and skipped, and the first such failure is returned
+// as unreadable. Callers must hold the lock.
+func (fs *FileStore) readTasksFromDisk(listID string) (tasks []models.Task, unreadable error, err error) {
+	tasksDir := filepath.Join(fs.baseDir, "lists", listID, "tasks")
+
+	// A list whose tasks directory was never created, such as one on a
+	// fresh data directory or written by hand, has no tasks; only a missing
+	// list is an error
+	if _, err := os.Stat(tasksDir); os.IsNotExist(err) {
+		if _, err := os.Stat(filepath.Join(fs.baseDir, "lists", listID, "list.json")); err == nil {
+			return []models.Task{}, nil, nil
+		}
+		return nil, nil, fmt.Errorf("%w: %s", ErrListNotFound, listID)
+	}
+
+	files, err := os.ReadDir(tasksDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read tasks directory: %w", err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		taskPath := filepath.Join(tasksDir, file.Name())
+		var task models.Task
+		data, err := os.ReadFile(taskPath)
+		if err != nil {
+			err = fmt.Errorf("failed to read task %s: %w", taskPath, err)
+		} else if err = json.Unmarshal(data, &task); err != nil {
+			err = corruptData("task "+taskPath, err)
+		}
+		if err != nil {
+			log.Printf("Warning: skipping unreadable task: %v", err)
+			if unreadable == nil {
+				unreadable = err
+			}
+			continue
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	models.SortTasksByCreation(tasks)
+	return tasks, unreadable, nil
+}
+
+// buildIndex reads every list's tasks from disk into a new index. Must be
+// called with the write lock held.
+func (fs *FileStore) buildIndex() error {
+	entries, err := os.ReadDir(filepath.Join(fs.baseDir, "lists"))
+	if err != nil {
+		return fmt.Errorf("failed to read lists directory: %w", err)
+	}
+
+	index := make(taskIndex, len(entries))
+	unreadable := make(map[string]error)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		tasks, bad, err := fs.readTasksFromDisk(entry.Name())
+		if err != nil {
+			continue
+		}
+		index.setList(entry.Name(), tasks)
+		if bad != nil {
+			unreadable[entry.Name()] = bad
+		}
+	}
+
+	fs.index = index
+	fs.unreadable = unreadable
+	return nil
+}
+
+// recheckUnreadable rereads a list that had an unreadable task file when it
+// was indexed, so the file being fixed, rewritten or deleted is picked up by
+// the next write to the list. Must be called with the write lock held.
+func (fs *FileStore) recheckUnreadable(listID string) {
+	if fs.unreadable[listID] == nil {
+		return
+	}
+	tasks, bad, err := fs.readTasksFromDisk(listID)
+	if err != nil {
+		return
+	}
+	fs.index.setList(listID, tasks)
+	if bad == nil {
+		delete(fs.unreadable, listID)
+	} else {
+		fs.unreadable[listID] = bad
+	}
+}
+
+// RebuildIndex rereads every task from disk, picking up changes made to the
+// data directory outside the store
+func (fs *FileStore) RebuildIndex() error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	return fs.buildIndex()
+}
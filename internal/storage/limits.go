@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// ErrTaskLimit is returned when creating tasks would exceed the limits set
+// with SetTaskLimits
+var ErrTaskLimit = errors.New("task limit reached")
+
+// TaskLimits caps how many tasks a store holds. Zero means no limit.
+type TaskLimits struct {
+	PerList int // Tasks in any one list
+	Total   int // Tasks across all lists
+}
+
+// check reports whether adding tasks to a list that holds listCount of the
+// store's total tasks stays within the limits
+func (l TaskLimits) check(listCount, total, adding int) error {
+	if l.PerList > 0 && listCount+adding > l.PerList {
+		return fmt.Errorf("%w: a list may hold at most %d tasks", ErrTaskLimit, l.PerList)
+	}
+	if l.Total > 0 && total+adding > l.Total {
+		return fmt.Errorf("%w: at most %d tasks may exist", ErrTaskLimit, l.Total)
+	}
+	return nil
+}
+
+// checkMove reports whether moving a task into a list holding listCount
+// tasks stays within the per-list limit; a move leaves the total unchanged
+func (l TaskLimits) checkMove(listCount int) error {
+	return TaskLimits{PerList: l.PerList}.check(listCount, 0, 1)
+}
+
+// checkSpread reports whether adding tasks to several lists, adding[listID]
+// to each, stays within the limits given the lists' current counts
+func (l TaskLimits) checkSpread(listCounts map[string]int, total int, adding map[string]int) error {
+	sum := 0
+	for listID, n := range adding {
+		if err := (TaskLimits{PerList: l.PerList}).check(listCounts[listID], 0, n); err != nil {
+			return err
+		}
+		sum += n
+	}
+	return TaskLimits{Total: l.Total}.check(0, total, sum)
+}
+
+// countByList counts tasks per list
+func countByList(tasks []models.Task) map[string]int {
+	counts := make(map[string]int)
+	for _, task := range tasks {
+		counts[task.ListID]++
+	}
+	return counts
+}
+
+// SetTaskLimits caps the number of tasks the store holds. Creating, moving,
+// restoring from the trash and undoing deletes are refused when they would
+// exceed the limits, but tasks already stored are kept even if they do.
+func (fs *FileStore) SetTaskLimits(limits TaskLimits) {
+	fs.limits = limits
+}
+
+// checkTaskLimits checks adding tasks to a list against the limits. Callers
+// must hold the lock.
+func (fs *FileStore) checkTaskLimits(listID string, adding int) error {
+	total := 0
+	for _, tasks := range fs.index {
+		total += len(tasks)
+	}
+	return fs.limits.check(len(fs.index[listID]), total, adding)
+}
+
+// checkMoveLimits checks moving a task into a list against the limits.
+// Callers must hold the lock.
+func (fs *FileStore) checkMoveLimits(listID string) error {
+	return fs.limits.checkMove(len(fs.index[listID]))
+}
+
+// checkRestoreLimits checks putting tasks back into their lists against the
+// limits. Callers must hold the lock.
+func (fs *FileStore) checkRestoreLimits(tasks []models.Task) error {
+	listCounts := make(map[string]int, len(fs.index))
+	total := 0
+	for listID, listTasks := range fs.index {
+		listCounts[listID] = len(listTasks)
+		total += len(listTasks)
+	}
+	return fs.limits.checkSpread(listCounts, total, countByList(tasks))
+}
+
+// SetTaskLimits caps the number of tasks the store holds, as for FileStore
+func (s *SQLiteStore) SetTaskLimits(limits TaskLimits) {
+	s.limits = limits
+}
+
+// checkTaskLimits checks adding tasks to a list against the limits
+func (s *SQLiteStore) checkTaskLimits(tx *sql.Tx, listID string, adding int) error {
+	if s.limits == (TaskLimits{}) {
+		return nil
+	}
+
+	var listCount, total int
+	err := tx.QueryRow(`SELECT COUNT(CASE WHEN list_id = ? THEN 1 END), COUNT(*) FROM tasks`, listID).Scan(&listCount, &total)
+	if err != nil {
+		return fmt.Errorf("failed to count tasks: %w", err)
+	}
+	return s.limits.check(listCount, total, adding)
+}
+
+// checkMoveLimits checks moving a task into a list against the limits
+func (s *SQLiteStore) checkMoveLimits(tx *sql.Tx, listID string) error {
+	if s.limits.PerList == 0 {
+		return nil
+	}
+
+	var listCount int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM tasks WHERE list_id = ?`, listID).Scan(&listCount); err != nil {
+		return fmt.Errorf("failed to count tasks: %w", err)
+	}
+	return s.limits.checkMove(listCount)
+}
+
+// checkRestoreLimits checks putting tasks back into their lists against the
+// limits
+func (s *SQLiteStore) checkRestoreLimits(tx *sql.Tx, tasks []models.Task) error {
+	if s.limits == (TaskLimits{}) {
+		return nil
+	}
+
+	rows, err := tx.Query(`SELECT list_id, COUNT(*) FROM tasks GROUP BY list_id`)
+	if err != nil {
+		return fmt.Errorf("failed to count tasks: %w", err)
+	}
+	defer rows.Close()
+
+	listCounts := make(map[string]int)
+	total := 0
+	for rows.Next() {
+		var listID string
+		var count int
+		if err := rows.Scan(&listID, &count); err != nil {
+			return fmt.Errorf("failed to count tasks: %w", err)
+		}
+		listCounts[listID] = count
+		total += count
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to count tasks: %w", err)
+	}
+	return s.limits.checkSpread(listCounts, total, countByList(tasks))
+}
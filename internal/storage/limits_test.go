@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+// setTestLimits sets the task limits of either store implementation
+func setTestLimits(store TaskStore, limits TaskLimits) {
+	store.(interface{ SetTaskLimits(TaskLimits) }).SetTaskLimits(limits)
+}
+
+func TestMoveTaskRespectsPerListLimit(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store TaskStore) {
+		createTestList(t, store, "a")
+		createTestList(t, store, "b")
+		createTestTask(t, store, "a", "moving")
+		createTestTask(t, store, "b", "resident")
+		setTestLimits(store, TaskLimits{PerList: 1, Total: 2})
+
+		if _, err := store.MoveTask("a", "moving", "b", MoveOptions{}); !errors.Is(err, ErrTaskLimit) {
+			t.Fatalf("MoveTask into a full list: got %v, want ErrTaskLimit", err)
+		}
+		if _, err := store.GetTask("a", "moving"); err != nil {
+			t.Errorf("task after rejected move: %v", err)
+		}
+
+		// A move leaves the total unchanged, so only the per-list limit applies
+		if err := store.DeleteTask("b", "resident"); err != nil {
+			t.Fatalf("DeleteTask: %v", err)
+		}
+		createTestList(t, store, "c")
+		createTestTask(t, store, "c", "other")
+		if _, err := store.MoveTask("a", "moving", "b", MoveOptions{}); err != nil {
+			t.Errorf("MoveTask with the total at its limit: %v", err)
+		}
+	})
+}
+
+func TestUndoMoveRespectsPerListLimit(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store TaskStore) {
+		createTestList(t, store, "a")
+		createTestList(t, store, "b")
+		createTestTask(t, store, "a", "moving")
+		createTestTask(t, store, "a", "resident")
+		if _, err := store.MoveTask("a", "moving", "b", MoveOptions{}); err != nil {
+			t.Fatalf("MoveTask: %v", err)
+		}
+		setTestLimits(store, TaskLimits{PerList: 1})
+
+		if _, err := store.Undo(); !errors.Is(err, ErrTaskLimit) {
+			t.Fatalf("undoing a move into a full list: got %v, want ErrTaskLimit", err)
+		}
+		if _, err := store.GetTask("b", "moving"); err != nil {
+			t.Errorf("task after rejected undo: %v", err)
+		}
+	})
+}
+
+func TestRestoreTaskRespectsLimits(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store TaskStore) {
+		createTestList(t, store, "a")
+		createTestTask(t, store, "a", "deleted")
+		if err := store.DeleteTask("a", "deleted"); err != nil {
+			t.Fatalf("DeleteTask: %v", err)
+		}
+		createTestTask(t, store, "a", "replacement")
+		setTestLimits(store, TaskLimits{PerList: 1})
+
+		if _, err := store.RestoreTask("deleted"); !errors.Is(err, ErrTaskLimit) {
+			t.Fatalf("RestoreTask into a full list: got %v, want ErrTaskLimit", err)
+		}
+		trash, err := store.GetTrash()
+		if err != nil || len(trash) != 1 {
+			t.Errorf("trash after rejected restore: %d tasks, %v; want the deleted task kept", len(trash), err)
+		}
+	})
+}
+
+func TestUndoDeleteRespectsLimits(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store TaskStore) {
+		createTestList(t, store, "a")
+		createTestTask(t, store, "a", "kept")
+		createTestTask(t, store, "a", "deleted")
+		if err := store.DeleteTask("a", "deleted"); err != nil {
+			t.Fatalf("DeleteTask: %v", err)
+		}
+		setTestLimits(store, TaskLimits{PerList: 1})
+
+		if _, err := store.Undo(); !errors.Is(err, ErrTaskLimit) {
+			t.Fatalf("undoing a delete into a full list: got %v, want ErrTaskLimit", err)
+		}
+		if _, err := store.GetTask("a", "deleted"); !errors.Is(err, ErrTaskNotFound) {
+			t.Errorf("task after rejected undo: got %v, want ErrTaskNotFound", err)
+		}
+	})
+}
+
+func TestUndoDeleteListRespectsTotalLimit(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store TaskStore) {
+		createTestList(t, store, "a")
+		createTestList(t, store, "b")
+		createTestTask(t, store, "a", "kept")
+		createTestTask(t, store, "b", "first")
+		createTestTask(t, store, "b", "second")
+		if err := store.DeleteList("b"); err != nil {
+			t.Fatalf("DeleteList: %v", err)
+		}
+		setTestLimits(store, TaskLimits{Total: 2})
+
+		if _, err := store.Undo(); !errors.Is(err, ErrTaskLimit) {
+			t.Fatalf("undoing a list delete past the total: got %v, want ErrTaskLimit", err)
+		}
+		if _, err := store.GetList("b"); !errors.Is(err, ErrListNotFound) {
+			t.Errorf("list after rejected undo: got %v, want ErrListNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// stampNewTask sets the fields CreateTask assigns to a new task
+func stampNewTask(task *models.Task, now time.Time) {
+	task.CreatedAt = now
+	task.UpdatedAt = now
+	task.Version = 1
+	if task.StateTime.IsZero() {
+		task.StateTime = now
+	}
+	task.RecordCompletion(nil, now)
+}
+
+// NewList is a list to create together with its tasks, see CreateLists
+type NewList struct {
+	List  *models.TaskList
+	Tasks []models.Task
+}
+
+// validateNewLists checks the IDs of lists about to be created, including
+// that no list or task ID appears twice among them
+func validateNewLists(lists []NewList) error {
+	seen := make(map[string]bool)
+	for _, nl := range lists {
+		if err := validateNewListIDs(nl.List, nl.Tasks); err != nil {
+			return err
+		}
+		if seen["list/"+nl.List.ID] {
+			return fmt.Errorf("%w: %s", ErrListExists, nl.List.ID)
+		}
+		seen["list/"+nl.List.ID] = true
+		for i := range nl.Tasks {
+			if seen["task/"+nl.Tasks[i].ID] {
+				return fmt.Errorf("%w: %s", ErrTaskExists, nl.Tasks[i].ID)
+			}
+			seen["task/"+nl.Tasks[i].ID] = true
+		}
+	}
+	return nil
+}
+
+// CreateListWithTasks creates a list together with its tasks. The list is
+// assembled in a temporary directory and moved into place in one rename, so
+// a failure partway leaves no list behind.
+func (fs *FileStore) CreateListWithTasks(list *models.TaskList, tasks []models.Task) error {
+	return fs.CreateLists([]NewList{{List: list, Tasks: tasks}})
+}
+
+// CreateLists creates several lists with their tasks, either all of them or
+// none. Each list is assembled in a temporary directory and moved into place
+// in one rename; if a later rename fails, the lists already moved are
+// removed again.
+func (fs *FileStore) CreateLists(lists []NewList) error {
+	if err := validateNewLists(lists); err != nil {
+		return err
+	}
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	total := 0
+	for _, tasks := range fs.index {
+		total += len(tasks)
+	}
+	for _, nl := range lists {
+		if _, err := os.Stat(filepath.Join(fs.baseDir, "lists", nl.List.ID)); err == nil {
+			return fmt.Errorf("%w: %s", ErrListExists, nl.List.ID)
+		}
+		if err := fs.limits.check(0, total, len(nl.Tasks)); err != nil {
+			return err
+		}
+		total += len(nl.Tasks)
+		for i := range nl.Tasks {
+			if _, err := fs.findTask(nl.Tasks[i].ID); err == nil {
+				return fmt.Errorf("%w: %s", ErrTaskExists, nl.Tasks[i].ID)
+			}
+		}
+	}
+
+	now := time.Now()
+	tmpDirs := make([]string, 0, len(lists))
+	defer func() {
+		for _, dir := range tmpDirs {
+			os.RemoveAll(dir)
+		}
+	}()
+	for _, nl := range lists {
+		tmpDir, err := fs.assembleList(nl.List, nl.Tasks, now)
+		if tmpDir != "" {
+			tmpDirs = append(tmpDirs, tmpDir)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, nl := range lists {
+		if err := os.Rename(tmpDirs[i], filepath.Join(fs.baseDir, "lists", nl.List.ID)); err != nil {
+			for _, created := range lists[:i] {
+				os.RemoveAll(filepath.Join(fs.baseDir, "lists", created.List.ID))
+			}
+			return fmt.Errorf("failed to create list directory: %w", err)
+		}
+	}
+	for _, nl := range lists {
+		fs.index.setList(nl.List.ID, nl.Tasks)
+	}
+	return nil
+}
+
+// assembleList writes a new list and its tasks to a temporary directory,
+// returning the directory for the caller to move into place or remove
+func (fs *FileStore) assembleList(list *models.TaskList, tasks []models.Task, now time.Time) (string, error) {
+	tmpDir, err := os.MkdirTemp(fs.baseDir, ".list-"+list.ID+"-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create list directory: %w", err)
+	}
+	if err := os.Chmod(tmpDir, 0755); err != nil {
+		return tmpDir, fmt.Errorf("failed to create list directory: %w", err)
+	}
+
+	list.CreatedAt = now
+	list.UpdatedAt = now
+
+	data, err := fs.marshal(list)
+	if err != nil {
+		return tmpDir, fmt.Errorf("failed to serialize list: %w", err)
+	}
+	if err := fs.writeFile(filepath.Join(tmpDir, "list.json"), data, 0644); err != nil {
+		return tmpDir, fmt.Errorf("failed to write list file: %w", err)
+	}
+
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	if err := os.MkdirAll(tasksDir, 0755); err != nil {
+		return tmpDir, fmt.Errorf("failed to create tasks directory: %w", err)
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		task.ListID = list.ID
+		stampNewTask(task, now)
+
+		data, err := fs.marshal(task)
+		if err != nil {
+			return tmpDir, fmt.Errorf("failed to serialize task: %w", err)
+		}
+		if err := fs.writeFile(filepath.Join(tasksDir, task.ID+".json"), data, 0644); err != nil {
+			return tmpDir, fmt.Errorf("failed to write task file: %w", err)
+		}
+	}
+	return tmpDir, nil
+}
+
+// CreateListWithTasks creates a list together with its tasks in a single
+// transaction
+func (s *SQLiteStore) CreateListWithTasks(list *models.TaskList, tasks []models.Task) error {
+	return s.CreateLists([]NewList{{List: list, Tasks: tasks}})
+}
+
+// CreateLists creates several lists with their tasks in a single
+// transaction, so either all of them are created or none
+func (s *SQLiteStore) CreateLists(lists []NewList) error {
+	if err := validateNewLists(lists); err != nil {
+		return err
+	}
+	return s.inTx(func(tx *sql.Tx) error {
+		now := time.Now()
+		for _, nl := range lists {
+			list, tasks := nl.List, nl.Tasks
+			exists, err := listExists(tx, list.ID)
+			if err != nil {
+				return err
+			}
+			if exists {
+				return fmt.Errorf("%w: %s", ErrListExists, list.ID)
+			}
+			if err := s.checkTaskLimits(tx, list.ID, len(tasks)); err != nil {
+				return err
+			}
+			for i := range tasks {
+				exists, err := taskExists(tx, tasks[i].ID)
+				if err != nil {
+					return err
+				}
+				if exists {
+					return fmt.Errorf("%w: %s", ErrTaskExists, tasks[i].ID)
+				}
+			}
+
+			list.CreatedAt = now
+			list.UpdatedAt = now
+			if err := putList(tx, list); err != nil {
+				return err
+			}
+
+			for i := range tasks {
+				task := &tasks[i]
+				task.ListID = list.ID
+				stampNewTask(task, now)
+				if err := putTask(tx, task); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// reorderLists assigns Order 1..n following ids, placing any list not named
+// after them in its current order. lists must already be sorted. It returns
+// the lists whose order changed, or an error naming the first unknown ID.
+func reorderLists(lists []models.TaskList, ids []string) ([]*models.TaskList, error) {
+	byID := make(map[string]*models.TaskList, len(lists))
+	for i := range lists {
+		byID[lists[i].ID] = &lists[i]
+	}
+
+	ordered := make([]*models.TaskList, 0, len(lists))
+	placed := make(map[string]bool, len(lists))
+	for _, id := range ids {
+		list, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrListNotFound, id)
+		}
+		if !placed[id] {
+			placed[id] = true
+			ordered = append(ordered, list)
+		}
+	}
+	for i := range lists {
+		if !placed[lists[i].ID] {
+			ordered = append(ordered, &lists[i])
+		}
+	}
+
+	now := time.Now()
+	var changed []*models.TaskList
+	for i, list := range ordered {
+		if list.Order != i+1 {
+			list.Order = i + 1
+			list.UpdatedAt = now
+			changed = append(changed, list)
+		}
+	}
+	return changed, nil
+}
+
+// ReorderLists sets the display order of the lists to follow ids. Lists not
+// named keep their relative order after the named ones.
+func (fs *FileStore) ReorderLists(ids []string) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	lists, err := fs.readAllLists(false)
+	if err != nil {
+		return err
+	}
+
+	changed, err := reorderLists(lists, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, list := range changed {
+		data, err := fs.marshal(list)
+		if err != nil {
+			return fmt.Errorf("failed to serialize list: %w", err)
+		}
+		listPath := filepath.Join(fs.baseDir, "lists", list.ID, "list.json")
+		if err := fs.writeFile(listPath, data, 0644); err != nil {
+			return fmt.Errorf("failed to write list file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ReorderLists sets the display order of the lists to follow ids. Lists not
+// named keep their relative order after the named ones.
+func (s *SQLiteStore) ReorderLists(ids []string) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query(`SELECT data FROM lists`)
+		if err != nil {
+			return fmt.Errorf("failed to query lists: %w", err)
+		}
+
+		var lists []models.TaskList
+		for rows.Next() {
+			var data string
+			if err := rows.Scan(&data); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to read list: %w", err)
+			}
+			var list models.TaskList
+			if err := json.Unmarshal([]byte(data), &list); err != nil {
+				log.Printf("Warning: not reordering unreadable list: %v", err)
+				continue
+			}
+			lists = append(lists, list)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to query lists: %w", err)
+		}
+		models.SortLists(lists)
+
+		changed, err := reorderLists(lists, ids)
+		if err != nil {
+			return err
+		}
+		for _, list := range changed {
+			if err := putList(tx, list); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// ErrListExists is returned when a list ID is already taken
+var ErrListExists = errors.New("list already exists")
+
+// relistTask points a task at the list's new ID, recording the change in its
+// history
+func relistTask(task *models.Task, newID string, now time.Time) {
+	previous := task.Clone()
+	task.ListID = newID
+	task.UpdatedAt = now
+	task.Version++
+	task.RecordChanges(&previous, now)
+}
+
+// relistTrashedTask points a deleted task at the list's new ID. Its history
+// is left alone, as it is no longer a live task.
+func relistTrashedTask(data []byte, newID string) ([]byte, error) {
+	var task models.Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		return nil, corruptData("deleted task", err)
+	}
+	task.ListID = newID
+	return json.Marshal(task)
+}
+
+// RenameListID changes a list's ID to newID, moving its directory and
+// pointing every task in it, deleted ones included, at the new ID. The
+// directory is moved first, so a failure while rewriting the task files
+// leaves the list under its new ID with some tasks still naming the old one.
+func (fs *FileStore) RenameListID(id, newID string) (*models.TaskList, error) {
+	if err := validateIDs(id, newID); err != nil {
+		return nil, err
+	}
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	list, err := fs.readList(id)
+	if err != nil {
+		return nil, err
+	}
+	if id == newID {
+		return list, nil
+	}
+
+	newDir := filepath.Join(fs.baseDir, "lists", newID)
+	if _, err := os.Stat(newDir); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrListExists, newID)
+	}
+
+	tasks, err := fs.readTasksForList(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(filepath.Join(fs.baseDir, "lists", id), newDir); err != nil {
+		return nil, fmt.Errorf("failed to move list directory: %w", err)
+	}
+	delete(fs.index, id)
+	if bad, ok := fs.unreadable[id]; ok {
+		delete(fs.unreadable, id)
+		fs.unreadable[newID] = bad
+	}
+
+	now := time.Now()
+	list.ID = newID
+	list.UpdatedAt = now
+	data, err := fs.marshal(list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize list: %w", err)
+	}
+	if err := fs.writeFile(filepath.Join(newDir, "list.json"), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write list file: %w", err)
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		relistTask(task, newID, now)
+		data, err := fs.marshal(task)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize task: %w", err)
+		}
+		if err := fs.writeFile(filepath.Join(newDir, "tasks", task.ID+".json"), data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write task file: %w", err)
+		}
+	}
+	fs.index.setList(newID, tasks)
+
+	trashFiles, err := filepath.Glob(filepath.Join(newDir, "trash", "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+	for _, trashPath := range trashFiles {
+		data, err := os.ReadFile(trashPath)
+		if err == nil {
+			data, err = relistTrashedTask(data, newID)
+		}
+		if err != nil {
+			log.Printf("Warning: not moving unreadable deleted task %s: %v", trashPath, err)
+			continue
+		}
+		if err := fs.writeFile(trashPath, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write trash file: %w", err)
+		}
+	}
+
+	return list, nil
+}
+
+// RenameListID changes a list's ID to newID in a single transaction,
+// pointing every task in it, deleted ones included, at the new ID
+func (s *SQLiteStore) RenameListID(id, newID string) (*models.TaskList, error) {
+	if err := validateIDs(id, newID); err != nil {
+		return nil, err
+	}
+
+	var list *models.TaskList
+	err := s.inTx(func(tx *sql.Tx) error {
+		var err error
+		list, err = getList(tx, id)
+		if err != nil || id == newID {
+			return err
+		}
+
+		exists, err := listExists(tx, newID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("%w: %s", ErrListExists, newID)
+		}
+
+		tasks, err := queryTasks(tx, s.strict, `WHERE t.list_id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		list.ID = newID
+		list.UpdatedAt = now
+		if err := putList(tx, list); err != nil {
+			return err
+		}
+
+		for i := range tasks {
+			relistTask(&tasks[i], newID, now)
+			if err := putTask(tx, &tasks[i]); err != nil {
+				return err
+			}
+		}
+
+		if err := relistSQLiteTrash(tx, id, newID); err != nil {
+			return err
+		}
+
+		// Anything left, such as tasks too corrupt to read, goes with the
+		// old list
+		if _, err := tx.Exec(`DELETE FROM lists WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete list: %w", err)
+		}
+
+		from := filepath.Join(s.filesDir, "lists", id)
+		if _, err := os.Stat(from); os.IsNotExist(err) {
+			return nil
+		}
+		if err := os.Rename(from, filepath.Join(s.filesDir, "lists", newID)); err != nil {
+			return fmt.Errorf("failed to move attachments: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// relistSQLiteTrash points the deleted tasks of a list at its new ID
+func relistSQLiteTrash(tx *sql.Tx, id, newID string) error {
+	rows, err := tx.Query(`SELECT id, data FROM trash WHERE list_id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	updated := make(map[string][]byte)
+	for rows.Next() {
+		var taskID, data string
+		if err := rows.Scan(&taskID, &data); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read trash: %w", err)
+		}
+		relisted, err := relistTrashedTask([]byte(data), newID)
+		if err != nil {
+			log.Printf("Warning: not moving unreadable deleted task %s: %v", taskID, err)
+			continue
+		}
+		updated[taskID] = relisted
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	for taskID, data := range updated {
+		if _, err := tx.Exec(`UPDATE trash SET list_id = ?, data = ? WHERE id = ?`, newID, string(data), taskID); err != nil {
+			return fmt.Errorf("failed to write trash: %w", err)
+		}
+	}
+	return nil
+}
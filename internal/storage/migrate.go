@@ -0,0 +1,315 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// SchemaVersion is the version of the stored data format this build writes.
+// Data written at an older version is migrated when a store is opened, and
+// data from a newer release is refused rather than risk losing its fields.
+const SchemaVersion = 1
+
+// migration upgrades stored lists and tasks by one schema version. Tasks are
+// migrated after their list, which is passed along for its workflow states.
+type migration struct {
+	list func(list *models.TaskList)
+	task func(list *models.TaskList, task *models.Task)
+}
+
+// migrations[i] upgrades data from schema version i to i+1. To change the
+// format, bump SchemaVersion and append a step.
+var migrations = [SchemaVersion]migration{
+	{list: migrateListV1, task: migrateTaskV1},
+}
+
+// migrateListV1 fills in list fields older releases could leave unset
+func migrateListV1(list *models.TaskList) {
+	if list.UpdatedAt.IsZero() {
+		list.UpdatedAt = list.CreatedAt
+	}
+	if list.Archived && list.ArchivedAt == nil {
+		archivedAt := list.UpdatedAt
+		list.ArchivedAt = &archivedAt
+	}
+}
+
+// migrateTaskV1 fills in task fields older releases could leave unset: the
+// state and its timestamp, the version counter and the completion time, which
+// for a done task is when it entered that state
+func migrateTaskV1(list *models.TaskList, task *models.Task) {
+	task.ListID = list.ID
+	if task.State == "" {
+		task.State = list.TaskStates()[0]
+	}
+	if task.UpdatedAt.IsZero() {
+		task.UpdatedAt = task.CreatedAt
+	}
+	if task.StateTime.IsZero() {
+		task.StateTime = task.UpdatedAt
+	}
+	if task.Version == 0 {
+		task.Version = 1
+	}
+	if task.State == models.TaskStateDone && task.CompletedAt == nil {
+		completedAt := task.StateTime
+		task.CompletedAt = &completedAt
+	}
+
+	for i := range task.SubTasks {
+		subTask := &task.SubTasks[i]
+		subTask.ListID = list.ID
+		if subTask.State == "" {
+			subTask.State = list.TaskStates()[0]
+		}
+		if subTask.UpdatedAt.IsZero() {
+			subTask.UpdatedAt = subTask.CreatedAt
+		}
+		if subTask.StateTime.IsZero() {
+			subTask.StateTime = subTask.UpdatedAt
+		}
+	}
+}
+
+// migrateList applies every migration step after version from to a list
+func migrateList(from int, list *models.TaskList) {
+	for _, step := range migrations[from:] {
+		step.list(list)
+	}
+}
+
+// migrateTask applies every migration step after version from to a task
+func migrateTask(from int, list *models.TaskList, task *models.Task) {
+	for _, step := range migrations[from:] {
+		step.task(list, task)
+	}
+}
+
+// checkSchemaVersion rejects data written by a newer release
+func checkSchemaVersion(version int) error {
+	if version < 0 || version > SchemaVersion {
+		return fmt.Errorf("stored data has schema version %d, but this release supports up to %d", version, SchemaVersion)
+	}
+	return nil
+}
+
+// schemaMarker is the content of the file store's schema.json
+type schemaMarker struct {
+	Version int `json:"version"`
+}
+
+// schemaPath returns the path of the file recording the data directory's
+// schema version
+func (fs *FileStore) schemaPath() string {
+	return filepath.Join(fs.baseDir, "schema.json")
+}
+
+// readSchemaVersion returns the data directory's schema version. Directories
+// written before the marker existed are version 0.
+func (fs *FileStore) readSchemaVersion() (int, error) {
+	data, err := os.ReadFile(fs.schemaPath())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	var marker schemaMarker
+	if err := json.Unmarshal(data, &marker); err != nil {
+		return 0, fmt.Errorf("failed to parse schema version: %w", err)
+	}
+	return marker.Version, nil
+}
+
+// migrate brings the data directory up to SchemaVersion, rewriting every list
+// and task, including those in the trash, and then recording the new version.
+// Files that can't be read are left alone. Called by NewFileStore before the
+// index is built.
+func (fs *FileStore) migrate() error {
+	version, err := fs.readSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if err := checkSchemaVersion(version); err != nil {
+		return err
+	}
+	if version == SchemaVersion {
+		return nil
+	}
+
+	entries, err := os.ReadDir(filepath.Join(fs.baseDir, "lists"))
+	if err != nil {
+		return fmt.Errorf("failed to read lists directory: %w", err)
+	}
+
+	migrated := 0
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		list, err := fs.readList(entry.Name())
+		if err != nil {
+			log.Printf("Warning: not migrating list %s: %v", entry.Name(), err)
+			continue
+		}
+		list.ID = entry.Name()
+
+		migrateList(version, list)
+		data, err := fs.marshal(list)
+		if err != nil {
+			return fmt.Errorf("failed to serialize list: %w", err)
+		}
+		if err := fs.writeFile(filepath.Join(fs.baseDir, "lists", list.ID, "list.json"), data, 0644); err != nil {
+			return fmt.Errorf("failed to write list file: %w", err)
+		}
+
+		for _, dir := range []string{"tasks", "trash"} {
+			n, err := fs.migrateTaskDir(version, list, filepath.Join(fs.baseDir, "lists", list.ID, dir))
+			if err != nil {
+				return err
+			}
+			migrated += n
+		}
+	}
+
+	data, err := json.Marshal(schemaMarker{Version: SchemaVersion})
+	if err != nil {
+		return fmt.Errorf("failed to serialize schema version: %w", err)
+	}
+	if err := fs.writeFile(fs.schemaPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write schema version: %w", err)
+	}
+
+	if migrated > 0 {
+		log.Printf("Migrated %d tasks from schema version %d to %d", migrated, version, SchemaVersion)
+	}
+	return nil
+}
+
+// migrateTaskDir rewrites the task files in dir, returning how many were
+// migrated. A missing directory holds no tasks.
+func (fs *FileStore) migrateTaskDir(version int, list *models.TaskList, dir string) (int, error) {
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read tasks directory: %w", err)
+	}
+
+	migrated := 0
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		taskPath := filepath.Join(dir, file.Name())
+		data, err := os.ReadFile(taskPath)
+		if err != nil {
+			log.Printf("Warning: not migrating task %s: %v", taskPath, err)
+			continue
+		}
+		var task models.Task
+		if err := json.Unmarshal(data, &task); err != nil {
+			log.Printf("Warning: not migrating task %s: %v", taskPath, err)
+			continue
+		}
+
+		migrateTask(version, list, &task)
+		if data, err = fs.marshal(task); err != nil {
+			return migrated, fmt.Errorf("failed to serialize task: %w", err)
+		}
+		if err := fs.writeFile(taskPath, data, 0644); err != nil {
+			return migrated, fmt.Errorf("failed to write task file: %w", err)
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// migrate brings the database up to SchemaVersion in one transaction,
+// rewriting every list and task. The version is kept in SQLite's
+// user_version pragma.
+func (s *SQLiteStore) migrate() error {
+	var version int
+	if err := s.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if err := checkSchemaVersion(version); err != nil {
+		return err
+	}
+	if version == SchemaVersion {
+		return nil
+	}
+
+	migrated := 0
+	err := s.inTx(func(tx *sql.Tx) error {
+		rows, err := tx.Query(`SELECT data FROM lists`)
+		if err != nil {
+			return fmt.Errorf("failed to read lists: %w", err)
+		}
+		lists := make(map[string]*models.TaskList)
+		for rows.Next() {
+			var data string
+			if err := rows.Scan(&data); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to read list: %w", err)
+			}
+			var list models.TaskList
+			if err := json.Unmarshal([]byte(data), &list); err != nil {
+				log.Printf("Warning: not migrating unreadable list: %v", err)
+				continue
+			}
+			lists[list.ID] = &list
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read lists: %w", err)
+		}
+
+		for _, list := range lists {
+			migrateList(version, list)
+			if err := putList(tx, list); err != nil {
+				return err
+			}
+		}
+
+		tasks, err := queryTasks(tx, false, "")
+		if err != nil {
+			return err
+		}
+		for i := range tasks {
+			list, ok := lists[tasks[i].ListID]
+			if !ok {
+				continue
+			}
+			migrateTask(version, list, &tasks[i])
+			if err := putTask(tx, &tasks[i]); err != nil {
+				return err
+			}
+			migrated++
+		}
+
+		// PRAGMA doesn't take bound parameters
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, SchemaVersion)); err != nil {
+			return fmt.Errorf("failed to record schema version: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if migrated > 0 {
+		log.Printf("Migrated %d tasks from schema version %d to %d", migrated, version, SchemaVersion)
+	}
+	return nil
+}
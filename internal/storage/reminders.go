@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// markReminderSent sets the ReminderSent of a task that is still due on due
+func markReminderSent(task *models.Task, due, sent time.Time) error {
+	if task.DueDate == nil || !task.DueDate.Equal(due) {
+		return fmt.Errorf("%w: due date of %s changed since the reminder was sent", ErrVersionConflict, task.ID)
+	}
+	task.ReminderSent = &sent
+	return nil
+}
+
+// MarkReminderSent records that a reminder for due, the task's due date, was
+// sent. This is bookkeeping rather than an edit: the task's version, update
+// time and history are left alone and nothing is added to the undo journal,
+// so reminders never get in the way of the user's own changes.
+func (fs *FileStore) MarkReminderSent(listID, taskID string, due, sent time.Time) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	taskPath, task, err := fs.readTaskFile(listID, taskID)
+	if err != nil {
+		return err
+	}
+	if err := markReminderSent(task, due, sent); err != nil {
+		return err
+	}
+
+	data, err := fs.marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to serialize task: %w", err)
+	}
+	if err := fs.writeFile(taskPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write task file: %w", err)
+	}
+	fs.index.put(task)
+	return nil
+}
+
+// MarkReminderSent records that a reminder for due, the task's due date, was
+// sent, leaving its version, history and the undo journal alone
+func (s *SQLiteStore) MarkReminderSent(listID, taskID string, due, sent time.Time) error {
+	if err := validateIDs(listID, taskID); err != nil {
+		return err
+	}
+	return s.inTx(func(tx *sql.Tx) error {
+		task, err := getTaskInList(tx, listID, taskID)
+		if err != nil {
+			return err
+		}
+		if err := markReminderSent(task, due, sent); err != nil {
+			return err
+		}
+		return putTask(tx, task)
+	})
+}
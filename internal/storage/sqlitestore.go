@@ -0,0 +1,1196 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jbutlerdev/tasks/internal/models"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteSchema creates the tables used by SQLiteStore. Lists and tasks keep
+// their full JSON representation in a data column alongside the columns used
+// for lookups, so new model fields need no schema change. Notes live in their
+// own table and are stripped from the task JSON.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS lists (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	data       TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS tasks (
+	id         TEXT PRIMARY KEY,
+	list_id    TEXT NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+	title      TEXT NOT NULL,
+	state      TEXT NOT NULL,
+	due_date   TEXT,
+	created_at TEXT NOT NULL,
+	data       TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS tasks_list_id ON tasks(list_id);
+
+CREATE TABLE IF NOT EXISTS notes (
+	task_id    TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	position   INTEGER NOT NULL,
+	id         TEXT NOT NULL,
+	content    TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL,
+	PRIMARY KEY (task_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS saved_filters (
+	name TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS undo_entries (
+	seq  INTEGER PRIMARY KEY AUTOINCREMENT,
+	data TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS trash (
+	id         TEXT PRIMARY KEY,
+	list_id    TEXT NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+	deleted_at TEXT NOT NULL,
+	data       TEXT NOT NULL
+);
+`
+
+// SQLiteStore implements TaskStore on top of a single SQLite database file
+type SQLiteStore struct {
+	db         *sql.DB
+	filesDir   string     // Data directory holding attachment files
+	hardDelete bool       // Remove deleted tasks instead of moving them to the trash
+	strict     bool       // Fail reads on unparseable rows instead of skipping them
+	limits     TaskLimits // See SetTaskLimits
+}
+
+// NewSQLiteStore opens (creating if needed) the SQLite database at path
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// SQLite allows a single writer; serializing connections avoids
+	// "database is locked" errors under concurrent requests
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	}
+
+	s := &SQLiteStore{db: db, filesDir: filepath.Dir(path)}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetHardDelete makes DeleteTask remove tasks permanently instead of moving
+// them into the trash
+func (s *SQLiteStore) SetHardDelete(hard bool) {
+	s.hardDelete = hard
+}
+
+// SetStrict makes reads of many lists or tasks fail when a row's data can't
+// be parsed, instead of logging a warning and skipping it
+func (s *SQLiteStore) SetStrict(strict bool) {
+	s.strict = strict
+}
+
+// Ready reports whether the database can be reached
+func (s *SQLiteStore) Ready() error {
+	return s.db.Ping()
+}
+
+// Close closes the underlying database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// sqlExecer is satisfied by both *sql.DB and *sql.Tx
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// inTx runs fn inside a transaction, committing if it returns nil
+func (s *SQLiteStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// formatTime formats a timestamp for storage in a TEXT column
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// Task List Methods
+
+// GetAllLists returns all task lists
+func (s *SQLiteStore) GetAllLists() ([]models.TaskList, error) {
+	return s.readAllLists(false)
+}
+
+// GetAllListsWithErrors returns all task lists, including placeholders for
+// rows whose data cannot be parsed
+func (s *SQLiteStore) GetAllListsWithErrors() ([]models.TaskList, error) {
+	return s.readAllLists(true)
+}
+
+// readAllLists reads every list row. Unparseable rows fail the read in strict
+// mode; otherwise they are logged and either skipped or, when includeErrors
+// is set, returned as placeholders.
+func (s *SQLiteStore) readAllLists(includeErrors bool) ([]models.TaskList, error) {
+	rows, err := s.db.Query(`SELECT id, data FROM lists`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lists: %w", err)
+	}
+	defer rows.Close()
+
+	var lists []models.TaskList
+	for rows.Next() {
+		var id, data string
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, fmt.Errorf("failed to read list: %w", err)
+		}
+
+		var list models.TaskList
+		if err := json.Unmarshal([]byte(data), &list); err != nil {
+			if s.strict {
+				return nil, corruptData("list "+id, err)
+			}
+			log.Printf("Warning: skipping unreadable list %s: %v", id, err)
+			if includeErrors {
+				lists = append(lists, models.TaskList{ID: id, Name: "(unreadable)", Error: err.Error()})
+			}
+			continue
+		}
+		lists = append(lists, list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query lists: %w", err)
+	}
+
+	models.SortLists(lists)
+
+	return lists, nil
+}
+
+// GetList returns a single task list by ID
+func (s *SQLiteStore) GetList(id string) (*models.TaskList, error) {
+	return getList(s.db, id)
+}
+
+// getList reads a list using the given connection or transaction
+func getList(q sqlExecer, id string) (*models.TaskList, error) {
+	var data string
+	err := q.QueryRow(`SELECT data FROM lists WHERE id = ?`, id).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrListNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to read list: %w", err)
+	}
+
+	var list models.TaskList
+	if err := json.Unmarshal([]byte(data), &list); err != nil {
+		return nil, corruptData("list", err)
+	}
+
+	return &list, nil
+}
+
+// putList inserts or replaces a list row
+func putList(q sqlExecer, list *models.TaskList) error {
+	data, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to serialize list: %w", err)
+	}
+
+	_, err = q.Exec(`
+		INSERT INTO lists (id, name, created_at, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET name = excluded.name, created_at = excluded.created_at, data = excluded.data`,
+		list.ID, list.Name, formatTime(list.CreatedAt), string(data))
+	if err != nil {
+		return fmt.Errorf("failed to write list: %w", err)
+	}
+
+	return nil
+}
+
+// listExists reports whether a list row exists
+func listExists(q sqlExecer, id string) (bool, error) {
+	var n int
+	if err := q.QueryRow(`SELECT COUNT(*) FROM lists WHERE id = ?`, id).Scan(&n); err != nil {
+		return false, fmt.Errorf("failed to read list: %w", err)
+	}
+	return n > 0, nil
+}
+
+// CreateList creates a new task list, failing with ErrListExists if the ID
+// is taken
+func (s *SQLiteStore) CreateList(list *models.TaskList) error {
+	if err := ValidateID(list.ID); err != nil {
+		return err
+	}
+	return s.inTx(func(tx *sql.Tx) error {
+		exists, err := listExists(tx, list.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("%w: %s", ErrListExists, list.ID)
+		}
+
+		now := time.Now()
+		list.CreatedAt = now
+		list.UpdatedAt = now
+		return putList(tx, list)
+	})
+}
+
+// UpdateList updates an existing task list
+func (s *SQLiteStore) UpdateList(list *models.TaskList) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		exists, err := listExists(tx, list.ID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrListNotFound, list.ID)
+		}
+
+		list.UpdatedAt = time.Now()
+		return putList(tx, list)
+	})
+}
+
+// ArchiveList marks a task list as archived. Archiving an already archived
+// list is a no-op.
+func (s *SQLiteStore) ArchiveList(id string) (*models.TaskList, error) {
+	var list *models.TaskList
+	err := s.inTx(func(tx *sql.Tx) error {
+		var err error
+		list, err = getList(tx, id)
+		if err != nil || list.Archived {
+			return err
+		}
+
+		now := time.Now()
+		list.Archived = true
+		list.ArchivedAt = &now
+		list.UpdatedAt = now
+		return putList(tx, list)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// DeleteList deletes a task list and all its tasks
+func (s *SQLiteStore) DeleteList(id string) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		list, err := getList(tx, id)
+		if err != nil {
+			return err
+		}
+
+		// Snapshot the tasks so the deletion can be undone
+		tasks, err := queryTasks(tx, s.strict, `WHERE t.list_id = ?`, id)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`DELETE FROM lists WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete list: %w", err)
+		}
+
+		return recordSQLiteUndo(tx, UndoEntry{Kind: UndoDeleteList, List: list, Tasks: tasks})
+	})
+}
+
+// Task Methods
+
+// queryTasks returns the tasks matching a WHERE clause over the tasks table
+// (aliased t), along with their notes, using a single query. Tasks whose
+// data can't be parsed fail the query when strict is set and are otherwise
+// logged and skipped.
+func queryTasks(q sqlExecer, strict bool, where string, args ...interface{}) ([]models.Task, error) {
+	rows, err := q.Query(`
+		SELECT t.id, t.data, n.id, n.content, n.created_at, n.updated_at
+		FROM tasks t
+		LEFT JOIN notes n ON n.task_id = t.id
+		`+where+`
+		ORDER BY t.list_id, t.created_at, t.id, n.position`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		var (
+			taskID, data                                      string
+			noteID, noteContent, noteCreatedAt, noteUpdatedAt sql.NullString
+		)
+		if err := rows.Scan(&taskID, &data, &noteID, &noteContent, &noteCreatedAt, &noteUpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to read task: %w", err)
+		}
+
+		// Rows for the same task are adjacent; start a new task on a new ID
+		if len(tasks) == 0 || tasks[len(tasks)-1].ID != taskID {
+			var task models.Task
+			if err := json.Unmarshal([]byte(data), &task); err != nil {
+				if strict {
+					return nil, corruptData("task "+taskID, err)
+				}
+				log.Printf("Warning: skipping unreadable task %s: %v", taskID, err)
+				continue
+			}
+			task.Notes = nil
+			tasks = append(tasks, task)
+		}
+
+		if noteContent.Valid {
+			note := models.Note{ID: noteID.String, Content: noteContent.String}
+			note.CreatedAt, _ = time.Parse(time.RFC3339Nano, noteCreatedAt.String)
+			note.UpdatedAt, _ = time.Parse(time.RFC3339Nano, noteUpdatedAt.String)
+			last := &tasks[len(tasks)-1]
+			if last.ID == taskID {
+				last.Notes = append(last.Notes, note)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// getTask reads a single task by ID
+func getTask(q sqlExecer, taskID string) (*models.Task, error) {
+	tasks, err := queryTasks(q, true, `WHERE t.id = ?`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+	return &tasks[0], nil
+}
+
+// taskExists reports whether a task with the ID exists in any list
+func taskExists(q sqlExecer, id string) (bool, error) {
+	var n int
+	if err := q.QueryRow(`SELECT COUNT(*) FROM tasks WHERE id = ?`, id).Scan(&n); err != nil {
+		return false, fmt.Errorf("failed to read task: %w", err)
+	}
+	return n > 0, nil
+}
+
+// putTask inserts or replaces a task row and its notes
+func putTask(q sqlExecer, task *models.Task) error {
+	stored := *task
+	stored.Notes = nil
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("failed to serialize task: %w", err)
+	}
+
+	var dueDate interface{}
+	if task.DueDate != nil {
+		dueDate = formatTime(*task.DueDate)
+	}
+
+	_, err = q.Exec(`
+		INSERT INTO tasks (id, list_id, title, state, due_date, created_at, data) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET list_id = excluded.list_id, title = excluded.title, state = excluded.state,
+			due_date = excluded.due_date, created_at = excluded.created_at, data = excluded.data`,
+		task.ID, task.ListID, task.Title, string(task.State), dueDate, formatTime(task.CreatedAt), string(data))
+	if err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+
+	if _, err := q.Exec(`DELETE FROM notes WHERE task_id = ?`, task.ID); err != nil {
+		return fmt.Errorf("failed to write notes: %w", err)
+	}
+	for i, note := range task.Notes {
+		_, err := q.Exec(`INSERT INTO notes (task_id, position, id, content, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			task.ID, i, note.ID, note.Content, formatTime(note.CreatedAt), formatTime(note.UpdatedAt))
+		if err != nil {
+			return fmt.Errorf("failed to write notes: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetAllTasks returns all tasks across all lists
+func (s *SQLiteStore) GetAllTasks() ([]models.Task, error) {
+	return queryTasks(s.db, s.strict, "")
+}
+
+// GetTasksPage returns one page of the tasks GetAllTasks would return, in the
+// same order, along with the total number of tasks
+func (s *SQLiteStore) GetTasksPage(offset, limit int) ([]models.Task, int, error) {
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM tasks`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	tasks, err := queryTasks(s.db, s.strict, `WHERE t.id IN (SELECT id FROM tasks ORDER BY list_id, created_at, id LIMIT ? OFFSET ?)`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if tasks == nil {
+		tasks = []models.Task{}
+	}
+
+	return tasks, total, nil
+}
+
+// GetTasksByList returns all tasks for a specific list
+func (s *SQLiteStore) GetTasksByList(listID string) ([]models.Task, error) {
+	return s.GetTasksForList(listID)
+}
+
+// GetTasksForList gets tasks for a list
+func (s *SQLiteStore) GetTasksForList(listID string) ([]models.Task, error) {
+	exists, err := listExists(s.db, listID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrListNotFound, listID)
+	}
+
+	return queryTasks(s.db, s.strict, `WHERE t.list_id = ?`, listID)
+}
+
+// GetTask returns a single task by ID. Task IDs are unique across lists, so
+// a task that has moved is still found.
+func (s *SQLiteStore) GetTask(listID, taskID string) (*models.Task, error) {
+	return getTask(s.db, taskID)
+}
+
+// FindTask locates a task by ID without knowing which list it belongs to
+func (s *SQLiteStore) FindTask(taskID string) (*models.Task, error) {
+	if err := ValidateID(taskID); err != nil {
+		return nil, err
+	}
+	return getTask(s.db, taskID)
+}
+
+// CreateTask creates a new task
+func (s *SQLiteStore) CreateTask(task *models.Task) error {
+	if err := validateIDs(task.ListID, task.ID); err != nil {
+		return err
+	}
+	return s.inTx(func(tx *sql.Tx) error {
+		exists, err := listExists(tx, task.ListID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrListNotFound, task.ListID)
+		}
+		if err := s.checkTaskLimits(tx, task.ListID, 1); err != nil {
+			return err
+		}
+
+		// putTask would otherwise overwrite the existing task, even one in
+		// another list
+		exists, err = taskExists(tx, task.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("%w: %s", ErrTaskExists, task.ID)
+		}
+
+		now := time.Now()
+		task.CreatedAt = now
+		task.UpdatedAt = now
+		task.Version = 1
+		if task.StateTime.IsZero() {
+			task.StateTime = now
+		}
+		task.RecordCompletion(nil, now)
+
+		if err := putTask(tx, task); err != nil {
+			return err
+		}
+		return recordSQLiteUndo(tx, UndoEntry{Kind: UndoCreateTask, Tasks: []models.Task{task.Clone()}, Version: task.Version})
+	})
+}
+
+// UpdateTask updates an existing task
+func (s *SQLiteStore) UpdateTask(task *models.Task) error {
+	if err := validateIDs(task.ListID, task.ID); err != nil {
+		return err
+	}
+	return s.inTx(func(tx *sql.Tx) error {
+		exists, err := listExists(tx, task.ListID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrListNotFound, task.ListID)
+		}
+
+		now := time.Now()
+		task.UpdatedAt = now
+
+		// Reject stale writes and record what changed since the stored copy
+		previous, err := getTask(tx, task.ID)
+		if err == nil {
+			if task.Version != 0 && task.Version != previous.Version {
+				return fmt.Errorf("%w: version %d is stale, current is %d", ErrVersionConflict, task.Version, previous.Version)
+			}
+			task.RecordChanges(previous, now)
+			task.RecordStateTime(previous, now)
+			task.RecordCompletion(previous, now)
+			task.RecordReminder(previous)
+			task.Attachments = previous.Attachments // Managed by AddAttachment and DeleteAttachment
+			task.Version = previous.Version
+		} else {
+			previous = nil
+		}
+		task.Version++
+		if err := putTask(tx, task); err != nil {
+			return err
+		}
+		return recordSQLiteUndo(tx, updateUndoEntry(previous, task))
+	})
+}
+
+// MoveTask moves a task from one list to another
+func (s *SQLiteStore) MoveTask(originalListID, taskID, newListID string, opts MoveOptions) (*models.Task, error) {
+	if err := validateIDs(originalListID, taskID, newListID); err != nil {
+		return nil, err
+	}
+	if originalListID == newListID {
+		return nil, fmt.Errorf("task is already in list: %s", newListID)
+	}
+
+	var task *models.Task
+	err := s.inTx(func(tx *sql.Tx) error {
+		var err error
+		task, err = getTask(tx, taskID)
+		if err != nil || task.ListID != originalListID {
+			return fmt.Errorf("%w: %s/%s", ErrTaskNotFound, originalListID, taskID)
+		}
+
+		newList, err := getList(tx, newListID)
+		if err != nil {
+			return fmt.Errorf("destination %w: %s", ErrListNotFound, newListID)
+		}
+		if err := s.checkMoveLimits(tx, newListID); err != nil {
+			return err
+		}
+
+		before := task.Clone()
+		if err := applyMove(task, newList, opts); err != nil {
+			return err
+		}
+		if err := putTask(tx, task); err != nil {
+			return err
+		}
+		if err := recordSQLiteUndo(tx, UndoEntry{Kind: UndoMoveTask, Tasks: []models.Task{before}, Version: task.Version}); err != nil {
+			return err
+		}
+		return moveAttachmentDir(s.filesDir, taskID, originalListID, newListID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// DeleteTask deletes a task
+func (s *SQLiteStore) DeleteTask(listID, taskID string) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		task, err := getTask(tx, taskID)
+		if err != nil {
+			return err
+		}
+
+		if !s.hardDelete {
+			if err := trashSQLiteTask(tx, *task); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(`DELETE FROM tasks WHERE id = ?`, taskID); err != nil {
+			return fmt.Errorf("failed to delete task: %w", err)
+		}
+
+		return recordSQLiteUndo(tx, UndoEntry{Kind: UndoDeleteTask, Tasks: []models.Task{*task}})
+	})
+}
+
+// Trash Methods
+
+// trashSQLiteTask keeps a copy of a deleted task, notes included, in the trash
+func trashSQLiteTask(tx *sql.Tx, task models.Task) error {
+	now := time.Now()
+	task.DeletedAt = &now
+
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to serialize task: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO trash (id, list_id, deleted_at, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET list_id = excluded.list_id, deleted_at = excluded.deleted_at, data = excluded.data`,
+		task.ID, task.ListID, formatTime(now), string(data))
+	if err != nil {
+		return fmt.Errorf("failed to write trash: %w", err)
+	}
+
+	return nil
+}
+
+// GetTrash returns all deleted tasks, most recently deleted first
+func (s *SQLiteStore) GetTrash() ([]models.Task, error) {
+	rows, err := s.db.Query(`SELECT data FROM trash ORDER BY deleted_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]models.Task, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read trash: %w", err)
+		}
+
+		var task models.Task
+		if err := json.Unmarshal([]byte(data), &task); err != nil {
+			return nil, corruptData("task", err)
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// RestoreTask moves a deleted task out of the trash and back into its list
+func (s *SQLiteStore) RestoreTask(taskID string) (*models.Task, error) {
+	if err := ValidateID(taskID); err != nil {
+		return nil, err
+	}
+	var task models.Task
+	err := s.inTx(func(tx *sql.Tx) error {
+		var data string
+		err := tx.QueryRow(`SELECT data FROM trash WHERE id = ?`, taskID).Scan(&data)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotInTrash
+			}
+			return fmt.Errorf("failed to read trash: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(data), &task); err != nil {
+			return corruptData("task", err)
+		}
+
+		if _, err := getTask(tx, task.ID); err == nil {
+			return fmt.Errorf("task already exists: %s", task.ID)
+		}
+		if err := s.checkRestoreLimits(tx, []models.Task{task}); err != nil {
+			return err
+		}
+
+		task.DeletedAt = nil
+		task.UpdatedAt = time.Now()
+		if err := putTask(tx, &task); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`DELETE FROM trash WHERE id = ?`, taskID); err != nil {
+			return fmt.Errorf("failed to write trash: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// Saved Filter Methods
+
+// GetSavedFilters returns all saved filters sorted by name
+func (s *SQLiteStore) GetSavedFilters() ([]models.SavedFilter, error) {
+	rows, err := s.db.Query(`SELECT data FROM saved_filters ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read filters: %w", err)
+	}
+	defer rows.Close()
+
+	filters := make([]models.SavedFilter, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read filters: %w", err)
+		}
+
+		var filter models.SavedFilter
+		if err := json.Unmarshal([]byte(data), &filter); err != nil {
+			return nil, fmt.Errorf("failed to parse filters: %w", err)
+		}
+		filters = append(filters, filter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read filters: %w", err)
+	}
+
+	return filters, nil
+}
+
+// GetSavedFilter returns a saved filter by name
+func (s *SQLiteStore) GetSavedFilter(name string) (*models.SavedFilter, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM saved_filters WHERE name = ?`, name).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("filter not found: %s", name)
+		}
+		return nil, fmt.Errorf("failed to read filters: %w", err)
+	}
+
+	var filter models.SavedFilter
+	if err := json.Unmarshal([]byte(data), &filter); err != nil {
+		return nil, fmt.Errorf("failed to parse filters: %w", err)
+	}
+
+	return &filter, nil
+}
+
+// SaveFilter creates or replaces a saved filter
+func (s *SQLiteStore) SaveFilter(filter *models.SavedFilter) error {
+	existing, err := s.GetSavedFilter(filter.Name)
+
+	now := time.Now()
+	if err == nil {
+		filter.CreatedAt = existing.CreatedAt
+	} else {
+		filter.CreatedAt = now
+	}
+	filter.UpdatedAt = now
+
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return fmt.Errorf("failed to serialize filters: %w", err)
+	}
+
+	_, err = s.db.Exec(`INSERT INTO saved_filters (name, data) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET data = excluded.data`, filter.Name, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to write filters: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSavedFilter deletes a saved filter by name
+func (s *SQLiteStore) DeleteSavedFilter(name string) error {
+	result, err := s.db.Exec(`DELETE FROM saved_filters WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("failed to write filters: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("filter not found: %s", name)
+	}
+
+	return nil
+}
+
+// Undo Methods
+
+// recordSQLiteUndo appends an entry to the undo journal, dropping the oldest
+// entries beyond maxUndoEntries
+func recordSQLiteUndo(tx *sql.Tx, entry UndoEntry) error {
+	entry.ID = uuid.New().String()
+	entry.Timestamp = time.Now()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to serialize undo entry: %w", err)
+	}
+
+	if _, err := tx.Exec(`INSERT INTO undo_entries (data) VALUES (?)`, string(data)); err != nil {
+		return fmt.Errorf("failed to record undo entry: %w", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM undo_entries WHERE seq NOT IN (SELECT seq FROM undo_entries ORDER BY seq DESC LIMIT ?)`, maxUndoEntries)
+	if err != nil {
+		return fmt.Errorf("failed to trim undo journal: %w", err)
+	}
+
+	return nil
+}
+
+// UndoHistory returns the recorded operations, most recent first
+func (s *SQLiteStore) UndoHistory() ([]UndoEntry, error) {
+	rows, err := s.db.Query(`SELECT data FROM undo_entries ORDER BY seq DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read undo journal: %w", err)
+	}
+	defer rows.Close()
+
+	history := make([]UndoEntry, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read undo journal: %w", err)
+		}
+
+		var entry UndoEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse undo journal: %w", err)
+		}
+		history = append(history, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read undo journal: %w", err)
+	}
+
+	return history, nil
+}
+
+// Undo reverts the most recently recorded operation and removes it from the
+// journal. If the operation cannot be reverted the entry is kept.
+func (s *SQLiteStore) Undo() (*UndoEntry, error) {
+	var entry UndoEntry
+	err := s.inTx(func(tx *sql.Tx) error {
+		var seq int64
+		var data string
+		err := tx.QueryRow(`SELECT seq, data FROM undo_entries ORDER BY seq DESC LIMIT 1`).Scan(&seq, &data)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNothingToUndo
+			}
+			return fmt.Errorf("failed to read undo journal: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return fmt.Errorf("failed to parse undo journal: %w", err)
+		}
+
+		switch entry.Kind {
+		case UndoDeleteTask:
+			err = s.restoreTasks(tx, entry.Tasks)
+		case UndoDeleteList:
+			err = s.restoreList(tx, entry.List, entry.Tasks)
+		case UndoCreateTask:
+			err = s.removeCreatedTask(tx, entry)
+		case UndoUpdateTask, UndoMoveTask:
+			var reverted *models.Task
+			if reverted, err = s.revertToSnapshot(tx, entry); err == nil {
+				err = rebaseSQLiteUndo(tx, seq, entry.Tasks[0].Version, reverted)
+			}
+		case UndoArchiveTasks:
+			err = s.unarchiveTasks(tx, entry)
+		default:
+			err = fmt.Errorf("unsupported undo operation: %s", entry.Kind)
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`DELETE FROM undo_entries WHERE seq = ?`, seq); err != nil {
+			return fmt.Errorf("failed to write undo journal: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// restoreTasks writes tasks back into their lists, refusing to overwrite
+// tasks that have since been recreated
+func (s *SQLiteStore) restoreTasks(tx *sql.Tx, tasks []models.Task) error {
+	for _, task := range tasks {
+		exists, err := listExists(tx, task.ListID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("list no longer exists: %s", task.ListID)
+		}
+		if _, err := getTask(tx, task.ID); err == nil {
+			return fmt.Errorf("task already exists: %s", task.ID)
+		}
+	}
+	if err := s.checkRestoreLimits(tx, tasks); err != nil {
+		return err
+	}
+
+	for i := range tasks {
+		if err := putTask(tx, &tasks[i]); err != nil {
+			return err
+		}
+
+		// A soft-deleted task is back, so it no longer belongs in the trash
+		if _, err := tx.Exec(`DELETE FROM trash WHERE id = ?`, tasks[i].ID); err != nil {
+			return fmt.Errorf("failed to write trash: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// restoreList recreates a deleted list along with its tasks
+func (s *SQLiteStore) restoreList(tx *sql.Tx, list *models.TaskList, tasks []models.Task) error {
+	if list == nil {
+		return fmt.Errorf("undo entry has no list")
+	}
+
+	exists, err := listExists(tx, list.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("list already exists: %s", list.ID)
+	}
+
+	if err := putList(tx, list); err != nil {
+		return err
+	}
+
+	return s.restoreTasks(tx, tasks)
+}
+
+// removeCreatedTask deletes the task a create entry recorded, along with any
+// attachments, without moving it to the trash
+func (s *SQLiteStore) removeCreatedTask(tx *sql.Tx, entry UndoEntry) error {
+	if len(entry.Tasks) != 1 {
+		return fmt.Errorf("undo entry has no task")
+	}
+	current, err := getTask(tx, entry.Tasks[0].ID)
+	if err != nil {
+		return fmt.Errorf("task no longer exists: %s", entry.Tasks[0].ID)
+	}
+	if _, err := undoneTask(entry, current); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM tasks WHERE id = ?`, current.ID); err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	if err := os.RemoveAll(attachmentDir(s.filesDir, current.ListID, current.ID)); err != nil {
+		return fmt.Errorf("failed to delete attachments: %w", err)
+	}
+	return nil
+}
+
+// revertToSnapshot puts a task back the way an update or move entry found
+// it, moving it back to its earlier list if need be, and returns the task
+func (s *SQLiteStore) revertToSnapshot(tx *sql.Tx, entry UndoEntry) (*models.Task, error) {
+	if len(entry.Tasks) != 1 {
+		return nil, fmt.Errorf("undo entry has no task")
+	}
+	current, err := getTask(tx, entry.Tasks[0].ID)
+	if err != nil {
+		return nil, fmt.Errorf("task no longer exists: %s", entry.Tasks[0].ID)
+	}
+	task, err := undoneTask(entry, current)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := listExists(tx, task.ListID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("list no longer exists: %s", task.ListID)
+	}
+	if task.ListID != current.ListID {
+		if err := s.checkMoveLimits(tx, task.ListID); err != nil {
+			return nil, err
+		}
+	}
+
+	revertTask(&task, current, time.Now())
+	if err := putTask(tx, &task); err != nil {
+		return nil, err
+	}
+	if task.ListID != current.ListID {
+		if err := moveAttachmentDir(s.filesDir, task.ID, current.ListID, task.ListID); err != nil {
+			return nil, err
+		}
+	}
+	return &task, nil
+}
+
+// rebaseSQLiteUndo applies rebaseUndoEntries to the entries recorded before
+// seq
+func rebaseSQLiteUndo(tx *sql.Tx, seq int64, from int, reverted *models.Task) error {
+	rows, err := tx.Query(`SELECT seq, data FROM undo_entries WHERE seq < ?`, seq)
+	if err != nil {
+		return fmt.Errorf("failed to read undo journal: %w", err)
+	}
+
+	updated := make(map[int64]UndoEntry)
+	for rows.Next() {
+		var entrySeq int64
+		var data string
+		if err := rows.Scan(&entrySeq, &data); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read undo journal: %w", err)
+		}
+		var entry UndoEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to parse undo journal: %w", err)
+		}
+		entries := []UndoEntry{entry}
+		if rebaseUndoEntries(entries, from, reverted) {
+			updated[entrySeq] = entries[0]
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read undo journal: %w", err)
+	}
+
+	for entrySeq, entry := range updated {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to serialize undo entry: %w", err)
+		}
+		if _, err := tx.Exec(`UPDATE undo_entries SET data = ? WHERE seq = ?`, string(data), entrySeq); err != nil {
+			return fmt.Errorf("failed to write undo journal: %w", err)
+		}
+	}
+	return nil
+}
+
+// Attachment operations
+
+// getTaskInList reads a task, checking that it belongs to the list
+func getTaskInList(q sqlExecer, listID, taskID string) (*models.Task, error) {
+	task, err := getTask(q, taskID)
+	if err != nil || task.ListID != listID {
+		return nil, fmt.Errorf("%w: %s/%s", ErrTaskNotFound, listID, taskID)
+	}
+	return task, nil
+}
+
+// AddAttachment stores the content of a new attachment and records it on the
+// task. The attachment's Size is set from the bytes written.
+func (s *SQLiteStore) AddAttachment(listID, taskID string, attachment *models.Attachment, content io.Reader) error {
+	if err := validateIDs(listID, taskID); err != nil {
+		return err
+	}
+	dir := attachmentDir(s.filesDir, listID, taskID)
+	written := false
+
+	err := s.inTx(func(tx *sql.Tx) error {
+		task, err := getTaskInList(tx, listID, taskID)
+		if err != nil {
+			return err
+		}
+
+		size, err := writeAttachmentFile(dir, attachment.ID, content)
+		if err != nil {
+			return err
+		}
+		written = true
+		attachment.Size = size
+
+		task.Attachments = append(task.Attachments, *attachment)
+		task.UpdatedAt = time.Now()
+		task.Version++
+		return putTask(tx, task)
+	})
+	if err != nil && written {
+		os.Remove(filepath.Join(dir, attachment.ID))
+	}
+	return err
+}
+
+// OpenAttachment returns an attachment's metadata and its content, which the
+// caller must close
+func (s *SQLiteStore) OpenAttachment(listID, taskID, attachmentID string) (*models.Attachment, *os.File, error) {
+	task, err := getTaskInList(s.db, listID, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return openAttachmentFile(s.filesDir, task, attachmentID)
+}
+
+// DeleteAttachment removes an attachment from a task along with its content
+func (s *SQLiteStore) DeleteAttachment(listID, taskID, attachmentID string) error {
+	err := s.inTx(func(tx *sql.Tx) error {
+		task, err := getTaskInList(tx, listID, taskID)
+		if err != nil {
+			return err
+		}
+		if err := removeAttachment(task, attachmentID); err != nil {
+			return err
+		}
+
+		task.UpdatedAt = time.Now()
+		task.Version++
+		return putTask(tx, task)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filepath.Join(attachmentDir(s.filesDir, listID, taskID), attachmentID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// positionTask moves the task with taskID to index among tasks, which must be
+// sorted by position, and renumbers Position 1..n in the new order. An index
+// past the end places the task last. It returns the moved task and every task
+// whose position changed, with their UpdatedAt and Version advanced, or an
+// error if the task isn't among tasks.
+func positionTask(tasks []models.Task, taskID string, index int) (*models.Task, []*models.Task, error) {
+	var moved *models.Task
+	others := make([]*models.Task, 0, len(tasks))
+	for i := range tasks {
+		if tasks[i].ID == taskID {
+			moved = &tasks[i]
+			continue
+		}
+		others = append(others, &tasks[i])
+	}
+	if moved == nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+
+	index = max(0, min(index, len(others)))
+	ordered := append(others[:index:index], moved)
+	ordered = append(ordered, others[index:]...)
+
+	now := time.Now()
+	previous := moved.Clone()
+	var changed []*models.Task
+	for i, task := range ordered {
+		if task.Position == i+1 {
+			continue
+		}
+		task.Position = i + 1
+		task.UpdatedAt = now
+		task.Version++
+		changed = append(changed, task)
+	}
+	moved.RecordChanges(&previous, now)
+	return moved, changed, nil
+}
+
+// PositionTask moves a task to index (0-based) in its list's position order,
+// shifting the tasks around it, and returns the moved task
+func (fs *FileStore) PositionTask(listID, taskID string, index int) (*models.Task, error) {
+	if err := validateIDs(listID, taskID); err != nil {
+		return nil, err
+	}
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	tasks, err := fs.readTasksForList(listID)
+	if err != nil {
+		return nil, err
+	}
+	models.SortTasksByPosition(tasks)
+
+	moved, changed, err := positionTask(tasks, taskID, index)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range changed {
+		data, err := fs.marshal(task)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize task: %w", err)
+		}
+		taskPath := filepath.Join(fs.baseDir, "lists", listID, "tasks", task.ID+".json")
+		if err := fs.writeFile(taskPath, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write task file: %w", err)
+		}
+		fs.index.put(task)
+	}
+
+	return moved, nil
+}
+
+// PositionTask moves a task to index (0-based) in its list's position order,
+// shifting the tasks around it, and returns the moved task
+func (s *SQLiteStore) PositionTask(listID, taskID string, index int) (*models.Task, error) {
+	if err := validateIDs(listID, taskID); err != nil {
+		return nil, err
+	}
+
+	var moved *models.Task
+	err := s.inTx(func(tx *sql.Tx) error {
+		exists, err := listExists(tx, listID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrListNotFound, listID)
+		}
+
+		tasks, err := queryTasks(tx, true, `WHERE t.list_id = ?`, listID)
+		if err != nil {
+			return err
+		}
+		models.SortTasksByPosition(tasks)
+
+		var changed []*models.Task
+		moved, changed, err = positionTask(tasks, taskID, index)
+		if err != nil {
+			return err
+		}
+		for _, task := range changed {
+			if err := putTask(tx, task); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// ErrNotInTrash is returned by RestoreTask when no deleted task has the ID
+var ErrNotInTrash = errors.New("task not in trash")
+
+// SetHardDelete makes DeleteTask remove task files permanently instead of
+// moving them into the list's trash directory
+func (fs *FileStore) SetHardDelete(hard bool) {
+	fs.hardDelete = hard
+}
+
+// trashTaskPath returns the path a deleted task is kept at
+func (fs *FileStore) trashTaskPath(listID, taskID string) string {
+	return filepath.Join(fs.baseDir, "lists", listID, "trash", taskID+".json")
+}
+
+// trashTask writes a copy of a deleted task into its list's trash directory.
+// Must be called with the write lock held.
+func (fs *FileStore) trashTask(task models.Task) error {
+	now := time.Now()
+	task.DeletedAt = &now
+
+	trashPath := fs.trashTaskPath(task.ListID, task.ID)
+	if err := os.MkdirAll(filepath.Dir(trashPath), 0755); err != nil {
+		return fmt.Errorf("failed to create trash directory: %w", err)
+	}
+
+	data, err := fs.marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to serialize task: %w", err)
+	}
+
+	if err := fs.writeFile(trashPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write trash file: %w", err)
+	}
+
+	return nil
+}
+
+// GetTrash returns all deleted tasks, most recently deleted first
+func (fs *FileStore) GetTrash() ([]models.Task, error) {
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+
+	trashFiles, err := filepath.Glob(filepath.Join(fs.baseDir, "lists", "*", "trash", "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	tasks := make([]models.Task, 0, len(trashFiles))
+	for _, trashPath := range trashFiles {
+		var task models.Task
+		data, err := os.ReadFile(trashPath)
+		if err != nil {
+			err = fmt.Errorf("failed to read deleted task %s: %w", trashPath, err)
+		} else if err = json.Unmarshal(data, &task); err != nil {
+			err = corruptData("deleted task "+trashPath, err)
+		}
+		if err != nil {
+			if fs.strict {
+				return nil, err
+			}
+			log.Printf("Warning: skipping unreadable deleted task: %v", err)
+			continue
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].DeletedAt == nil {
+			return false
+		}
+		if tasks[j].DeletedAt == nil {
+			return true
+		}
+		return tasks[i].DeletedAt.After(*tasks[j].DeletedAt)
+	})
+
+	return tasks, nil
+}
+
+// findTrashFile returns the path of a deleted task's file in whichever list's
+// trash holds it, or ErrNotInTrash
+func (fs *FileStore) findTrashFile(taskID string) (string, error) {
+	entries, err := os.ReadDir(filepath.Join(fs.baseDir, "lists"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read lists directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		trashPath := filepath.Join(fs.baseDir, "lists", entry.Name(), "trash", taskID+".json")
+		if _, err := os.Stat(trashPath); err == nil {
+			return trashPath, nil
+		}
+	}
+	return "", ErrNotInTrash
+}
+
+// RestoreTask moves a deleted task out of the trash and back into its list
+func (fs *FileStore) RestoreTask(taskID string) (*models.Task, error) {
+	if err := ValidateID(taskID); err != nil {
+		return nil, err
+	}
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	trashPath, err := fs.findTrashFile(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(trashPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash file: %w", err)
+	}
+
+	var task models.Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		return nil, corruptData("task", err)
+	}
+
+	taskPath := filepath.Join(fs.baseDir, "lists", task.ListID, "tasks", task.ID+".json")
+	if _, err := os.Stat(taskPath); err == nil {
+		return nil, fmt.Errorf("task already exists: %s", task.ID)
+	}
+	if err := fs.checkRestoreLimits([]models.Task{task}); err != nil {
+		return nil, err
+	}
+
+	task.DeletedAt = nil
+	task.UpdatedAt = time.Now()
+
+	data, err = fs.marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize task: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(taskPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create tasks directory: %w", err)
+	}
+
+	if err := fs.writeFile(taskPath, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write task file: %w", err)
+	}
+	fs.index.put(&task)
+
+	if err := os.Remove(trashPath); err != nil {
+		return nil, fmt.Errorf("failed to remove trash file: %w", err)
+	}
+
+	return &task, nil
+}
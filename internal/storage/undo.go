@@ -0,0 +1,371 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jbutlerdev/tasks/internal/models"
+)
+
+// maxUndoEntries bounds the number of operations kept in the undo journal
+const maxUndoEntries = 20
+
+// ErrNothingToUndo is returned by Undo when the journal is empty
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// UndoKind identifies the kind of operation recorded in the undo journal
+type UndoKind string
+
+const (
+	UndoDeleteTask UndoKind = "delete_task"
+	UndoDeleteList UndoKind = "delete_list"
+	UndoCreateTask UndoKind = "create_task"
+	UndoUpdateTask UndoKind = "update_task"
+	UndoMoveTask   UndoKind = "move_task"
+
+	UndoArchiveTasks UndoKind = "archive_tasks"
+)
+
+// UndoEntry records enough state to reverse an operation. For deletions
+// Tasks holds what was deleted; for a created task it holds the new task, and
+// for an update, move or archive the tasks as they were before.
+type UndoEntry struct {
+	ID        string           `json:"id"`
+	Kind      UndoKind         `json:"kind"`
+	Timestamp time.Time        `json:"timestamp"`
+	List      *models.TaskList `json:"list,omitempty"`
+	Tasks     []models.Task    `json:"tasks,omitempty"`
+	Version   int              `json:"version,omitempty"` // Task version the create, update or move left; undoing is refused once the task has changed again
+}
+
+// updateUndoEntry returns the entry for saving task over previous, which is
+// nil when the save created the task
+func updateUndoEntry(previous, task *models.Task) UndoEntry {
+	if previous == nil {
+		return UndoEntry{Kind: UndoCreateTask, Tasks: []models.Task{task.Clone()}, Version: task.Version}
+	}
+	return UndoEntry{Kind: UndoUpdateTask, Tasks: []models.Task{*previous}, Version: task.Version}
+}
+
+// undoneTask returns the task an update, move or create entry recorded,
+// checking that current, the task as stored now, hasn't changed since. A
+// change the journal didn't record, such as a new attachment or position,
+// makes the entry impossible to undo.
+func undoneTask(entry UndoEntry, current *models.Task) (models.Task, error) {
+	if len(entry.Tasks) != 1 {
+		return models.Task{}, fmt.Errorf("undo entry has no task")
+	}
+	if current.Version != entry.Version {
+		return models.Task{}, fmt.Errorf("task %s has changed since (version %d, expected %d)", current.ID, current.Version, entry.Version)
+	}
+	return entry.Tasks[0], nil
+}
+
+// revertTask turns snapshot, a task as it was before an update or move, into
+// the task to store in place of current. The reverted fields are recorded in
+// the history, time in the current state is kept, and the version moves
+// forward so clients holding the undone version are still rejected.
+func revertTask(snapshot *models.Task, current *models.Task, now time.Time) {
+	snapshot.RecordChanges(current, now)
+	snapshot.RecordStateTime(current, now)
+	if snapshot.State != current.State {
+		snapshot.StateTime = now
+	}
+	snapshot.RecordReminder(current)
+	snapshot.Attachments = current.Attachments // Managed by AddAttachment and DeleteAttachment
+	snapshot.UpdatedAt = now
+	snapshot.Version = current.Version + 1
+}
+
+// rebaseUndoEntries points the entries for a reverted task that expect the
+// version it was reverted to, from, at its new version instead, so the
+// changes before it can be undone in turn. It reports whether any changed.
+func rebaseUndoEntries(entries []UndoEntry, from int, reverted *models.Task) bool {
+	changed := false
+	for i := range entries {
+		entry := &entries[i]
+		switch entry.Kind {
+		case UndoCreateTask, UndoUpdateTask, UndoMoveTask:
+		default:
+			continue
+		}
+		if len(entry.Tasks) == 1 && entry.Tasks[0].ID == reverted.ID && entry.Version == from {
+			entry.Version = reverted.Version
+			changed = true
+		}
+	}
+	return changed
+}
+
+// undoJournalPath returns the path of the on-disk undo journal
+func (fs *FileStore) undoJournalPath() string {
+	return filepath.Join(fs.baseDir, "undo.json")
+}
+
+// readUndoJournal loads the journal, oldest entry first
+func (fs *FileStore) readUndoJournal() ([]UndoEntry, error) {
+	data, err := os.ReadFile(fs.undoJournalPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read undo journal: %w", err)
+	}
+
+	var entries []UndoEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse undo journal: %w", err)
+	}
+
+	return entries, nil
+}
+
+// writeUndoJournal persists the journal
+func (fs *FileStore) writeUndoJournal(entries []UndoEntry) error {
+	data, err := fs.marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to serialize undo journal: %w", err)
+	}
+
+	if err := fs.writeFile(fs.undoJournalPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write undo journal: %w", err)
+	}
+
+	return nil
+}
+
+// recordUndo appends an entry to the journal, dropping the oldest entries
+// beyond maxUndoEntries. Must be called with the write lock held. Failures
+// are logged rather than returned since the operation itself has succeeded.
+func (fs *FileStore) recordUndo(entry UndoEntry) {
+	entries, err := fs.readUndoJournal()
+	if err != nil {
+		log.Printf("Warning: discarding unreadable undo journal: %v", err)
+		entries = nil
+	}
+
+	entry.ID = uuid.New().String()
+	entry.Timestamp = time.Now()
+	entries = append(entries, entry)
+	if len(entries) > maxUndoEntries {
+		entries = entries[len(entries)-maxUndoEntries:]
+	}
+
+	if err := fs.writeUndoJournal(entries); err != nil {
+		log.Printf("Warning: failed to record undo entry: %v", err)
+	}
+}
+
+// UndoHistory returns the recorded operations, most recent first
+func (fs *FileStore) UndoHistory() ([]UndoEntry, error) {
+	fs.mutex.RLock()
+	defer fs.mutex.RUnlock()
+
+	entries, err := fs.readUndoJournal()
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]UndoEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		history = append(history, entries[i])
+	}
+	return history, nil
+}
+
+// Undo reverts the most recently recorded operation and removes it from the
+// journal. If the operation cannot be reverted the entry is kept.
+func (fs *FileStore) Undo() (*UndoEntry, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	entries, err := fs.readUndoJournal()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrNothingToUndo
+	}
+
+	entry := entries[len(entries)-1]
+	switch entry.Kind {
+	case UndoDeleteTask:
+		err = fs.restoreTasks(entry.Tasks)
+	case UndoDeleteList:
+		err = fs.restoreList(entry.List, entry.Tasks)
+	case UndoCreateTask:
+		err = fs.removeCreatedTask(entry)
+	case UndoUpdateTask, UndoMoveTask:
+		var reverted *models.Task
+		if reverted, err = fs.revertToSnapshot(entry); err == nil {
+			rebaseUndoEntries(entries[:len(entries)-1], entry.Tasks[0].Version, reverted)
+		}
+	case UndoArchiveTasks:
+		err = fs.unarchiveTasks(entry)
+	default:
+		err = fmt.Errorf("unsupported undo operation: %s", entry.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fs.writeUndoJournal(entries[:len(entries)-1]); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// restoreTasks writes tasks back into their lists, refusing to overwrite
+// tasks that have since been recreated
+func (fs *FileStore) restoreTasks(tasks []models.Task) error {
+	for _, task := range tasks {
+		tasksDir := filepath.Join(fs.baseDir, "lists", task.ListID, "tasks")
+		if _, err := os.Stat(filepath.Join(fs.baseDir, "lists", task.ListID)); os.IsNotExist(err) {
+			return fmt.Errorf("list no longer exists: %s", task.ListID)
+		}
+		if _, err := os.Stat(filepath.Join(tasksDir, task.ID+".json")); err == nil {
+			return fmt.Errorf("task already exists: %s", task.ID)
+		}
+	}
+	if err := fs.checkRestoreLimits(tasks); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		tasksDir := filepath.Join(fs.baseDir, "lists", task.ListID, "tasks")
+		if err := os.MkdirAll(tasksDir, 0755); err != nil {
+			return fmt.Errorf("failed to create tasks directory: %w", err)
+		}
+
+		data, err := fs.marshal(task)
+		if err != nil {
+			return fmt.Errorf("failed to serialize task: %w", err)
+		}
+
+		if err := fs.writeFile(filepath.Join(tasksDir, task.ID+".json"), data, 0644); err != nil {
+			return fmt.Errorf("failed to write task file: %w", err)
+		}
+		fs.index.put(&task)
+
+		// A soft-deleted task is back, so it no longer belongs in the trash
+		os.Remove(fs.trashTaskPath(task.ListID, task.ID))
+	}
+
+	return nil
+}
+
+// restoreList recreates a deleted list along with its tasks
+func (fs *FileStore) restoreList(list *models.TaskList, tasks []models.Task) error {
+	if list == nil {
+		return fmt.Errorf("undo entry has no list")
+	}
+
+	listDir := filepath.Join(fs.baseDir, "lists", list.ID)
+	if _, err := os.Stat(listDir); err == nil {
+		return fmt.Errorf("list already exists: %s", list.ID)
+	}
+	if err := fs.checkRestoreLimits(tasks); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(listDir, "tasks"), 0755); err != nil {
+		return fmt.Errorf("failed to create list directory: %w", err)
+	}
+
+	data, err := fs.marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to serialize list: %w", err)
+	}
+
+	if err := fs.writeFile(filepath.Join(listDir, "list.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write list file: %w", err)
+	}
+	fs.index.setList(list.ID, nil)
+
+	return fs.restoreTasks(tasks)
+}
+
+// removeCreatedTask deletes the task a create entry recorded, along with any
+// attachments, without moving it to the trash
+func (fs *FileStore) removeCreatedTask(entry UndoEntry) error {
+	if len(entry.Tasks) != 1 {
+		return fmt.Errorf("undo entry has no task")
+	}
+	current, err := fs.findTask(entry.Tasks[0].ID)
+	if err != nil {
+		return fmt.Errorf("task no longer exists: %s", entry.Tasks[0].ID)
+	}
+	if _, err := undoneTask(entry, current); err != nil {
+		return err
+	}
+
+	taskPath := filepath.Join(fs.baseDir, "lists", current.ListID, "tasks", current.ID+".json")
+	if err := os.Remove(taskPath); err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	fs.index.remove(current.ListID, current.ID)
+
+	if err := os.RemoveAll(attachmentDir(fs.baseDir, current.ListID, current.ID)); err != nil {
+		return fmt.Errorf("failed to delete attachments: %w", err)
+	}
+	return nil
+}
+
+// revertToSnapshot puts a task back the way an update or move entry found
+// it, moving it back to its earlier list if need be, and returns the task
+func (fs *FileStore) revertToSnapshot(entry UndoEntry) (*models.Task, error) {
+	if len(entry.Tasks) != 1 {
+		return nil, fmt.Errorf("undo entry has no task")
+	}
+	current, err := fs.findTask(entry.Tasks[0].ID)
+	if err != nil {
+		return nil, fmt.Errorf("task no longer exists: %s", entry.Tasks[0].ID)
+	}
+	task, err := undoneTask(entry, current)
+	if err != nil {
+		return nil, err
+	}
+
+	listDir := filepath.Join(fs.baseDir, "lists", task.ListID)
+	if _, err := os.Stat(listDir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("list no longer exists: %s", task.ListID)
+	}
+	if task.ListID != current.ListID {
+		if err := fs.checkMoveLimits(task.ListID); err != nil {
+			return nil, err
+		}
+	}
+	tasksDir := filepath.Join(listDir, "tasks")
+	if err := os.MkdirAll(tasksDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create tasks directory: %w", err)
+	}
+
+	revertTask(&task, current, time.Now())
+	data, err := fs.marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize task: %w", err)
+	}
+	if err := fs.writeFile(filepath.Join(tasksDir, task.ID+".json"), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write task file: %w", err)
+	}
+	fs.index.put(&task)
+
+	if task.ListID == current.ListID {
+		return &task, nil
+	}
+	if err := os.Remove(filepath.Join(fs.baseDir, "lists", current.ListID, "tasks", current.ID+".json")); err != nil {
+		return nil, fmt.Errorf("failed to delete moved task: %w", err)
+	}
+	fs.index.remove(current.ListID, current.ID)
+	if err := moveAttachmentDir(fs.baseDir, task.ID, current.ListID, task.ListID); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchSettle is how long the data directory must be quiet before a change
+// is reported, so a burst of writes triggers a single callback
+const watchSettle = 250 * time.Millisecond
+
+// ownChangeWindow is how long after the file store's last write an event is
+// still taken to be caused by it. inotify delivers events within a few
+// milliseconds, so this only hides outside edits made in the same instant.
+const ownChangeWindow = 100 * time.Millisecond
+
+// storeLock is the file store's lock. It also records when the store last
+// held it for writing, so the watcher can skip events from the store's own
+// writes instead of rebuilding the index after every API call.
+type storeLock struct {
+	sync.RWMutex
+	writing   atomic.Bool
+	lastWrite atomic.Int64 // UnixNano
+}
+
+func (l *storeLock) Lock() {
+	l.RWMutex.Lock()
+	l.writing.Store(true)
+}
+
+func (l *storeLock) Unlock() {
+	l.lastWrite.Store(time.Now().UnixNano())
+	l.writing.Store(false)
+	l.RWMutex.Unlock()
+}
+
+// OwnChange reports whether a change seen now was probably made by the store
+// itself: it is writing, or finished writing within ownChangeWindow. Pass it
+// to WatchDir as ignore.
+func (fs *FileStore) OwnChange() bool {
+	if fs.mutex.writing.Load() {
+		return true
+	}
+	return time.Since(time.Unix(0, fs.mutex.lastWrite.Load())) < ownChangeWindow
+}
+
+// WatchDir watches dir and everything below it for changes, including files
+// edited outside the server, and calls onChange once the changes settle.
+// Directories created later are watched as they appear. Dot files and .tmp
+// files are temporaries and never count as changes, and neither does any
+// event seen while ignore, if not nil, returns true. Close the returned
+// watcher to stop.
+func WatchDir(dir string, ignore func() bool, onChange func()) (io.Closer, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watcher: %w", err)
+	}
+	if err := addWatchTree(watcher, dir); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	go runWatch(watcher, ignore, onChange)
+	return watcher, nil
+}
+
+// addWatchTree watches root and every directory below it, since fsnotify
+// only reports changes to a directory's direct children
+func addWatchTree(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// The directory may have been removed while we walked it
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// temporaryName reports whether path is a temporary file or directory, such
+// as the ones writeFile renames into place
+func temporaryName(path string) bool {
+	name := filepath.Base(path)
+	return strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".tmp")
+}
+
+// runWatch reports change events until the watcher is closed
+func runWatch(watcher *fsnotify.Watcher, ignore func() bool, onChange func()) {
+	var settle *time.Timer
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if temporaryName(event.Name) {
+				continue
+			}
+			// New directories are watched even when the store made them,
+			// so later outside edits inside them are seen
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := addWatchTree(watcher, event.Name); err != nil {
+						log.Printf("Data directory watcher: %v", err)
+					}
+				}
+			}
+			if ignore != nil && ignore() {
+				continue
+			}
+
+			if settle == nil {
+				settle = time.AfterFunc(watchSettle, onChange)
+			} else {
+				settle.Reset(watchSettle)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Data directory watcher: %v", err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchDirIgnoresOwnWrites(t *testing.T) {
+	store := newTestFileStore(t)
+	createTestList(t, store, "list")
+
+	changes := make(chan struct{}, 10)
+	watcher, err := WatchDir(store.baseDir, store.OwnChange, func() { changes <- struct{}{} })
+	if err != nil {
+		t.Fatalf("WatchDir: %v", err)
+	}
+	defer watcher.Close()
+
+	createTestTask(t, store, "list", "own")
+	select {
+	case <-changes:
+		t.Fatal("store's own write was reported as a change")
+	case <-time.After(2 * watchSettle):
+	}
+
+	// Wait out the window so the outside edit isn't taken for the store's
+	time.Sleep(ownChangeWindow)
+	if err := os.WriteFile(filepath.Join(store.baseDir, "lists", "list", "tasks", ".scratch"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+		t.Fatal("dot file was reported as a change")
+	case <-time.After(2 * watchSettle):
+	}
+
+	outside := newTestTask("list", "outside")
+	data, err := store.marshal(outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(store.baseDir, "lists", "list", "tasks", "outside.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("outside edit was not reported")
+	}
+}
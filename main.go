@@ -1,11 +1,22 @@
 package main
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/jbutlerdev/tasks/internal/api"
 	"github.com/jbutlerdev/tasks/internal/storage"
@@ -14,22 +25,249 @@ import (
 //go:embed web/static
 var staticFiles embed.FS
 
+// shutdownTimeout is how long in-flight requests get to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
 func main() {
+	configFile := flag.String("config", "", "JSON config file of flag settings; flags given on the command line override it")
 	port := flag.Int("port", 8080, "Port to run the server on")
 	dataDir := flag.String("data", "./data", "Directory to store task data")
+	storageBackend := flag.String("storage", "file", "Storage backend: file (one JSON file per task) or sqlite (tasks.db in the data directory)")
+	writeConcurrency := flag.Int("write-concurrency", 32, "Maximum number of concurrent file writes (0 for unlimited)")
+	readHeaderTimeout := flag.Duration("read-header-timeout", 5*time.Second, "Maximum duration for reading request headers")
+	readTimeout := flag.Duration("read-timeout", 30*time.Second, "Maximum duration for reading an entire request")
+	writeTimeout := flag.Duration("write-timeout", 30*time.Second, "Maximum duration before timing out writes of a response")
+	idleTimeout := flag.Duration("idle-timeout", 120*time.Second, "Maximum time to wait for the next request on keep-alive connections")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file (enables HTTPS and HTTP/2)")
+	tlsKey := flag.String("tls-key", "", "TLS private key file")
+	httpRedirectPort := flag.Int("http-redirect-port", 0, "With TLS, also listen for plain HTTP on this port and redirect to HTTPS (0 to disable)")
+	defaultPageSize := flag.Int("default-page-size", 100, "Page size used when ?offset= is given without ?limit=")
+	maxPageSize := flag.Int("max-page-size", 1000, "Largest page size a client may request with ?limit=")
+	storageJSON := flag.String("storage-json", "pretty", "Format of stored JSON files: pretty or compact")
+	logFormat := flag.String("log-format", "text", "Access log format: text or json (one JSON object per request)")
+	maxUploadSize := flag.Int64("max-upload-size", 10<<20, "Largest attachment, in bytes, that may be uploaded to a task")
+	watchData := flag.Bool("watch", false, "Watch the data directory and pick up changes made to it outside the server")
+	hardDelete := flag.Bool("hard-delete", false, "Delete tasks permanently instead of moving them to the trash")
+	strictReads := flag.Bool("strict", false, "Fail requests that read an unreadable or corrupt list or task instead of skipping it with a logged warning")
+	maxTasksPerList := flag.Int("max-tasks-per-list", 0, "Most tasks a single list may hold; adding more is rejected (0 for unlimited)")
+	maxTasks := flag.Int("max-tasks", 0, "Most tasks that may exist across all lists; adding more is rejected (0 for unlimited)")
+	reminderWindow := flag.Duration("reminder-window", 0, "Send a reminder for tasks due within this long, e.g. 24h (0 disables reminders)")
+	reminderInterval := flag.Duration("reminder-interval", time.Minute, "How often to check for tasks needing a due-date reminder")
+	reminderWebhook := flag.String("reminder-webhook", "", "URL to POST due-date reminders to as JSON; reminders are only logged without one")
+	authUser := flag.String("auth-user", "", "Username for HTTP basic auth, or $TASKS_AUTH_USER (auth is off unless credentials are set)")
+	authPass := flag.String("auth-pass", "", "Password for HTTP basic auth, or $TASKS_AUTH_PASS")
+	apiTokensFile := flag.String("api-tokens-file", "", "File of bearer tokens accepted on /api routes, one per line")
+	corsOrigins := flag.String("cors-origins", "", "Comma-separated origins allowed to call the API cross-origin (* for any)")
+	corsMethods := flag.String("cors-methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS", "Comma-separated methods allowed in cross-origin requests")
+	corsHeaders := flag.String("cors-headers", "Content-Type,Authorization", "Comma-separated request headers allowed in cross-origin requests")
 	flag.Parse()
 
+	if *configFile != "" {
+		if err := loadConfigFile(flag.CommandLine, *configFile); err != nil {
+			log.Fatalf("Failed to load config file %s: %v", *configFile, err)
+		}
+	}
+
+	// Credentials from the environment are read only now, rather than used as
+	// flag defaults, so that -h doesn't print them
+	if *authUser == "" {
+		*authUser = os.Getenv("TASKS_AUTH_USER")
+	}
+	if *authPass == "" {
+		*authPass = os.Getenv("TASKS_AUTH_PASS")
+	}
+
+	if (*tlsCert == "") != (*tlsKey == "") {
+		log.Fatalf("Both -tls-cert and -tls-key are required to serve HTTPS")
+	}
+	if *httpRedirectPort != 0 && *tlsCert == "" {
+		log.Fatalf("-http-redirect-port requires -tls-cert and -tls-key")
+	}
+
+	if *storageJSON != "pretty" && *storageJSON != "compact" {
+		log.Fatalf("Invalid -storage-json value %q: must be pretty or compact", *storageJSON)
+	}
+	if *logFormat != "text" && *logFormat != "json" {
+		log.Fatalf("Invalid -log-format value %q: must be text or json", *logFormat)
+	}
+	if *maxTasksPerList < 0 || *maxTasks < 0 {
+		log.Fatalf("-max-tasks-per-list and -max-tasks must not be negative")
+	}
+	taskLimits := storage.TaskLimits{PerList: *maxTasksPerList, Total: *maxTasks}
+	if *reminderWindow < 0 {
+		log.Fatalf("-reminder-window must not be negative")
+	}
+	if *reminderWindow > 0 && *reminderInterval <= 0 {
+		log.Fatalf("-reminder-interval must be positive")
+	}
+	if *reminderWebhook != "" {
+		if u, err := url.Parse(*reminderWebhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			log.Fatalf("Invalid -reminder-webhook value %q: must be an http or https URL", *reminderWebhook)
+		}
+	}
+
 	// Initialize storage
-	store, err := storage.NewFileStore(*dataDir)
-	if err != nil {
-		log.Fatalf("Failed to initialize storage: %v", err)
+	var store storage.TaskStore
+	switch *storageBackend {
+	case "file":
+		fileStore, err := storage.NewFileStore(*dataDir)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+		fileStore.SetWriteConcurrency(*writeConcurrency)
+		fileStore.SetCompactJSON(*storageJSON == "compact")
+		fileStore.SetHardDelete(*hardDelete)
+		fileStore.SetStrict(*strictReads)
+		fileStore.SetTaskLimits(taskLimits)
+		store = fileStore
+	case "sqlite":
+		sqliteStore, err := storage.NewSQLiteStore(filepath.Join(*dataDir, "tasks.db"))
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+		defer sqliteStore.Close()
+		sqliteStore.SetHardDelete(*hardDelete)
+		sqliteStore.SetStrict(*strictReads)
+		sqliteStore.SetTaskLimits(taskLimits)
+		store = sqliteStore
+	default:
+		log.Fatalf("Invalid -storage value %q: must be file or sqlite", *storageBackend)
+	}
+	if *watchData {
+		// The file store's task index must be reread along with the API's
+		// caches, since reads no longer go to the task files. The store's own
+		// writes already keep the index current, so their events are ignored.
+		onChange := api.InvalidateCaches
+		var ignore func() bool
+		if fileStore, ok := store.(*storage.FileStore); ok {
+			ignore = fileStore.OwnChange
+			onChange = func() {
+				if err := fileStore.RebuildIndex(); err != nil {
+					log.Printf("Failed to rebuild task index: %v", err)
+				}
+				api.InvalidateCaches()
+			}
+		}
+		watcher, err := storage.WatchDir(*dataDir, ignore, onChange)
+		if err != nil {
+			log.Fatalf("Failed to watch data directory: %v", err)
+		}
+		defer watcher.Close()
 	}
+	api.SetLogFormat(*logFormat)
+	api.SetPageLimits(*defaultPageSize, *maxPageSize)
+	api.SetMaxUploadSize(*maxUploadSize)
+	api.SetBasicAuth(*authUser, *authPass)
+
+	// API tokens come from the TASKS_API_TOKENS environment variable
+	// (comma-separated) and the optional tokens file
+	apiTokens := strings.Split(os.Getenv("TASKS_API_TOKENS"), ",")
+	if *apiTokensFile != "" {
+		data, err := os.ReadFile(*apiTokensFile)
+		if err != nil {
+			log.Fatalf("Failed to read API tokens file: %v", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+				apiTokens = append(apiTokens, line)
+			}
+		}
+	}
+	api.SetAPITokens(apiTokens)
+	api.SetCORS(splitList(*corsOrigins), splitList(*corsMethods), splitList(*corsHeaders))
 
 	// Setup API routes with embedded static files
 	router := api.NewRouter(store, staticFiles)
 
-	// Start server
-	addr := fmt.Sprintf(":%d", *port)
-	log.Printf("Server starting on %s", addr)
-	log.Fatal(http.ListenAndServe(addr, router))
+	// ctx is cancelled on SIGINT or SIGTERM, stopping background work and
+	// shutting the server down gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *reminderWindow > 0 {
+		go api.RunReminders(ctx, store, api.ReminderOptions{
+			Window:     *reminderWindow,
+			Interval:   *reminderInterval,
+			WebhookURL: *reminderWebhook,
+		})
+	}
+
+	// Start server. Streaming handlers that outlive the write timeout must
+	// clear their deadline with http.ResponseController.SetWriteDeadline.
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", *port),
+		Handler:           router,
+		ReadHeaderTimeout: *readHeaderTimeout,
+		ReadTimeout:       *readTimeout,
+		WriteTimeout:      *writeTimeout,
+		IdleTimeout:       *idleTimeout,
+	}
+
+	// Shutdown makes ListenAndServe return at once, so main waits for the
+	// in-flight requests to finish before deferred cleanup closes the store
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		log.Printf("Shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Shutdown: %v", err)
+		}
+	}()
+
+	var err error
+	if *tlsCert != "" {
+		if *httpRedirectPort != 0 {
+			redirect := &http.Server{
+				Addr:              fmt.Sprintf(":%d", *httpRedirectPort),
+				Handler:           httpsRedirect(*port),
+				ReadHeaderTimeout: *readHeaderTimeout,
+				IdleTimeout:       *idleTimeout,
+			}
+			go func() {
+				log.Printf("Redirecting HTTP on %s to HTTPS", redirect.Addr)
+				log.Fatal(redirect.ListenAndServe())
+			}()
+		}
+
+		log.Printf("Server starting on %s (HTTPS)", server.Addr)
+		err = server.ListenAndServeTLS(*tlsCert, *tlsKey)
+	} else {
+		log.Printf("Server starting on %s (HTTP)", server.Addr)
+		err = server.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+	<-shutdownDone
+}
+
+// httpsRedirect permanently redirects requests to the same host and path on
+// the HTTPS port
+func httpsRedirect(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+
+		target := url.URL{Scheme: "https", Host: host, Path: r.URL.Path, RawQuery: r.URL.RawQuery}
+		http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
+	})
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
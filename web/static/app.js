@@ -1,12 +1,36 @@
 // Task management app JavaScript enhancements
 
-// Clear forms after successful submission
+// Clear forms after successful submission. Errors shown in the error banner
+// count as successful swaps, so check the status too.
 document.addEventListener('htmx:afterRequest', function(event) {
-    if (event.detail.successful && event.target.tagName === 'FORM') {
+    if (event.detail.successful && event.detail.xhr.status < 400 && event.target.tagName === 'FORM') {
         event.target.reset();
     }
 });
 
+// Error responses to HTMX requests are retargeted at the error banner; htmx
+// doesn't swap error responses unless told to
+document.addEventListener('htmx:beforeSwap', function(event) {
+    const xhr = event.detail.xhr;
+    if (xhr.status >= 400 && xhr.getResponseHeader('HX-Retarget')) {
+        event.detail.shouldSwap = true;
+        event.detail.isError = false;
+    }
+});
+
+// Clear the error banner after a successful request or when dismissed
+document.addEventListener('htmx:afterRequest', function(event) {
+    const banner = document.getElementById('error-banner');
+    if (banner && event.detail.successful && event.detail.xhr.status < 400) {
+        banner.innerHTML = '';
+    }
+});
+document.addEventListener('click', function(event) {
+    if (event.target.closest('.error-dismiss')) {
+        document.getElementById('error-banner').innerHTML = '';
+    }
+});
+
 // List filter functionality
 document.addEventListener('DOMContentLoaded', function() {
     const filterForm = document.getElementById('list-filter-form');
@@ -182,6 +206,11 @@ document.addEventListener('DOMContentLoaded', function() {
 
         // Format date for input field if present
         const formattedDate = task.due_date ? new Date(task.due_date).toISOString().split('T')[0] : '';
+        const formattedStartDate = task.start_date ? new Date(task.start_date).toISOString().split('T')[0] : '';
+        
+        // Offer the workflow states of the task's list
+        const taskList = lists.find(list => list.id === task.list_id);
+        const states = taskList && taskList.states && taskList.states.length ? taskList.states : ['todo', 'in_progress', 'blocked', 'done'];
         
         // Get target selector based on current view
         const targetSelector = window.location.pathname.includes('/kanban/') ? '.kanban-board' : '.tasks-container';
@@ -198,6 +227,7 @@ document.addEventListener('DOMContentLoaded', function() {
                     <form id="edit-task-form" data-task-id="${task.id}" data-list-id="${task.list_id}" data-target="${targetSelector}" enctype="application/x-www-form-urlencoded">
                         <input type="hidden" id="edit-id" name="id" value="${task.id}">
                         <input type="hidden" id="edit-original-list-id" name="original_list_id" value="${task.list_id}">
+                        <input type="hidden" id="edit-version" name="version" value="${task.version || ''}">
                         
                         <div>
                             <label for="edit-title">Title:</label>
@@ -212,16 +242,32 @@ document.addEventListener('DOMContentLoaded', function() {
                         <div>
                             <label for="edit-state">State:</label>
                             <select id="edit-state" name="state">
-                                ${['todo', 'in_progress', 'blocked', 'done'].map(state => 
+                                ${states.map(state => 
                                     `<option value="${state}" ${task.state === state ? 'selected' : ''}>
                                         ${state === 'todo' ? 'Todo' : 
                                           state === 'in_progress' ? 'In Progress' : 
-                                          state === 'blocked' ? 'Blocked' : 'Done'}
+                                          state === 'blocked' ? 'Blocked' : 
+                                          state === 'done' ? 'Done' : state}
                                     </option>`
                                 ).join('')}
                             </select>
                         </div>
                         
+                        <div>
+                            <label for="edit-blocked-reason">Blocked Reason:</label>
+                            <input type="text" id="edit-blocked-reason" name="blocked_reason" value="${task.blocked_reason || ''}" placeholder="Required when state is Blocked">
+                        </div>
+                        
+                        <div>
+                            <label for="edit-tags">Tags:</label>
+                            <input type="text" id="edit-tags" name="tags" value="${(task.tags || []).join(', ')}" placeholder="Comma-separated">
+                        </div>
+                        
+                        <div>
+                            <label for="edit-assignee">Assignee:</label>
+                            <input type="text" id="edit-assignee" name="assignee" value="${task.assignee || ''}" placeholder="Leave empty for unassigned">
+                        </div>
+                        
                         <div>
                             <label for="edit-list">Task List:</label>
                             <select id="edit-list" name="list_id">
@@ -233,6 +279,27 @@ document.addEventListener('DOMContentLoaded', function() {
                             </select>
                         </div>
                         
+                        <div>
+                            <label for="edit-flagged">Flagged:</label>
+                            <input type="hidden" name="flagged" value="false">
+                            <input type="checkbox" id="edit-flagged" name="flagged" value="true" ${task.flagged ? 'checked' : ''}>
+                        </div>
+                        
+                        <div>
+                            <label for="edit-estimate-minutes">Estimate (minutes):</label>
+                            <input type="number" id="edit-estimate-minutes" name="estimate_minutes" min="0" value="${task.estimate_minutes || ''}">
+                        </div>
+                        
+                        <div>
+                            <label for="edit-spent-minutes">Spent (minutes):</label>
+                            <input type="number" id="edit-spent-minutes" name="spent_minutes" min="0" value="${task.spent_minutes || ''}">
+                        </div>
+                        
+                        <div>
+                            <label for="edit-start-date">Start Date:</label>
+                            <input type="date" id="edit-start-date" name="start_date" value="${formattedStartDate}">
+                        </div>
+                        
                         <div>
                             <label for="edit-due-date">Due Date:</label>
                             <input type="date" id="edit-due-date" name="due_date" value="${formattedDate}">
@@ -339,6 +406,9 @@ document.addEventListener('DOMContentLoaded', function() {
                 body: urlParams.toString()
             })
             .then(response => {
+                if (response.status === 409) {
+                    throw new Error('the task was changed by someone else, reload to see the latest version');
+                }
                 if (!response.ok) {
                     throw new Error(`HTTP error ${response.status}`);
                 }
@@ -0,0 +1,197 @@
+// API docs page: renders the OpenAPI spec served by /api/openapi
+
+document.addEventListener('DOMContentLoaded', function() {
+    const main = document.querySelector('.api-docs');
+    const content = document.getElementById('api-docs-content');
+    if (!main || !content) {
+        return;
+    }
+
+    fetch(main.dataset.specUrl)
+        .then(function(response) {
+            if (!response.ok) {
+                throw new Error('HTTP ' + response.status);
+            }
+            return response.json();
+        })
+        .then(function(spec) {
+            content.replaceChildren(renderSpec(spec));
+        })
+        .catch(function(error) {
+            content.textContent = 'Failed to load the API specification: ' + error.message;
+        });
+});
+
+const docsMethods = ['get', 'post', 'put', 'patch', 'delete', 'head', 'options'];
+
+// el creates an element with a class and children; strings become text, so
+// nothing from the spec is parsed as HTML
+function el(tag, className, children) {
+    const node = document.createElement(tag);
+    if (className) {
+        node.className = className;
+    }
+    (children || []).forEach(function(child) {
+        if (child !== null && child !== undefined) {
+            node.append(child);
+        }
+    });
+    return node;
+}
+
+// renderSpec renders the operations, grouped by the first path segment after
+// /api, followed by the schemas
+function renderSpec(spec) {
+    const fragment = document.createDocumentFragment();
+    if (spec.info && spec.info.description) {
+        fragment.append(el('p', null, [spec.info.description]));
+    }
+
+    const groups = new Map();
+    Object.keys(spec.paths || {}).sort().forEach(function(path) {
+        const item = spec.paths[path];
+        docsMethods.forEach(function(method) {
+            if (!item[method]) {
+                return;
+            }
+            const group = path.split('/')[2] || path;
+            if (!groups.has(group)) {
+                groups.set(group, []);
+            }
+            groups.get(group).push(renderOperation(method, path, item[method], item.parameters || []));
+        });
+    });
+    groups.forEach(function(operations, group) {
+        fragment.append(el('section', 'api-docs-group', [el('h3', null, ['/api/' + group])].concat(operations)));
+    });
+
+    const schemas = (spec.components && spec.components.schemas) || {};
+    const names = Object.keys(schemas).sort();
+    if (names.length > 0) {
+        const section = el('section', 'api-docs-group', [el('h3', null, ['Schemas'])]);
+        names.forEach(function(name) {
+            section.append(renderSchema(name, schemas[name]));
+        });
+        fragment.append(section);
+    }
+    return fragment;
+}
+
+// renderOperation renders one operation as a collapsible block
+function renderOperation(method, path, operation, pathParameters) {
+    const summary = el('summary', null, [
+        el('span', 'api-method api-method-' + method, [method.toUpperCase()]),
+        el('code', 'api-path', [path]),
+        el('span', 'api-summary', [operation.summary || '']),
+    ]);
+    const details = el('details', 'api-operation', [summary]);
+    details.id = operation.operationId || (method + path);
+
+    if (operation.description) {
+        details.append(el('p', null, [operation.description]));
+    }
+
+    const parameters = pathParameters.concat(operation.parameters || []);
+    if (parameters.length > 0) {
+        const rows = parameters.map(function(parameter) {
+            return el('tr', null, [
+                el('td', null, [el('code', null, [parameter.name]), parameter.required ? ' *' : '']),
+                el('td', null, [parameter.in]),
+                el('td', null, [describeSchema(parameter.schema)]),
+                el('td', null, [parameter.description || '']),
+            ]);
+        });
+        details.append(el('h4', null, ['Parameters']), table(['Name', 'In', 'Type', 'Description'], rows));
+    }
+
+    if (operation.requestBody) {
+        details.append(el('h4', null, ['Request body']), renderContent(operation.requestBody));
+    }
+
+    const responses = operation.responses || {};
+    const rows = Object.keys(responses).sort().map(function(status) {
+        return el('tr', null, [
+            el('td', null, [el('code', null, [status])]),
+            el('td', null, [responses[status].description || '', renderContent(responses[status])]),
+        ]);
+    });
+    if (rows.length > 0) {
+        details.append(el('h4', null, ['Responses']), table(['Status', 'Description'], rows));
+    }
+    return details;
+}
+
+// renderContent lists the media types and schemas of a request or response
+function renderContent(body) {
+    const content = body.content || {};
+    const list = el('ul', 'api-content');
+    Object.keys(content).forEach(function(type) {
+        list.append(el('li', null, [el('code', null, [type]), ' ', describeSchema(content[type].schema)]));
+    });
+    return list.childElementCount > 0 ? list : null;
+}
+
+// renderSchema renders a component schema's properties
+function renderSchema(name, schema) {
+    const details = el('details', 'api-operation', [el('summary', null, [el('code', 'api-path', [name])])]);
+    details.id = 'schema-' + name;
+    if (schema.description) {
+        details.append(el('p', null, [schema.description]));
+    }
+
+    const properties = schema.properties || {};
+    const rows = Object.keys(properties).sort().map(function(property) {
+        return el('tr', null, [
+            el('td', null, [el('code', null, [property])]),
+            el('td', null, [describeSchema(properties[property])]),
+            el('td', null, [properties[property].description || '']),
+        ]);
+    });
+    if (rows.length > 0) {
+        details.append(table(['Field', 'Type', 'Description'], rows));
+    }
+    return details;
+}
+
+// describeSchema returns a short description of a schema's type, linking
+// references to the schema they name
+function describeSchema(schema) {
+    if (!schema) {
+        return '';
+    }
+    if (schema.$ref) {
+        const name = schema.$ref.split('/').pop();
+        const link = el('a', null, [name]);
+        link.href = '#schema-' + name;
+        link.addEventListener('click', function() {
+            const target = document.getElementById('schema-' + name);
+            if (target) {
+                target.open = true;
+            }
+        });
+        return link;
+    }
+    if (schema.type === 'array') {
+        return el('span', null, ['array of ', describeSchema(schema.items)]);
+    }
+
+    let text = schema.type || 'object';
+    if (schema.format) {
+        text += ' (' + schema.format + ')';
+    }
+    if (schema.enum) {
+        text += ': ' + schema.enum.join(', ');
+    }
+    if (schema.nullable) {
+        text += ', nullable';
+    }
+    return text;
+}
+
+// table builds a table with the given headings and rows
+function table(headings, rows) {
+    const head = el('tr', null, headings.map(function(heading) {
+        return el('th', null, [heading]);
+    }));
+    return el('table', 'api-table', [el('thead', null, [head]), el('tbody', null, rows)]);
+}